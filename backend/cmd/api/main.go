@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
 
 	"github.com/project-capillary/backend/internal/application/usecases"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
 	"github.com/project-capillary/backend/internal/infrastructure/config"
+	"github.com/project-capillary/backend/internal/infrastructure/db/migrations"
 	"github.com/project-capillary/backend/internal/infrastructure/db/postgres"
+	"github.com/project-capillary/backend/internal/infrastructure/hl7"
 	httpInfra "github.com/project-capillary/backend/internal/infrastructure/http"
 	"github.com/project-capillary/backend/internal/infrastructure/http/handlers"
 	"github.com/project-capillary/backend/internal/infrastructure/mq"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
+	"github.com/project-capillary/backend/internal/infrastructure/signing"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
 	"github.com/project-capillary/backend/internal/infrastructure/ws"
 )
 
@@ -31,13 +40,33 @@ func main() {
 
 	log.Println("Database connection established")
 
-	patientRepo := postgres.NewPatientRepository(db.DB)
+	if cfg.Database.AutoMigrate {
+		migrateDatabase(db)
+	}
+
+	keys, created, err := pii.LoadOrCreateKeyring(cfg.PII.KeyFile)
+	if err != nil {
+		log.Fatalf("Failed to load PII key file: %v", err)
+	}
+	if created {
+		log.Printf("Created PII key file %s; back it up, patient data and signing keys cannot be read without it", cfg.PII.KeyFile)
+	}
+
+	patientRepo := postgres.NewPatientRepository(db.DB, keys)
 	examinationRepo := postgres.NewExaminationRepository(db.DB)
 	imageRepo := postgres.NewImageRepository(db.DB)
+	captureRepo := postgres.NewPhotoCaptureRepository(db.DB)
 	analysisRepo := postgres.NewAnalysisRepository(db.DB)
 	reportRepo := postgres.NewReportRepository(db.DB)
 	userRepo := postgres.NewUserRepository(db.DB)
-	_ = postgres.NewDeviceRepository(db.DB)
+	deviceRepo := postgres.NewDeviceRepository(db.DB)
+	signingKeyRepo := postgres.NewSigningKeyRepository(db.DB, keys)
+	hl7MessageRepo := postgres.NewHL7MessageRepository(db.DB)
+	patientMergeRepo := postgres.NewPatientMergeRepository(db.DB, keys)
+	patientErasureRepo := postgres.NewPatientErasureRepository(db.DB, keys)
+	auditRepo := postgres.NewAuditRepository(db.DB)
+	txManager := postgres.NewTransactionManager(db.DB)
+	organizationRepo := postgres.NewOrganizationRepository(db.DB)
 
 	mqPublisher, err := mq.NewRabbitMQ(cfg.RabbitMQ.URL, cfg.RabbitMQ.QueueName)
 	if err != nil {
@@ -47,24 +76,147 @@ func main() {
 
 	log.Println("RabbitMQ connection established")
 
-	patientUseCase := usecases.NewPatientUseCase(patientRepo)
-	examinationUseCase := usecases.NewExaminationUseCase(examinationRepo, analysisRepo, imageRepo, mqPublisher)
-	reportUseCase := usecases.NewReportUseCase(reportRepo, examinationRepo, analysisRepo, imageRepo)
-	userUseCase := usecases.NewUserUseCase(userRepo)
+	signer := signing.NewSigner(signingKeyRepo)
+
+	tokenSecret := []byte(cfg.Auth.TokenSecret)
+	if len(tokenSecret) == 0 {
+		log.Println("AUTH_TOKEN_SECRET is not set; generating a temporary secret")
+		tokenSecret = make([]byte, 32)
+		if _, err := rand.Read(tokenSecret); err != nil {
+			log.Fatalf("Failed to generate token secret: %v", err)
+		}
+	}
+	tokens := auth.NewTokenIssuer(tokenSecret, cfg.Auth.TokenTTL)
+	urls := auth.NewURLSigner(tokenSecret, cfg.Auth.SignedURLTTL)
+
+	blobs, err := storage.NewBlobStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to open photo storage: %v", err)
+	}
 
-	patientHandler := handlers.NewPatientHandler(patientUseCase)
-	examinationHandler := handlers.NewExaminationHandler(examinationUseCase)
+	patientUseCase := usecases.NewPatientUseCase(patientRepo, patientMergeRepo, userRepo, txManager)
+	patientErasureUseCase := usecases.NewPatientErasureUseCase(patientRepo, examinationRepo, imageRepo, captureRepo,
+		patientErasureRepo, userRepo, blobs, cfg.Retention.MedicalRecordYears)
+	examinationUseCase := usecases.NewExaminationUseCase(examinationRepo, analysisRepo, imageRepo, captureRepo, patientRepo, userRepo, txManager, mqPublisher, blobs)
+	inboxUseCase := usecases.NewPhotoInboxUseCase(captureRepo, imageRepo, blobs, urls)
+	imageUseCase := usecases.NewImageUseCase(imageRepo, examinationRepo, blobs)
+	reportUseCase := usecases.NewReportUseCase(reportRepo, examinationRepo, analysisRepo, imageRepo, userRepo, signer, blobs, urls)
+	userUseCase := usecases.NewUserUseCase(userRepo, organizationRepo, txManager, tokens)
+	organizationUseCase := usecases.NewOrganizationUseCase(organizationRepo)
+	deviceUseCase := usecases.NewDeviceUseCase(deviceRepo)
+	auditUseCase := usecases.NewAuditUseCase(auditRepo)
+	fhirUseCase := usecases.NewFHIRUseCase(patientRepo, examinationRepo, imageRepo, analysisRepo, reportRepo)
+	dicomUseCase := usecases.NewDICOMUseCase(patientRepo, examinationRepo, imageRepo, blobs)
+
+	var hl7Client *hl7.Client
+	if cfg.HL7.Enabled() {
+		hl7Client = hl7.NewClient(cfg.HL7.MLLPAddress, cfg.HL7.Timeout)
+	}
+	hl7UseCase := usecases.NewHL7UseCase(
+		reportRepo,
+		examinationRepo,
+		patientRepo,
+		analysisRepo,
+		hl7MessageRepo,
+		hl7Client,
+		hl7.Header{
+			SendingApplication:   cfg.HL7.SendingApplication,
+			SendingFacility:      cfg.HL7.SendingFacility,
+			ReceivingApplication: cfg.HL7.ReceivingApplication,
+			ReceivingFacility:    cfg.HL7.ReceivingFacility,
+		},
+		cfg.HL7.MaxAttempts,
+		cfg.HL7.RetryDelay,
+	)
+
+	if hl7UseCase.Enabled() {
+		reportUseCase.SetReportSignedCallback(func(report *entities.Report) {
+			go func() {
+				message, err := hl7UseCase.SendReport(context.Background(), report.ID)
+				if err != nil {
+					log.Printf("Failed to send HL7 result for report %s: %v", report.ID, err)
+					return
+				}
+				log.Printf("HL7 result %s for report %s: %s", message.ControlID, report.ID, message.Status)
+			}()
+		})
+	}
+
+	patientHandler := handlers.NewPatientHandler(patientUseCase, patientErasureUseCase)
+	examinationHandler := handlers.NewExaminationHandler(examinationUseCase, cfg.Storage.MaxUploadBytes)
+	imageHandler := handlers.NewImageHandler(imageUseCase)
+	photoHandler := handlers.NewPhotoHandler(inboxUseCase)
 	reportHandler := handlers.NewReportHandler(reportUseCase)
 	userHandler := handlers.NewUserHandler(userUseCase)
+	fhirHandler := handlers.NewFHIRHandler(fhirUseCase)
+	dicomHandler := handlers.NewDICOMHandler(dicomUseCase)
+	hl7Handler := handlers.NewHL7Handler(hl7UseCase)
+	auditHandler := handlers.NewAuditHandler(auditUseCase)
+	organizationHandler := handlers.NewOrganizationHandler(organizationUseCase)
+	deviceHandler := handlers.NewDeviceHandler(deviceUseCase)
 
-	deviceManager := ws.NewDeviceManager(cfg.Storage.PhotoPath)
+	deviceManager := ws.NewDeviceManager(inboxUseCase, urls, db.ScopeTenant)
+	deviceManager.SetAuditCallback(func(ctx context.Context, action, filename string) {
+		err := auditUseCase.Record(ctx, usecases.AuditEvent{
+			Action:       action,
+			ResourceType: "photo",
+			ResourceID:   filename,
+			Route:        "/ws",
+		})
+		if err != nil {
+			log.Printf("Failed to record audit entry for photo %s: %v", action, err)
+		}
+	})
+
+	// Device connections live in this process only, so none is open yet.
+	if reset, err := deviceRepo.MarkAllOffline(context.Background()); err != nil {
+		log.Printf("Failed to reset device statuses: %v", err)
+	} else if reset > 0 {
+		log.Printf("Marked %d devices offline", reset)
+	}
+	deviceManager.SetDeviceConnectCallback(func(ctx context.Context, organizationID string, hello ws.DeviceHello) error {
+		return deviceUseCase.Connect(ctx, organizationID, hello.DeviceID, hello.Name, hello.Label)
+	})
+	deviceManager.SetDeviceDisconnectCallback(deviceUseCase.Disconnect)
+
+	// Photos taken during a capture session go straight into the session's
+	// examination.
+	deviceManager.SetSessionStartCallback(func(ctx context.Context, session *ws.CaptureSession) error {
+		position, err := examinationUseCase.OpenCaptureSession(ctx, session.ExaminationID, session.Position)
+		session.Position = position
+		return err
+	})
+	deviceManager.SetPhotoSavedCallback(func(ctx context.Context, filename string, session *ws.CaptureSession) error {
+		if session == nil {
+			return nil
+		}
+		return examinationUseCase.AttachPhotos(ctx, session.ExaminationID, []string{filename}, session.Position)
+	})
+	deviceManager.SetSessionEndCallback(func(ctx context.Context, session *ws.CaptureSession, analyze bool) error {
+		if !analyze {
+			return nil
+		}
+		return examinationUseCase.StartAnalysis(ctx, session.ExaminationID)
+	})
 
 	router := httpInfra.NewRouter(
 		patientHandler,
 		examinationHandler,
+		imageHandler,
+		photoHandler,
 		userHandler,
 		reportHandler,
+		fhirHandler,
+		dicomHandler,
+		hl7Handler,
+		auditHandler,
+		organizationHandler,
+		deviceHandler,
 		deviceManager,
+		auditUseCase,
+		tokens,
+		urls,
+		db.ScopeTenant,
 	)
 
 	engine := router.Setup()
@@ -76,3 +228,19 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// migrateDatabase applies pending migrations. The API and worker may both do
+// this at startup; the migration lock lets only one of them run each one.
+func migrateDatabase(db *postgres.PostgresDB) {
+	migrator, err := postgres.NewMigrator(db.DB, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to apply migrations: %v", err)
+	}
+	for _, migration := range applied {
+		log.Printf("Applied migration %s", migration)
+	}
+}
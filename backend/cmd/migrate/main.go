@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/project-capillary/backend/internal/infrastructure/config"
+	"github.com/project-capillary/backend/internal/infrastructure/db/migrations"
+	"github.com/project-capillary/backend/internal/infrastructure/db/postgres"
+)
+
+const usage = `usage: migrate <command> [flags]
+
+commands:
+  up        apply all pending migrations
+  down      revert the latest migrations (-steps N, default 1)
+  status    list migrations and when each was applied
+  baseline  record migrations up to -version N as applied without running them,
+            for databases created by the old docker-entrypoint-initdb.d scripts
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	steps := flags.Int("steps", 1, "migrations to revert with down")
+	version := flags.Int64("version", 0, "last migration already present, for baseline")
+	flags.Parse(os.Args[2:])
+
+	cfg := config.Load()
+	db, err := postgres.NewPostgresDB(
+		cfg.Database.Host,
+		cfg.Database.Port,
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.DBName,
+		cfg.Database.SSLMode,
+	)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := postgres.NewMigrator(db.DB, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		logMigrations("Applied", applied)
+		if err != nil {
+			log.Fatalf("Failed to apply migrations: %v", err)
+		}
+		log.Printf("Applied %d migrations", len(applied))
+
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		logMigrations("Reverted", reverted)
+		if errors.Is(err, postgres.ErrIrreversibleMigration) {
+			log.Fatalf("%v; restore from a backup instead", err)
+		}
+		if err != nil {
+			log.Fatalf("Failed to revert migrations: %v", err)
+		}
+		log.Printf("Reverted %d migrations", len(reverted))
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED\tREVERSIBLE")
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			name := status.Name
+			if status.Unknown {
+				name += " (unknown to this build)"
+			}
+			fmt.Fprintf(w, "%03d\t%s\t%s\t%t\n", status.Version, name, applied, status.Reversible)
+		}
+		w.Flush()
+
+	case "baseline":
+		if *version <= 0 {
+			log.Fatal("baseline requires -version")
+		}
+		recorded, err := migrator.Baseline(ctx, *version)
+		logMigrations("Recorded", recorded)
+		if err != nil {
+			log.Fatalf("Failed to record baseline: %v", err)
+		}
+		log.Printf("Recorded %d migrations as applied", len(recorded))
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func logMigrations(verb string, migrations []postgres.Migration) {
+	for _, migration := range migrations {
+		log.Printf("%s %s", verb, migration)
+	}
+}
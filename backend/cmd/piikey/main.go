@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/project-capillary/backend/internal/infrastructure/config"
+	"github.com/project-capillary/backend/internal/infrastructure/db/postgres"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
+)
+
+const usage = `usage: piikey <command> [-batch N]
+
+commands:
+  rotate     add a new active key to PII_KEY_FILE and re-encrypt all patient data and
+             signing keys under it
+  reencrypt  re-encrypt patient data and signing keys not yet under the active key, e.g.
+             after upgrading plaintext rows or resuming an interrupted rotation
+  prune      re-encrypt stragglers, then remove retired keys from PII_KEY_FILE; run after
+             restarting the API and worker so nothing writes with a retired key
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	batchSize := flags.Int("batch", 500, "rows re-encrypted per query")
+	flags.Parse(os.Args[2:])
+
+	cfg := config.Load()
+	keys, err := pii.LoadKeyring(cfg.PII.KeyFile)
+	if err != nil {
+		log.Fatalf("Failed to load PII key file: %v", err)
+	}
+
+	switch command {
+	case "rotate":
+		keyID, err := keys.Rotate()
+		if err != nil {
+			log.Fatalf("Failed to generate key: %v", err)
+		}
+		// Save before re-encrypting so rows sealed under the new key are
+		// never left without it.
+		if err := keys.Save(cfg.PII.KeyFile); err != nil {
+			log.Fatalf("Failed to save PII key file: %v", err)
+		}
+		log.Printf("Activated key %s", keyID)
+		reencrypt(cfg, keys, *batchSize)
+		log.Println("Restart the API and worker to use the new key, then run `piikey prune`")
+
+	case "reencrypt":
+		reencrypt(cfg, keys, *batchSize)
+
+	case "prune":
+		reencrypt(cfg, keys, *batchSize)
+		retired := keys.Retired()
+		for _, keyID := range retired {
+			keys.Remove(keyID)
+		}
+		if err := keys.Save(cfg.PII.KeyFile); err != nil {
+			log.Fatalf("Failed to save PII key file: %v", err)
+		}
+		log.Printf("Removed %d retired keys", len(retired))
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func reencrypt(cfg *config.Config, keys *pii.Keyring, batchSize int) {
+	db, err := postgres.NewPostgresDB(
+		cfg.Database.Host,
+		cfg.Database.Port,
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.DBName,
+		cfg.Database.SSLMode,
+	)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	patients, err := postgres.NewPatientRepository(db.DB, keys).Reencrypt(ctx, batchSize)
+	if err != nil {
+		log.Fatalf("Failed to re-encrypt patients after %d rows: %v", patients, err)
+	}
+	merges, err := postgres.NewPatientMergeRepository(db.DB, keys).Reencrypt(ctx, batchSize)
+	if err != nil {
+		log.Fatalf("Failed to re-encrypt merge snapshots after %d rows: %v", merges, err)
+	}
+	signingKeys, err := postgres.NewSigningKeyRepository(db.DB, keys).Reencrypt(ctx, batchSize)
+	if err != nil {
+		log.Fatalf("Failed to re-encrypt signing keys after %d rows: %v", signingKeys, err)
+	}
+	log.Printf("Re-encrypted %d patients, %d merge snapshots and %d signing keys under key %s",
+		patients, merges, signingKeys, keys.Active)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/project-capillary/backend/internal/infrastructure/signing"
+)
+
+func main() {
+	bundlePath := flag.String("bundle", "", "path to the JSON returned by GET /api/reports/:id/verify, or to a bare signature bundle")
+	publicKey := flag.String("public-key", "", "base64 Ed25519 public key to trust instead of the key embedded in the bundle")
+	photoDir := flag.String("photos", "", "directory with the report's photos to re-check image checksums")
+	flag.Parse()
+
+	if *bundlePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*bundlePath)
+	if err != nil {
+		log.Fatalf("Failed to read bundle: %v", err)
+	}
+
+	bundle, err := parseBundle(data)
+	if err != nil {
+		log.Fatalf("Failed to parse bundle: %v", err)
+	}
+
+	if *publicKey != "" && bundle.PublicKey != *publicKey {
+		fmt.Println("INVALID: bundle was not signed with the trusted public key")
+		os.Exit(1)
+	}
+
+	payload, err := bundle.Verify()
+	if err != nil {
+		fmt.Printf("INVALID: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *photoDir != "" {
+		for _, image := range payload.Images {
+			checksum, err := signing.FileSHA256(filepath.Join(*photoDir, filepath.Base(image.Filename)))
+			if err != nil {
+				fmt.Printf("INVALID: image %s: %v\n", image.Filename, err)
+				os.Exit(1)
+			}
+			if checksum != image.SHA256 {
+				fmt.Printf("INVALID: image %s checksum mismatch\n", image.Filename)
+				os.Exit(1)
+			}
+		}
+	}
+
+	fmt.Println("VALID")
+	fmt.Printf("Report:      %s\n", payload.ReportID)
+	fmt.Printf("Examination: %s\n", payload.ExaminationID)
+	fmt.Printf("Signed by:   %s\n", payload.SignedBy)
+	fmt.Printf("Signed at:   %s\n", payload.SignedAt)
+	fmt.Printf("Key:         %s\n", payload.KeyID)
+	fmt.Printf("Images:      %d\n", len(payload.Images))
+}
+
+func parseBundle(data []byte) (*signing.Bundle, error) {
+	var envelope struct {
+		Data struct {
+			Bundle *signing.Bundle `json:"bundle"`
+		} `json:"data"`
+		Bundle *signing.Bundle `json:"bundle"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Data.Bundle != nil {
+		return envelope.Data.Bundle, nil
+	}
+	if envelope.Bundle != nil {
+		return envelope.Bundle, nil
+	}
+
+	var bundle signing.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, err
+	}
+	if bundle.Signature == "" {
+		return nil, fmt.Errorf("no signature bundle found")
+	}
+	return &bundle, nil
+}
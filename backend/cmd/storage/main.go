@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/project-capillary/backend/internal/infrastructure/config"
+	"github.com/project-capillary/backend/internal/infrastructure/db/postgres"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
+)
+
+const usage = `usage: storage <command> [flags]
+
+commands:
+  fsck  compare stored photos with the images and photo inbox tables and
+        report orphaned files and missing blobs; -verify also re-hashes
+        every photo
+`
+
+const pageSize = 500
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	verify := flags.Bool("verify", false, "read every photo and check its SHA-256")
+	flags.Parse(os.Args[2:])
+
+	if command != "fsck" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	db, err := postgres.NewPostgresDB(
+		cfg.Database.Host,
+		cfg.Database.Port,
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.DBName,
+		cfg.Database.SSLMode,
+	)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	blobs, err := storage.NewBlobStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to open photo storage: %v", err)
+	}
+
+	ctx := context.Background()
+	imageRepo := postgres.NewImageRepository(db.DB)
+	refs := make(map[string]string)
+	for offset := 0; ; offset += pageSize {
+		images, err := imageRepo.List(ctx, pageSize, offset)
+		if err != nil {
+			log.Fatalf("Failed to list images: %v", err)
+		}
+		for _, image := range images {
+			if image.Filename != "" {
+				refs[storage.PhotoKey(image.Filename)] = image.SHA256
+			}
+		}
+		if len(images) < pageSize {
+			break
+		}
+	}
+	// Photos waiting in the inbox are referenced by their capture.
+	captureRepo := postgres.NewPhotoCaptureRepository(db.DB)
+	for offset := 0; ; offset += pageSize {
+		captures, err := captureRepo.ListUnassigned(ctx, pageSize, offset)
+		if err != nil {
+			log.Fatalf("Failed to list photo captures: %v", err)
+		}
+		for _, capture := range captures {
+			refs[storage.PhotoKey(capture.Filename)] = capture.SHA256
+		}
+		if len(captures) < pageSize {
+			break
+		}
+	}
+
+	report, err := storage.Fsck(ctx, blobs, refs, *verify)
+	if err != nil {
+		log.Fatalf("Failed to check storage: %v", err)
+	}
+
+	for _, key := range report.Missing {
+		fmt.Printf("missing   %s\n", key)
+	}
+	for _, blob := range report.Orphaned {
+		fmt.Printf("orphaned  %s (%d bytes, %s)\n", blob.Key, blob.Size, blob.ModTime.Format("2006-01-02 15:04"))
+	}
+	for _, key := range report.Corrupt {
+		fmt.Printf("corrupt   %s\n", key)
+	}
+	log.Printf("Checked %d blobs against %d references: %d missing, %d orphaned, %d corrupt",
+		report.Checked, len(refs), len(report.Missing), len(report.Orphaned), len(report.Corrupt))
+
+	if !report.Clean() {
+		os.Exit(1)
+	}
+}
@@ -8,17 +8,26 @@ import (
 	"math/rand"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/project-capillary/backend/internal/application/usecases"
 	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/valueobjects"
 	"github.com/project-capillary/backend/internal/infrastructure/config"
+	"github.com/project-capillary/backend/internal/infrastructure/db/migrations"
 	"github.com/project-capillary/backend/internal/infrastructure/db/postgres"
+	"github.com/project-capillary/backend/internal/infrastructure/hl7"
 	"github.com/project-capillary/backend/internal/infrastructure/mq"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
 )
 
+const inboxCleanupInterval = time.Hour
+
 func main() {
 	cfg := config.Load()
 
@@ -37,13 +46,48 @@ func main() {
 
 	log.Println("Worker: Database connection established")
 
+	if cfg.Database.AutoMigrate {
+		migrateDatabase(db)
+	}
+
+	keys, _, err := pii.LoadOrCreateKeyring(cfg.PII.KeyFile)
+	if err != nil {
+		log.Fatalf("Failed to load PII key file: %v", err)
+	}
+
 	analysisRepo := postgres.NewAnalysisRepository(db.DB)
 	examinationRepo := postgres.NewExaminationRepository(db.DB)
 	reportRepo := postgres.NewReportRepository(db.DB)
-	userRepo := postgres.NewUserRepository(db.DB)
 	imageRepo := postgres.NewImageRepository(db.DB)
+	patientRepo := postgres.NewPatientRepository(db.DB, keys)
+	hl7MessageRepo := postgres.NewHL7MessageRepository(db.DB)
+	txManager := postgres.NewTransactionManager(db.DB)
 
-	reportUseCase := usecases.NewReportUseCase(reportRepo, examinationRepo, analysisRepo, imageRepo)
+	blobs, err := storage.NewBlobStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to open photo storage: %v", err)
+	}
+
+	var hl7Client *hl7.Client
+	if cfg.HL7.Enabled() {
+		hl7Client = hl7.NewClient(cfg.HL7.MLLPAddress, cfg.HL7.Timeout)
+	}
+	hl7UseCase := usecases.NewHL7UseCase(
+		reportRepo,
+		examinationRepo,
+		patientRepo,
+		analysisRepo,
+		hl7MessageRepo,
+		hl7Client,
+		hl7.Header{
+			SendingApplication:   cfg.HL7.SendingApplication,
+			SendingFacility:      cfg.HL7.SendingFacility,
+			ReceivingApplication: cfg.HL7.ReceivingApplication,
+			ReceivingFacility:    cfg.HL7.ReceivingFacility,
+		},
+		cfg.HL7.MaxAttempts,
+		cfg.HL7.RetryDelay,
+	)
 
 	mqConsumer, err := mq.NewRabbitMQ(cfg.RabbitMQ.URL, cfg.RabbitMQ.QueueName)
 	if err != nil {
@@ -65,6 +109,12 @@ func main() {
 		cancel()
 	}()
 
+	if cfg.Storage.InboxRetentionDays > 0 {
+		// CleanupStale returns no photo links, so it needs no URL signer.
+		inboxUseCase := usecases.NewPhotoInboxUseCase(postgres.NewPhotoCaptureRepository(db.DB), imageRepo, blobs, nil)
+		go cleanupInbox(ctx, inboxUseCase, time.Duration(cfg.Storage.InboxRetentionDays)*24*time.Hour)
+	}
+
 	log.Println("Worker: Starting message consumer...")
 
 	err = mqConsumer.Consume(ctx, func(body []byte) error {
@@ -90,6 +140,12 @@ func main() {
 		analysis.StartProcessing()
 		analysisRepo.Update(ctx, analysis)
 
+		if err := readImage(ctx, blobs, imageRepo, taskMsg); err != nil {
+			log.Printf("Worker: Failed to read image %s: %v", taskMsg.ImagePath, err)
+			analysis.Fail(fmt.Sprintf("image unavailable: %v", err))
+			return analysisRepo.Update(ctx, analysis)
+		}
+
 		time.Sleep(2 * time.Second)
 
 		metrics := generateMockMetrics()
@@ -103,7 +159,7 @@ func main() {
 
 		log.Printf("Worker: Completed analysis task %s", taskMsg.AnalysisID)
 
-		err = checkAndGenerateReport(ctx, analysis.ExaminationID, analysisRepo, examinationRepo, reportRepo, reportUseCase, userRepo)
+		err = checkAndGenerateReport(ctx, analysis.ExaminationID, txManager, analysisRepo, examinationRepo, imageRepo, reportRepo, hl7UseCase)
 		if err != nil {
 			log.Printf("Worker: Failed to check/generate report: %v", err)
 		}
@@ -118,6 +174,42 @@ func main() {
 	log.Println("Worker: Shutdown complete")
 }
 
+// cleanupInbox deletes captured photos nobody attached to an examination
+// within maxAge, once at startup and then hourly.
+func cleanupInbox(ctx context.Context, inbox *usecases.PhotoInboxUseCase, maxAge time.Duration) {
+	ticker := time.NewTicker(inboxCleanupInterval)
+	defer ticker.Stop()
+	for {
+		removed, err := inbox.CleanupStale(ctx, maxAge)
+		if err != nil {
+			log.Printf("Worker: Failed to clean up photo inbox: %v", err)
+		} else if removed > 0 {
+			log.Printf("Worker: Removed %d stale photos from the inbox", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// readImage loads the photo an analysis runs on and checks it against the
+// checksum recorded at capture. The mock analysis only needs it readable.
+func readImage(ctx context.Context, blobs storage.BlobStore, imageRepo *postgres.ImageRepositoryImpl, taskMsg mq.AnalysisTaskMessage) error {
+	key, want := storage.PhotoKey(taskMsg.ImagePath), ""
+	image, err := imageRepo.GetByID(ctx, taskMsg.ImageID)
+	if err != nil {
+		return err
+	}
+	if image != nil {
+		key, want = storage.PhotoKey(image.Filename), image.SHA256
+	}
+	_, _, err = storage.ReadVerified(ctx, blobs, key, want)
+	return err
+}
+
 func generateMockMetrics() map[string]interface{} {
 	return map[string]interface{}{
 		"density":       7.0 + rand.Float64()*3.0,
@@ -132,78 +224,102 @@ func generateMockMetrics() map[string]interface{} {
 func checkAndGenerateReport(
 	ctx context.Context,
 	examinationID string,
+	txManager *postgres.TransactionManagerImpl,
 	analysisRepo *postgres.AnalysisRepositoryImpl,
 	examinationRepo *postgres.ExaminationRepositoryImpl,
+	imageRepo *postgres.ImageRepositoryImpl,
 	reportRepo *postgres.ReportRepositoryImpl,
-	reportUseCase *usecases.ReportUseCase,
-	userRepo *postgres.UserRepositoryImpl,
+	hl7UseCase *usecases.HL7UseCase,
 ) error {
-	analyses, err := analysisRepo.GetByExaminationID(ctx, examinationID)
-	if err != nil {
-		return fmt.Errorf("failed to get analyses: %w", err)
-	}
+	var report *entities.Report
+	err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The row lock makes analyses of one examination that finish together
+		// wait for each other, so only the last one generates the report.
+		examination, err := examinationRepo.GetByIDForUpdate(ctx, examinationID)
+		if err != nil || examination == nil {
+			return fmt.Errorf("failed to get examination: %w", err)
+		}
 
-	if len(analyses) == 0 {
-		return nil
-	}
-	allCompleted := true
-	for _, analysis := range analyses {
-		if analysis.Status != entities.AnalysisStatusCompleted {
-			allCompleted = false
-			break
+		analyses, err := analysisRepo.GetByExaminationID(ctx, examinationID)
+		if err != nil {
+			return fmt.Errorf("failed to get analyses: %w", err)
 		}
-	}
 
-	if !allCompleted {
-		log.Printf("Worker: Not all analyses completed for examination %s", examinationID)
-		return nil
-	}
+		if len(analyses) == 0 {
+			return nil
+		}
+		allCompleted := true
+		for _, analysis := range analyses {
+			if analysis.Status != entities.AnalysisStatusCompleted {
+				allCompleted = false
+				break
+			}
+		}
 
-	existingReport, err := reportRepo.GetByExaminationID(ctx, examinationID)
-	if err == nil && existingReport != nil {
-		log.Printf("Worker: Report already exists for examination %s", examinationID)
-		return nil
-	}
+		if !allCompleted {
+			log.Printf("Worker: Not all analyses completed for examination %s", examinationID)
+			return nil
+		}
 
-	examination, err := examinationRepo.GetByID(ctx, examinationID)
-	if err != nil || examination == nil {
-		return fmt.Errorf("failed to get examination: %w", err)
-	}
+		existingReport, err := reportRepo.GetByExaminationID(ctx, examinationID)
+		if err == nil && existingReport != nil {
+			log.Printf("Worker: Report already exists for examination %s", examinationID)
+			return nil
+		}
 
-	summary, diagnosis, recommendations := generateReportContent(analyses)
+		images, err := imageRepo.GetByExaminationID(ctx, examinationID)
+		if err != nil {
+			return fmt.Errorf("failed to get images: %w", err)
+		}
+		positions := make(map[string]string, len(images))
+		for _, image := range images {
+			positions[image.ID] = image.Position
+		}
 
-	systemUser, err := userRepo.GetByUsername(ctx, "system")
-	if err != nil || systemUser == nil {
-		users, err := userRepo.List(ctx, 1, 0)
-		if err != nil || len(users) == 0 {
-			return fmt.Errorf("no users found to assign as report generator")
+		summary, diagnosis, recommendations := generateReportContent(analyses)
+		content := fmt.Sprintf("Проанализировано изображений: %d\n\nДетали:\n%s", len(analyses), summary)
+		if fingers := fingerSummary(analyses, positions); fingers != "" {
+			content += "\n\n" + fingers
 		}
-		systemUser = users[0]
-	}
 
-	report := entities.NewReport(
-		uuid.New().String(),
-		examinationID,
-		fmt.Sprintf("Отчёт по исследованию %s", examination.Description),
-		fmt.Sprintf("Проанализировано изображений: %d\n\nДетали:\n%s", len(analyses), summary),
-		summary,
-		diagnosis,
-		recommendations,
-		systemUser.ID,
-	)
+		// The examination's doctor, a user of the same clinic, stands as the
+		// author of the generated report.
+		report = entities.NewReport(
+			uuid.New().String(),
+			examinationID,
+			fmt.Sprintf("Отчёт по исследованию %s", examination.Description),
+			content,
+			summary,
+			diagnosis,
+			recommendations,
+			examination.DoctorID,
+		)
+
+		if err := reportRepo.Create(ctx, report); err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
 
-	err = reportRepo.Create(ctx, report)
-	if err != nil {
-		return fmt.Errorf("failed to create report: %w", err)
+		examination.Complete()
+		if err := examinationRepo.Update(ctx, examination); err != nil {
+			return fmt.Errorf("failed to update examination status: %w", err)
+		}
+		return nil
+	})
+	if err != nil || report == nil {
+		return err
 	}
 
-	examination.Complete()
-	err = examinationRepo.Update(ctx, examination)
-	if err != nil {
-		log.Printf("Worker: Failed to update examination status: %v", err)
+	log.Printf("Worker: Successfully generated report %s for examination %s", report.ID, examinationID)
+
+	if hl7UseCase.Enabled() {
+		message, err := hl7UseCase.SendReport(ctx, report.ID)
+		if err != nil {
+			log.Printf("Worker: Failed to send HL7 result for report %s: %v", report.ID, err)
+			return nil
+		}
+		log.Printf("Worker: HL7 result %s for report %s: %s", message.ControlID, report.ID, message.Status)
 	}
 
-	log.Printf("Worker: Successfully generated report %s for examination %s", report.ID, examinationID)
 	return nil
 }
 
@@ -255,3 +371,68 @@ func generateReportContent(analyses []*entities.Analysis) (summary, diagnosis, r
 
 	return summary, diagnosis, recommendations
 }
+
+// fingerSummary averages the metrics of each finger position, in hand and
+// finger order; positions maps image IDs to their position. Examinations
+// without positions have no per-finger summary.
+func fingerSummary(analyses []*entities.Analysis, positions map[string]string) string {
+	type fingerMetrics struct {
+		images                        int
+		density, diameter, tortuosity float64
+	}
+	byPosition := make(map[string]*fingerMetrics)
+	for _, analysis := range analyses {
+		position := positions[analysis.ImageID]
+		if position == "" {
+			continue
+		}
+		finger, ok := byPosition[position]
+		if !ok {
+			finger = &fingerMetrics{}
+			byPosition[position] = finger
+		}
+		finger.images++
+		density, _ := analysis.Metrics["density"].(float64)
+		diameter, _ := analysis.Metrics["diameter"].(float64)
+		tortuosity, _ := analysis.Metrics["tortuosity"].(float64)
+		finger.density += density
+		finger.diameter += diameter
+		finger.tortuosity += tortuosity
+	}
+	if len(byPosition) == 0 {
+		return ""
+	}
+
+	order := make([]string, 0, len(byPosition))
+	for position := range byPosition {
+		order = append(order, position)
+	}
+	sort.Strings(order)
+
+	var b strings.Builder
+	b.WriteString("Показатели по пальцам:")
+	for _, position := range order {
+		finger := byPosition[position]
+		n := float64(finger.images)
+		fmt.Fprintf(&b, "\n- %s (%s), изображений %d: плотность %.2f сосудов/мм², диаметр %.2f мкм, извитость %.2f",
+			valueobjects.PositionLabel(position), position, finger.images,
+			finger.density/n, finger.diameter/n, finger.tortuosity/n)
+	}
+	return b.String()
+}
+
+// migrateDatabase applies pending migrations. The API and worker may both do
+// this at startup; the migration lock lets only one of them run each one.
+func migrateDatabase(db *postgres.PostgresDB) {
+	migrator, err := postgres.NewMigrator(db.DB, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to apply migrations: %v", err)
+	}
+	for _, migration := range applied {
+		log.Printf("Applied migration %s", migration)
+	}
+}
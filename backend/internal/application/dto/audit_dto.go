@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type AuditListQuery struct {
+	PageRequest
+	ActorID      string     `form:"actor_id"`
+	Action       string     `form:"action"`
+	ResourceType string     `form:"resource_type"`
+	ResourceID   string     `form:"resource_id"`
+	From         *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type AuditEntryResponse struct {
+	ID           string    `json:"id"`
+	Sequence     int64     `json:"sequence"`
+	ActorID      string    `json:"actor_id,omitempty"`
+	ActorRole    string    `json:"actor_role,omitempty"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id,omitempty"`
+	Route        string    `json:"route,omitempty"`
+	Status       int       `json:"status,omitempty"`
+	ClientIP     string    `json:"client_ip,omitempty"`
+	RequestID    string    `json:"request_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	PrevHash     string    `json:"prev_hash,omitempty"`
+	Hash         string    `json:"hash"`
+}
+
+type AuditVerifyResponse struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
@@ -19,3 +19,55 @@ type PaginatedResponse struct {
 	PageSize   int         `json:"page_size"`
 	TotalPages int         `json:"total_pages"`
 }
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PageRequest accepts either page/page_size or the older limit/offset pair;
+// page/page_size wins when both are given.
+type PageRequest struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1"`
+	Limit    int `form:"limit" binding:"omitempty,min=1"`
+	Offset   int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Bounds returns the limit and offset to query with and the page number to
+// report back, clamping the page size to MaxPageSize.
+func (p PageRequest) Bounds() (limit, offset, page int) {
+	limit = p.PageSize
+	if limit == 0 {
+		limit = p.Limit
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	if p.Page > 0 || p.Offset == 0 {
+		page = p.Page
+		if page < 1 {
+			page = 1
+		}
+		return limit, (page - 1) * limit, page
+	}
+	return limit, p.Offset, p.Offset/limit + 1
+}
+
+func NewPaginatedResponse(data interface{}, total int64, page, pageSize int) *PaginatedResponse {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = int((total + int64(pageSize) - 1) / int64(pageSize))
+	}
+	return &PaginatedResponse{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}
+}
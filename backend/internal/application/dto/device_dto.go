@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type CreateDeviceRequest struct {
+	Name     string `json:"name" binding:"required"`
+	DeviceID string `json:"device_id" binding:"required"`
+	Label    string `json:"label"`
+}
+
+// UpdateDeviceRequest changes the fields that are set.
+type UpdateDeviceRequest struct {
+	Name       string   `json:"name"`
+	Label      *string  `json:"label"`
+	Brightness *float64 `json:"brightness"`
+	Zoom       *float64 `json:"zoom"`
+}
+
+type DeviceResponse struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	DeviceID   string    `json:"device_id"`
+	Label      string    `json:"label"`
+	Status     string    `json:"status"`
+	LastSeen   time.Time `json:"last_seen"`
+	Brightness float64   `json:"brightness"`
+	Zoom       float64   `json:"zoom"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -5,11 +5,14 @@ import "time"
 type CreateExaminationRequest struct {
 	PatientID   string `json:"patient_id" binding:"required"`
 	DoctorID    string `json:"doctor_id" binding:"required"`
+	ExamType    string `json:"exam_type"`
 	Description string `json:"description"`
 }
 
 type AttachPhotosRequest struct {
 	Photos []string `json:"photos" binding:"required"`
+	// Position is the finger all photos were taken of, e.g. "R4".
+	Position string `json:"position"`
 }
 
 type UpdateExaminationRequest struct {
@@ -22,6 +25,7 @@ type ExaminationResponse struct {
 	PatientID   string     `json:"patient_id"`
 	DoctorID    string     `json:"doctor_id"`
 	Status      string     `json:"status"`
+	ExamType    string     `json:"exam_type"`
 	Description string     `json:"description"`
 	Images      []string   `json:"images"`
 	CreatedAt   time.Time  `json:"created_at"`
@@ -29,6 +33,21 @@ type ExaminationResponse struct {
 	CompletedAt *time.Time `json:"completed_at"`
 }
 
+// CaptureProgressResponse compares the images of an examination with the
+// capture protocol of its type.
+type CaptureProgressResponse struct {
+	ExamType  string                     `json:"exam_type"`
+	Complete  bool                       `json:"complete"`
+	Positions []PositionProgressResponse `json:"positions"`
+}
+
+type PositionProgressResponse struct {
+	Position string `json:"position"`
+	Label    string `json:"label"`
+	Required int    `json:"required"`
+	Captured int    `json:"captured"`
+}
+
 type StartAnalysisRequest struct {
 	ExaminationID string `json:"examination_id" binding:"required"`
 }
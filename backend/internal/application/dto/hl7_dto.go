@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type HL7MessageResponse struct {
+	ID             string     `json:"id"`
+	ReportID       string     `json:"report_id"`
+	ControlID      string     `json:"control_id"`
+	MessageType    string     `json:"message_type"`
+	Destination    string     `json:"destination"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	AckCode        string     `json:"ack_code,omitempty"`
+	AckMessage     string     `json:"ack_message,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	Payload        string     `json:"payload"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
@@ -3,14 +3,47 @@ package dto
 import "time"
 
 type ImageResponse struct {
-	ID            string    `json:"id"`
-	ExaminationID string    `json:"examination_id"`
-	Filename      string    `json:"filename"`
-	FilePath      string    `json:"file_path"`
-	FileSize      int64     `json:"file_size"`
-	MimeType      string    `json:"mime_type"`
-	Width         int       `json:"width"`
-	Height        int       `json:"height"`
-	CapturedAt    time.Time `json:"captured_at"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            string `json:"id"`
+	ExaminationID string `json:"examination_id"`
+	Filename      string `json:"filename"`
+	FilePath      string `json:"file_path"`
+	FileSize      int64  `json:"file_size"`
+	MimeType      string `json:"mime_type"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+	Position      string `json:"position,omitempty"`
+	SHA256        string `json:"sha256,omitempty"`
+	// Metadata is read from the file when it is attached; images attached
+	// before that have none.
+	Metadata   *ImageMetadataResponse `json:"metadata,omitempty"`
+	CapturedAt time.Time              `json:"captured_at"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type ImageMetadataResponse struct {
+	Format       string     `json:"format"`
+	ColorSpace   string     `json:"color_space,omitempty"`
+	DPI          int        `json:"dpi,omitempty"`
+	TakenAt      *time.Time `json:"taken_at,omitempty"`
+	CameraMake   string     `json:"camera_make,omitempty"`
+	CameraModel  string     `json:"camera_model,omitempty"`
+	ExposureTime float64    `json:"exposure_time,omitempty"`
+	ISO          int        `json:"iso,omitempty"`
+}
+
+// ImageUpload is one file of a multipart image upload.
+type ImageUpload struct {
+	Filename string
+	Position string
+	Data     []byte
+}
+
+// ImageContent is the file of an image or of one of its downscaled
+// renditions.
+type ImageContent struct {
+	Name        string
+	ContentType string
+	// ETag is the SHA-256 of Data.
+	ETag string
+	Data []byte
 }
@@ -3,13 +3,15 @@ package dto
 import "time"
 
 type CreatePatientRequest struct {
-	FirstName   string    `json:"first_name" binding:"required"`
-	LastName    string    `json:"last_name" binding:"required"`
-	MiddleName  string    `json:"middle_name"`
-	DateOfBirth time.Time `json:"date_of_birth" binding:"required"`
-	Gender      string    `json:"gender" binding:"required"`
-	Phone       string    `json:"phone"`
-	Email       string    `json:"email"`
+	FirstName      string    `json:"first_name" binding:"required"`
+	LastName       string    `json:"last_name" binding:"required"`
+	MiddleName     string    `json:"middle_name"`
+	DateOfBirth    time.Time `json:"date_of_birth" binding:"required"`
+	Gender         string    `json:"gender" binding:"required"`
+	Phone          string    `json:"phone"`
+	Email          string    `json:"email"`
+	ExternalSystem string    `json:"external_system"`
+	ExternalID     string    `json:"external_id"`
 }
 
 type UpdatePatientRequest struct {
@@ -21,14 +23,100 @@ type UpdatePatientRequest struct {
 }
 
 type PatientResponse struct {
-	ID          string    `json:"id"`
-	FirstName   string    `json:"first_name"`
-	LastName    string    `json:"last_name"`
-	MiddleName  string    `json:"middle_name"`
-	DateOfBirth time.Time `json:"date_of_birth"`
-	Gender      string    `json:"gender"`
-	Phone       string    `json:"phone"`
-	Email       string    `json:"email"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             string    `json:"id"`
+	FirstName      string    `json:"first_name"`
+	LastName       string    `json:"last_name"`
+	MiddleName     string    `json:"middle_name"`
+	DateOfBirth    time.Time `json:"date_of_birth"`
+	Gender         string    `json:"gender"`
+	Phone          string    `json:"phone"`
+	Email          string    `json:"email"`
+	ExternalSystem string    `json:"external_system,omitempty"`
+	ExternalID     string    `json:"external_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Version        int       `json:"version"`
+
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	LegalHoldUntil  *time.Time `json:"legal_hold_until,omitempty"`
+	LegalHoldReason string     `json:"legal_hold_reason,omitempty"`
+
+	PossibleDuplicates []PatientDuplicate `json:"possible_duplicates,omitempty"`
+}
+
+type PatientDuplicate struct {
+	Patient *PatientResponse `json:"patient"`
+	Score   float64          `json:"score"`
+	Reasons []string         `json:"reasons"`
+}
+
+type PatientDuplicatePair struct {
+	First   *PatientResponse `json:"first"`
+	Second  *PatientResponse `json:"second"`
+	Score   float64          `json:"score"`
+	Reasons []string         `json:"reasons"`
+}
+
+type MergePatientRequest struct {
+	DuplicateID string `json:"duplicate_id" binding:"required"`
+}
+
+type PatientMergeResponse struct {
+	ID             string           `json:"id"`
+	SurvivorID     string           `json:"survivor_id"`
+	MergedID       string           `json:"merged_id"`
+	MergedBy       string           `json:"merged_by"`
+	MergedPatient  *PatientResponse `json:"merged_patient"`
+	ExaminationIDs []string         `json:"examination_ids"`
+	ImageIDs       []string         `json:"image_ids"`
+	ReportIDs      []string         `json:"report_ids"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+type PatientImportRecord struct {
+	Line      int    `json:"line"`
+	Status    string `json:"status"`
+	PatientID string `json:"patient_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type PatientImportResult struct {
+	Total   int                   `json:"total"`
+	Created int                   `json:"created"`
+	Updated int                   `json:"updated"`
+	Failed  int                   `json:"failed"`
+	Records []PatientImportRecord `json:"records"`
+}
+
+type PatientListQuery struct {
+	PageRequest
+	Query   string `form:"q"`
+	Gender  string `form:"gender" binding:"omitempty,oneof=male female other"`
+	MinAge  *int   `form:"min_age" binding:"omitempty,min=0,max=150"`
+	MaxAge  *int   `form:"max_age" binding:"omitempty,min=0,max=150"`
+	Sort    string `form:"sort" binding:"omitempty,oneof=relevance created_at -created_at updated_at -updated_at last_name -last_name date_of_birth -date_of_birth"`
+	Deleted bool   `form:"deleted"`
+}
+
+type LegalHoldRequest struct {
+	Until  time.Time `json:"until" binding:"required"`
+	Reason string    `json:"reason" binding:"required"`
+}
+
+// ErasePatientRequest is recorded as requested by the caller.
+type ErasePatientRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	Mode   string `json:"mode" binding:"omitempty,oneof=anonymize delete"`
+}
+
+type PatientErasureResponse struct {
+	ID               string    `json:"id"`
+	PatientID        string    `json:"patient_id"`
+	RequestedBy      string    `json:"requested_by"`
+	Reason           string    `json:"reason"`
+	Mode             string    `json:"mode"`
+	ExaminationCount int       `json:"examination_count"`
+	ImageCount       int       `json:"image_count"`
+	FilesDeleted     int       `json:"files_deleted"`
+	CreatedAt        time.Time `json:"created_at"`
 }
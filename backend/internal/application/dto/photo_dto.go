@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// PhotoCaptureResponse is a photo in the inbox of captures not yet attached
+// to an examination.
+type PhotoCaptureResponse struct {
+	ID         string    `json:"id"`
+	Filename   string    `json:"filename"`
+	URL        string    `json:"url"`
+	FileSize   int64     `json:"size"`
+	MimeType   string    `json:"mime_type"`
+	CapturedBy string    `json:"captured_by,omitempty"`
+	DeviceID   string    `json:"device_id,omitempty"`
+	CapturedAt time.Time `json:"captured_at"`
+}
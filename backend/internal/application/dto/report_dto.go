@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/project-capillary/backend/internal/infrastructure/signing"
+)
 
 type CreateReportRequest struct {
 	ExaminationID   string `json:"examination_id" binding:"required"`
@@ -28,13 +32,29 @@ type ReportResponse struct {
 	Diagnosis       string      `json:"diagnosis"`
 	Recommendations string      `json:"recommendations"`
 	GeneratedBy     string      `json:"generated_by"`
+	Status          string      `json:"status"`
+	SignedBy        string      `json:"signed_by,omitempty"`
+	SignedAt        *time.Time  `json:"signed_at,omitempty"`
 	Images          []ImageInfo `json:"images,omitempty"`
 	CreatedAt       time.Time   `json:"created_at"`
 	UpdatedAt       time.Time   `json:"updated_at"`
+	Version         int         `json:"version"`
 }
 
+// ImageInfo links to an image with short-lived signed URLs that work in
+// <img> tags without a bearer token.
 type ImageInfo struct {
-	ID       string `json:"id"`
-	Filename string `json:"filename"`
-	URL      string `json:"url"`
+	ID           string `json:"id"`
+	Filename     string `json:"filename"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+type ReportVerificationResponse struct {
+	ReportID string          `json:"report_id"`
+	Valid    bool            `json:"valid"`
+	Reason   string          `json:"reason,omitempty"`
+	SignedBy string          `json:"signed_by,omitempty"`
+	SignedAt *time.Time      `json:"signed_at,omitempty"`
+	Bundle   *signing.Bundle `json:"bundle,omitempty"`
 }
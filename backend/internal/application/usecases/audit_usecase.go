@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+)
+
+// auditVerifyBatch is how many entries Verify reads per query.
+const auditVerifyBatch = 1000
+
+// AuditEvent describes one access to be recorded; the actor and request
+// details come from the context.
+type AuditEvent struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Route        string
+	Status       int
+}
+
+type requestInfo struct {
+	clientIP  string
+	requestID string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo attaches the client address and request ID that audit
+// entries recorded under ctx are attributed to.
+func WithRequestInfo(ctx context.Context, clientIP, requestID string) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, requestInfo{clientIP: clientIP, requestID: requestID})
+}
+
+type AuditUseCase struct {
+	auditRepo repositories.AuditRepository
+}
+
+func NewAuditUseCase(auditRepo repositories.AuditRepository) *AuditUseCase {
+	return &AuditUseCase{auditRepo: auditRepo}
+}
+
+func (uc *AuditUseCase) Record(ctx context.Context, event AuditEvent) error {
+	entry := entities.NewAuditEntry(uuid.New().String(), event.Action, event.ResourceType, event.ResourceID)
+	entry.Route = event.Route
+	entry.Status = event.Status
+	if claims := auth.ClaimsFromContext(ctx); claims != nil {
+		entry.OrganizationID = claims.OrganizationID
+		entry.ActorID = claims.UserID
+		entry.ActorRole = string(claims.Role)
+	}
+	if info, ok := ctx.Value(requestInfoKey{}).(requestInfo); ok {
+		entry.ClientIP = info.clientIP
+		entry.RequestID = info.requestID
+	}
+	return uc.auditRepo.Append(ctx, entry)
+}
+
+func (uc *AuditUseCase) ListEntries(ctx context.Context, query dto.AuditListQuery) (*dto.PaginatedResponse, error) {
+	limit, offset, page := query.Bounds()
+	filter := repositories.AuditFilter{
+		ActorID:      query.ActorID,
+		Action:       query.Action,
+		ResourceType: query.ResourceType,
+		ResourceID:   query.ResourceID,
+		From:         query.From,
+		To:           query.To,
+		Limit:        limit,
+		Offset:       offset,
+	}
+
+	entries, err := uc.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := uc.auditRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, toAuditEntryResponse(entry))
+	}
+	return dto.NewPaginatedResponse(response, total, page, limit), nil
+}
+
+// Verify walks the whole chain and reports the first entry whose hash or
+// link to its predecessor does not check out.
+func (uc *AuditUseCase) Verify(ctx context.Context) (*dto.AuditVerifyResponse, error) {
+	response := &dto.AuditVerifyResponse{Valid: true}
+	var sequence int64
+	var prevHash string
+	for {
+		entries, err := uc.auditRepo.ListAfter(ctx, sequence, auditVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.Sequence != sequence+1 || !entry.Verify(prevHash) {
+				brokenAt := entry.Sequence
+				response.Valid = false
+				response.BrokenAt = &brokenAt
+				return response, nil
+			}
+			sequence, prevHash = entry.Sequence, entry.Hash
+			response.Checked++
+		}
+
+		if len(entries) < auditVerifyBatch {
+			return response, nil
+		}
+	}
+}
+
+func toAuditEntryResponse(entry *entities.AuditEntry) *dto.AuditEntryResponse {
+	return &dto.AuditEntryResponse{
+		ID:           entry.ID,
+		Sequence:     entry.Sequence,
+		ActorID:      entry.ActorID,
+		ActorRole:    entry.ActorRole,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Route:        entry.Route,
+		Status:       entry.Status,
+		ClientIP:     entry.ClientIP,
+		RequestID:    entry.RequestID,
+		CreatedAt:    entry.CreatedAt,
+		PrevHash:     entry.PrevHash,
+		Hash:         entry.Hash,
+	}
+}
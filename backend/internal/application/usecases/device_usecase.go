@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+)
+
+// DeviceUseCase keeps the registry of the clinic's capture devices.
+// Devices register themselves when they connect over WebSocket and can be
+// added ahead of time.
+type DeviceUseCase struct {
+	deviceRepo repositories.DeviceRepository
+}
+
+func NewDeviceUseCase(deviceRepo repositories.DeviceRepository) *DeviceUseCase {
+	return &DeviceUseCase{deviceRepo: deviceRepo}
+}
+
+func (uc *DeviceUseCase) ListDevices(ctx context.Context) ([]dto.DeviceResponse, error) {
+	devices, err := uc.deviceRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toDeviceResponses(devices), nil
+}
+
+func (uc *DeviceUseCase) ListOnlineDevices(ctx context.Context) ([]dto.DeviceResponse, error) {
+	devices, err := uc.deviceRepo.GetOnlineDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toDeviceResponses(devices), nil
+}
+
+func (uc *DeviceUseCase) GetDevice(ctx context.Context, id string) (*dto.DeviceResponse, error) {
+	device, err := uc.device(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	response := toDeviceResponse(device)
+	return &response, nil
+}
+
+// CreateDevice registers a device before it first connects.
+func (uc *DeviceUseCase) CreateDevice(ctx context.Context, req dto.CreateDeviceRequest) (*dto.DeviceResponse, error) {
+	organizationID := auth.OrganizationID(ctx)
+	if organizationID == "" {
+		return nil, ErrOrganizationNotFound
+	}
+	deviceID := strings.TrimSpace(req.DeviceID)
+	existing, err := uc.deviceRepo.GetByDeviceID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDeviceExists, deviceID)
+	}
+
+	device := entities.NewDevice(uuid.New().String(), strings.TrimSpace(req.Name), deviceID, strings.TrimSpace(req.Label))
+	device.OrganizationID = organizationID
+	if err := uc.deviceRepo.Create(ctx, device); err != nil {
+		return nil, err
+	}
+	response := toDeviceResponse(device)
+	return &response, nil
+}
+
+func (uc *DeviceUseCase) UpdateDevice(ctx context.Context, id string, req dto.UpdateDeviceRequest) (*dto.DeviceResponse, error) {
+	device, err := uc.device(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name, label := device.Name, device.Label
+	if req.Name != "" {
+		name = strings.TrimSpace(req.Name)
+	}
+	if req.Label != nil {
+		label = strings.TrimSpace(*req.Label)
+	}
+	device.Rename(name, label)
+	if req.Brightness != nil {
+		if *req.Brightness < 0 || *req.Brightness > 1 {
+			return nil, ErrInvalidDeviceSetting
+		}
+		device.SetBrightness(*req.Brightness)
+	}
+	if req.Zoom != nil {
+		if *req.Zoom < 1 || *req.Zoom > 10 {
+			return nil, ErrInvalidDeviceSetting
+		}
+		device.SetZoom(*req.Zoom)
+	}
+
+	if err := uc.deviceRepo.Update(ctx, device); err != nil {
+		return nil, err
+	}
+	response := toDeviceResponse(device)
+	return &response, nil
+}
+
+func (uc *DeviceUseCase) DeleteDevice(ctx context.Context, id string) error {
+	if _, err := uc.device(ctx, id); err != nil {
+		return err
+	}
+	return uc.deviceRepo.Delete(ctx, id)
+}
+
+// Connect marks a device of the connection's organization online when it
+// identifies itself, registering it on first contact under its device ID.
+func (uc *DeviceUseCase) Connect(ctx context.Context, organizationID, deviceID, name, label string) error {
+	if organizationID == "" {
+		return ErrDeviceAnonymous
+	}
+	if name == "" {
+		name = deviceID
+	}
+
+	device := entities.NewDevice(uuid.New().String(), name, deviceID, label)
+	device.OrganizationID = organizationID
+	device.UpdateStatus(entities.DeviceStatusOnline)
+	return uc.deviceRepo.Upsert(ctx, device)
+}
+
+// Disconnect marks the device an organization's connection registered as
+// offline once its last connection has closed.
+func (uc *DeviceUseCase) Disconnect(ctx context.Context, organizationID, deviceID string) error {
+	if organizationID == "" {
+		return ErrDeviceAnonymous
+	}
+	return uc.deviceRepo.MarkOffline(ctx, organizationID, deviceID)
+}
+
+func (uc *DeviceUseCase) device(ctx context.Context, id string) (*entities.Device, error) {
+	device, err := uc.deviceRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrDeviceNotFound
+	}
+	return device, nil
+}
+
+func toDeviceResponses(devices []*entities.Device) []dto.DeviceResponse {
+	response := make([]dto.DeviceResponse, 0, len(devices))
+	for _, device := range devices {
+		response = append(response, toDeviceResponse(device))
+	}
+	return response
+}
+
+func toDeviceResponse(device *entities.Device) dto.DeviceResponse {
+	return dto.DeviceResponse{
+		ID:         device.ID,
+		Name:       device.Name,
+		DeviceID:   device.DeviceID,
+		Label:      device.Label,
+		Status:     string(device.Status),
+		LastSeen:   device.LastSeen,
+		Brightness: device.Brightness,
+		Zoom:       device.Zoom,
+		CreatedAt:  device.CreatedAt,
+		UpdatedAt:  device.UpdatedAt,
+	}
+}
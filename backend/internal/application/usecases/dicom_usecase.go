@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/dicom"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
+)
+
+type DICOMUseCase struct {
+	patientRepo     repositories.PatientRepository
+	examinationRepo repositories.ExaminationRepository
+	imageRepo       repositories.ImageRepository
+	blobs           storage.BlobStore
+}
+
+func NewDICOMUseCase(
+	patientRepo repositories.PatientRepository,
+	examinationRepo repositories.ExaminationRepository,
+	imageRepo repositories.ImageRepository,
+	blobs storage.BlobStore,
+) *DICOMUseCase {
+	return &DICOMUseCase{
+		patientRepo:     patientRepo,
+		examinationRepo: examinationRepo,
+		imageRepo:       imageRepo,
+		blobs:           blobs,
+	}
+}
+
+// ExportExamination returns a zip archive with one Secondary Capture .dcm
+// file per examination image, ordered by capture time.
+func (uc *DICOMUseCase) ExportExamination(ctx context.Context, examinationID string) ([]byte, error) {
+	examination, err := uc.examinationRepo.GetByID(ctx, examinationID)
+	if err != nil {
+		return nil, err
+	}
+	if examination == nil {
+		return nil, ErrExaminationNotFound
+	}
+
+	patient, err := uc.patientRepo.GetByID(ctx, examination.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, ErrPatientNotFound
+	}
+
+	images, err := uc.imageRepo.GetByExaminationID(ctx, examination.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].CapturedAt.Equal(images[j].CapturedAt) {
+			return images[i].ID < images[j].ID
+		}
+		return images[i].CapturedAt.Before(images[j].CapturedAt)
+	})
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for i, img := range images {
+		data, _, err := storage.ReadVerified(ctx, uc.blobs, storage.PhotoKey(img.Filename), img.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %s: %w", img.Filename, err)
+		}
+
+		file, err := dicom.NewSecondaryCapture(patient, examination, img, i+1, data)
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(img.Filename), filepath.Ext(img.Filename))
+		w, err := archive.Create(fmt.Sprintf("%04d_%s.dcm", i+1, name))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(file); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,38 @@
+package usecases
+
+import "errors"
+
+var (
+	ErrPatientNotFound      = errors.New("patient not found")
+	ErrExaminationNotFound  = errors.New("examination not found")
+	ErrReportNotFound       = errors.New("report not found")
+	ErrReportSigned         = errors.New("signed report cannot be modified")
+	ErrSignerNotAllowed     = errors.New("only active doctors can sign reports")
+	ErrInvalidFHIRResource  = errors.New("invalid FHIR resource")
+	ErrHL7Disabled          = errors.New("HL7 delivery is not configured")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrMergeSamePatient     = errors.New("patient cannot be merged into itself")
+	ErrPatientErased        = errors.New("patient data has been erased")
+	ErrErasureNotAllowed    = errors.New("patient data is under legal hold or retention")
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrOrganizationRequired = errors.New("organization name is required to register a new clinic")
+	ErrAdminRequired        = errors.New("only clinic administrators can add users")
+	ErrRoleRequired         = errors.New("role is required")
+	ErrUserExists           = errors.New("username or email is already taken")
+	ErrUsernameReserved     = errors.New("username is reserved")
+	ErrVersionMismatch      = errors.New("record has been modified since it was read")
+	ErrInvalidImage         = errors.New("file is not a valid JPEG, PNG or TIFF image")
+	ErrPhotoNotFound        = errors.New("photo not found in storage")
+	ErrImageNotFound        = errors.New("image not found")
+	ErrUnknownRendition     = errors.New("unknown image size")
+	ErrPhotoInUse           = errors.New("photo is attached to an examination")
+	ErrExaminationClosed    = errors.New("examination no longer accepts new images")
+	ErrUnknownExamType      = errors.New("unknown examination type")
+	ErrInvalidPosition      = errors.New("invalid finger position")
+	ErrCaptureIncomplete    = errors.New("required finger positions are not captured yet")
+	ErrDeviceNotFound       = errors.New("device not found")
+	ErrDeviceExists         = errors.New("device is already registered")
+	ErrInvalidDeviceSetting = errors.New("brightness must be between 0 and 1 and zoom between 1 and 10")
+	ErrDeviceAnonymous      = errors.New("devices must connect with an access token")
+	ErrUnauthenticated      = errors.New("authentication required")
+)
@@ -2,44 +2,89 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/project-capillary/backend/internal/application/dto"
 	"github.com/project-capillary/backend/internal/domain/entities"
 	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/domain/valueobjects"
+	"github.com/project-capillary/backend/internal/infrastructure/imaging"
 	"github.com/project-capillary/backend/internal/infrastructure/mq"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
 )
 
 type ExaminationUseCase struct {
 	examinationRepo repositories.ExaminationRepository
 	analysisRepo    repositories.AnalysisRepository
 	imageRepo       repositories.ImageRepository
+	captureRepo     repositories.PhotoCaptureRepository
+	patientRepo     repositories.PatientRepository
+	userRepo        repositories.UserRepository
+	txManager       repositories.TransactionManager
 	mqPublisher     *mq.RabbitMQ
+	blobs           storage.BlobStore
 }
 
 func NewExaminationUseCase(
 	examinationRepo repositories.ExaminationRepository,
 	analysisRepo repositories.AnalysisRepository,
 	imageRepo repositories.ImageRepository,
+	captureRepo repositories.PhotoCaptureRepository,
+	patientRepo repositories.PatientRepository,
+	userRepo repositories.UserRepository,
+	txManager repositories.TransactionManager,
 	mqPublisher *mq.RabbitMQ,
+	blobs storage.BlobStore,
 ) *ExaminationUseCase {
 	return &ExaminationUseCase{
 		examinationRepo: examinationRepo,
 		analysisRepo:    analysisRepo,
 		imageRepo:       imageRepo,
+		captureRepo:     captureRepo,
+		patientRepo:     patientRepo,
+		userRepo:        userRepo,
+		txManager:       txManager,
 		mqPublisher:     mqPublisher,
+		blobs:           blobs,
 	}
 }
 
 func (uc *ExaminationUseCase) CreateExamination(ctx context.Context, req dto.CreateExaminationRequest) (*dto.ExaminationResponse, error) {
+	protocol, err := valueobjects.LookupCaptureProtocol(req.ExamType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExamType, req.ExamType)
+	}
+
+	// Foreign keys are checked across organizations, so make sure both
+	// references are visible to the caller.
+	patient, err := uc.patientRepo.GetByID(ctx, req.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, ErrPatientNotFound
+	}
+	doctor, err := uc.userRepo.GetByID(ctx, req.DoctorID)
+	if err != nil {
+		return nil, err
+	}
+	if doctor == nil {
+		return nil, ErrUserNotFound
+	}
+
 	examination := entities.NewExamination(
 		uuid.New().String(),
 		req.PatientID,
 		req.DoctorID,
 		req.Description,
 	)
+	examination.OrganizationID = patient.OrganizationID
+	examination.ExamType = protocol.ExamType
 
-	err := uc.examinationRepo.Create(ctx, examination)
+	err = uc.examinationRepo.Create(ctx, examination)
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +94,7 @@ func (uc *ExaminationUseCase) CreateExamination(ctx context.Context, req dto.Cre
 		PatientID:   examination.PatientID,
 		DoctorID:    examination.DoctorID,
 		Status:      string(examination.Status),
+		ExamType:    examination.ExamType,
 		Description: examination.Description,
 		Images:      examination.Images,
 		CreatedAt:   examination.CreatedAt,
@@ -56,19 +102,26 @@ func (uc *ExaminationUseCase) CreateExamination(ctx context.Context, req dto.Cre
 	}, nil
 }
 
-func (uc *ExaminationUseCase) ListExaminations(ctx context.Context, limit, offset int) ([]*dto.ExaminationResponse, error) {
+func (uc *ExaminationUseCase) ListExaminations(ctx context.Context, pageRequest dto.PageRequest) (*dto.PaginatedResponse, error) {
+	limit, offset, page := pageRequest.Bounds()
 	examinations, err := uc.examinationRepo.List(ctx, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []*dto.ExaminationResponse
+	total, err := uc.examinationRepo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.ExaminationResponse, 0, len(examinations))
 	for _, examination := range examinations {
 		response = append(response, &dto.ExaminationResponse{
 			ID:          examination.ID,
 			PatientID:   examination.PatientID,
 			DoctorID:    examination.DoctorID,
 			Status:      string(examination.Status),
+			ExamType:    examination.ExamType,
 			Description: examination.Description,
 			Images:      examination.Images,
 			CreatedAt:   examination.CreatedAt,
@@ -77,7 +130,7 @@ func (uc *ExaminationUseCase) ListExaminations(ctx context.Context, limit, offse
 		})
 	}
 
-	return response, nil
+	return dto.NewPaginatedResponse(response, total, page, limit), nil
 }
 
 func (uc *ExaminationUseCase) GetExamination(ctx context.Context, id string) (*dto.ExaminationResponse, error) {
@@ -91,6 +144,7 @@ func (uc *ExaminationUseCase) GetExamination(ctx context.Context, id string) (*d
 		PatientID:   examination.PatientID,
 		DoctorID:    examination.DoctorID,
 		Status:      string(examination.Status),
+		ExamType:    examination.ExamType,
 		Description: examination.Description,
 		Images:      examination.Images,
 		CreatedAt:   examination.CreatedAt,
@@ -99,34 +153,54 @@ func (uc *ExaminationUseCase) GetExamination(ctx context.Context, id string) (*d
 	}, nil
 }
 
+// StartAnalysis moves the examination to processing and creates an analysis
+// per image in one transaction. Tasks are published only once it commits, so
+// the worker never picks up an analysis it cannot see. Examinations whose
+// capture protocol is not complete yet are left alone.
 func (uc *ExaminationUseCase) StartAnalysis(ctx context.Context, examinationID string) error {
-	examination, err := uc.examinationRepo.GetByID(ctx, examinationID)
-	if err != nil || examination == nil {
+	if err := requireOrganization(ctx); err != nil {
 		return err
 	}
+	var tasks []*mq.AnalysisTaskMessage
+	err := uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		examination, err := uc.examinationRepo.GetByIDForUpdate(ctx, examinationID)
+		if err != nil || examination == nil {
+			return err
+		}
 
-	images, err := uc.imageRepo.GetByExaminationID(ctx, examinationID)
-	if err != nil {
-		return err
-	}
+		images, err := uc.imageRepo.GetByExaminationID(ctx, examinationID)
+		if err != nil {
+			return err
+		}
+		protocol, err := valueobjects.LookupCaptureProtocol(examination.ExamType)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrUnknownExamType, examination.ExamType)
+		}
+		if missing := protocol.Missing(capturedPositions(images)); len(missing) > 0 {
+			return fmt.Errorf("%w: %s", ErrCaptureIncomplete, describeMissing(missing))
+		}
 
-	examination.StartProcessing()
-	err = uc.examinationRepo.Update(ctx, examination)
+		examination.StartProcessing()
+		if err := uc.examinationRepo.Update(ctx, examination); err != nil {
+			return err
+		}
+
+		for _, image := range images {
+			analysis := entities.NewAnalysis(uuid.New().String(), examinationID, image.ID)
+			if err := uc.analysisRepo.Create(ctx, analysis); err != nil {
+				return err
+			}
+			tasks = append(tasks, mq.NewAnalysisTaskMessage(analysis.ID, examinationID, image.ID, image.FilePath))
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	for _, image := range images {
-		analysis := entities.NewAnalysis(uuid.New().String(), examinationID, image.ID)
-		err = uc.analysisRepo.Create(ctx, analysis)
-		if err != nil {
-			continue
-		}
-
-		taskMsg := mq.NewAnalysisTaskMessage(analysis.ID, examinationID, image.ID, image.FilePath)
+	for _, taskMsg := range tasks {
 		uc.mqPublisher.Publish(ctx, taskMsg)
 	}
-
 	return nil
 }
 
@@ -143,6 +217,7 @@ func (uc *ExaminationUseCase) GetExaminationsByPatient(ctx context.Context, pati
 			PatientID:   e.PatientID,
 			DoctorID:    e.DoctorID,
 			Status:      string(e.Status),
+			ExamType:    e.ExamType,
 			Description: e.Description,
 			Images:      e.Images,
 			CreatedAt:   e.CreatedAt,
@@ -153,31 +228,300 @@ func (uc *ExaminationUseCase) GetExaminationsByPatient(ctx context.Context, pati
 	return response, nil
 }
 
-func (uc *ExaminationUseCase) AttachPhotos(ctx context.Context, examinationID string, photoFilenames []string) error {
+// OpenCaptureSession checks that photos can be captured straight into an
+// examination of the caller's organization: it must exist and not be
+// analyzed yet, and the position is required when its protocol lists
+// positions. It returns the position in normalized form.
+func (uc *ExaminationUseCase) OpenCaptureSession(ctx context.Context, examinationID, position string) (string, error) {
+	if err := requireOrganization(ctx); err != nil {
+		return "", err
+	}
+	position, err := parsePosition(position)
+	if err != nil {
+		return "", err
+	}
 	examination, err := uc.examinationRepo.GetByID(ctx, examinationID)
-	if err != nil || examination == nil {
-		return err
+	if err != nil {
+		return "", err
+	}
+	if err := acceptsImages(examination); err != nil {
+		return "", err
+	}
+	protocol, err := valueobjects.LookupCaptureProtocol(examination.ExamType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrUnknownExamType, examination.ExamType)
+	}
+	if position == "" && protocol.RequiresPositions() {
+		return "", fmt.Errorf("%w: %s examinations need the finger of each photo", ErrInvalidPosition, protocol.ExamType)
+	}
+	return position, nil
+}
+
+// CaptureProgress reports which positions of the examination's capture
+// protocol have enough images.
+func (uc *ExaminationUseCase) CaptureProgress(ctx context.Context, examinationID string) (*dto.CaptureProgressResponse, error) {
+	examination, err := uc.examinationRepo.GetByID(ctx, examinationID)
+	if err != nil {
+		return nil, err
+	}
+	if examination == nil {
+		return nil, ErrExaminationNotFound
 	}
+	protocol, err := valueobjects.LookupCaptureProtocol(examination.ExamType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExamType, examination.ExamType)
+	}
+	images, err := uc.imageRepo.GetByExaminationID(ctx, examinationID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CaptureProgressResponse{
+		ExamType:  protocol.ExamType,
+		Complete:  true,
+		Positions: []dto.PositionProgressResponse{},
+	}
+	for _, progress := range protocol.Progress(capturedPositions(images)) {
+		response.Complete = response.Complete && progress.Complete()
+		response.Positions = append(response.Positions, dto.PositionProgressResponse{
+			Position: progress.Position,
+			Label:    valueobjects.PositionLabel(progress.Position),
+			Required: progress.Required,
+			Captured: progress.Captured,
+		})
+	}
+	return response, nil
+}
 
+// AttachPhotos attaches photos already in storage, such as WebSocket
+// captures, reading each one to record its real metadata. All photos are
+// recorded at position, which may be empty. Attached captures leave the
+// photo inbox.
+func (uc *ExaminationUseCase) AttachPhotos(ctx context.Context, examinationID string, photoFilenames []string, position string) error {
+	if err := requireOrganization(ctx); err != nil {
+		return err
+	}
+	position, err := parsePosition(position)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkAcceptsImages(ctx, examinationID); err != nil {
+		return err
+	}
+	images := make([]*entities.Image, 0, len(photoFilenames))
 	for _, filename := range photoFilenames {
-		image := entities.NewImage(
-			uuid.New().String(),
-			examinationID,
-			filename,
-			"/app/storage/photos/"+filename,
-			"image/jpeg",
-			0,
-			0,
-			0,
-		)
-
-		err = uc.imageRepo.Create(ctx, image)
+		image, err := uc.storedImage(ctx, examinationID, filename)
+		if err != nil {
+			return err
+		}
+		image.Position = position
+		images = append(images, image)
+	}
+
+	return uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.addImages(ctx, examinationID, images); err != nil {
+			return err
+		}
+		for _, image := range images {
+			capture, err := uc.captureRepo.GetUnassignedByFilename(ctx, image.Filename)
+			if err != nil {
+				return err
+			}
+			if capture == nil {
+				continue
+			}
+			capture.Assign(image.ID)
+			if err := uc.captureRepo.Assign(ctx, capture); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// storedImage reads a photo the caller may attach: one in their inbox or
+// already attached to one of their examinations.
+func (uc *ExaminationUseCase) storedImage(ctx context.Context, examinationID, filename string) (*entities.Image, error) {
+	if filename == "" {
+		return nil, fmt.Errorf("%w: %q", ErrPhotoNotFound, filename)
+	}
+	key := storage.PhotoKey(filename)
+	if err := photoVisible(ctx, uc.captureRepo, uc.imageRepo, key); err != nil {
+		return nil, err
+	}
+	data, _, err := storage.ReadVerified(ctx, uc.blobs, key, "")
+	if errors.Is(err, storage.ErrBlobNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return nil, fmt.Errorf("%w: %q", ErrPhotoNotFound, filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := imaging.Inspect(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImage, filename, err)
+	}
+
+	// Renditions missing after a failure here are rendered on first request.
+	_ = storeRenditions(ctx, uc.blobs, key, data)
+
+	image := entities.NewImage(uuid.New().String(), examinationID, key, key, "", 0, 0, 0)
+	image.SHA256 = storage.Checksum(data)
+	image.ApplyMetadata(info.MimeType, info.Metadata(int64(len(data))))
+	return image, nil
+}
+
+// checkAcceptsImages fails unless the examination exists and still
+// accepts images, so that nothing is stored for one that does not. addImages
+// checks again under the row lock.
+func (uc *ExaminationUseCase) checkAcceptsImages(ctx context.Context, examinationID string) error {
+	examination, err := uc.examinationRepo.GetByID(ctx, examinationID)
+	if err != nil {
+		return err
+	}
+	return acceptsImages(examination)
+}
+
+// acceptsImages fails unless the examination is pending: images added
+// later would have no analysis and change the digests of signed reports.
+func acceptsImages(examination *entities.Examination) error {
+	if examination == nil {
+		return ErrExaminationNotFound
+	}
+	if examination.Status != entities.StatusPending {
+		return fmt.Errorf("%w: status is %s", ErrExaminationClosed, examination.Status)
+	}
+	return nil
+}
+
+// addImages records images and attaches them to the examination in one
+// transaction, provided it still accepts images.
+func (uc *ExaminationUseCase) addImages(ctx context.Context, examinationID string, images []*entities.Image) error {
+	return uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		examination, err := uc.examinationRepo.GetByIDForUpdate(ctx, examinationID)
+		if err != nil {
+			return err
+		}
+		if err := acceptsImages(examination); err != nil {
+			return err
+		}
+
+		for _, image := range images {
+			if err := uc.imageRepo.Create(ctx, image); err != nil {
+				return err
+			}
+			examination.AddImage(image.ID)
+		}
+		return uc.examinationRepo.Update(ctx, examination)
+	})
+}
+
+// UploadImages validates and stores uploaded files and attaches them to the
+// examination. Nothing is attached unless every file is a valid image.
+func (uc *ExaminationUseCase) UploadImages(ctx context.Context, examinationID string, uploads []dto.ImageUpload) ([]dto.ImageResponse, error) {
+	infos := make([]*imaging.Info, 0, len(uploads))
+	positions := make([]string, 0, len(uploads))
+	for _, upload := range uploads {
+		if upload.Filename == "" {
+			return nil, fmt.Errorf("%w: file without a name", ErrInvalidImage)
+		}
+		position, err := parsePosition(upload.Position)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, position)
+		info, err := imaging.Inspect(upload.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImage, upload.Filename, err)
+		}
+		infos = append(infos, info)
+	}
+	if err := uc.checkAcceptsImages(ctx, examinationID); err != nil {
+		return nil, err
+	}
+
+	// Blobs are written first; if the transaction fails they are left
+	// unreferenced and reported by storage fsck.
+	images := make([]*entities.Image, 0, len(uploads))
+	for i, upload := range uploads {
+		key, sum, _, err := storage.PutContent(ctx, uc.blobs, upload.Data, infos[i].Ext, infos[i].MimeType)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("store %s: %w", upload.Filename, err)
+		}
+		_ = storeRenditions(ctx, uc.blobs, key, upload.Data)
+		image := entities.NewImage(uuid.New().String(), examinationID, key, key, "", 0, 0, 0)
+		image.SHA256 = sum
+		image.Position = positions[i]
+		image.ApplyMetadata(infos[i].MimeType, infos[i].Metadata(int64(len(upload.Data))))
+		images = append(images, image)
+	}
+
+	if err := uc.addImages(ctx, examinationID, images); err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ImageResponse, 0, len(images))
+	for _, image := range images {
+		response = append(response, toImageResponse(image))
+	}
+	return response, nil
+}
+
+func toImageResponse(image *entities.Image) dto.ImageResponse {
+	response := dto.ImageResponse{
+		ID:            image.ID,
+		ExaminationID: image.ExaminationID,
+		Filename:      image.Filename,
+		FilePath:      image.FilePath,
+		FileSize:      image.FileSize,
+		MimeType:      image.MimeType,
+		Width:         image.Width,
+		Height:        image.Height,
+		Position:      image.Position,
+		SHA256:        image.SHA256,
+		CapturedAt:    image.CapturedAt,
+		CreatedAt:     image.CreatedAt,
+	}
+	if m := image.Metadata; m != nil {
+		response.Metadata = &dto.ImageMetadataResponse{
+			Format:       m.Format,
+			ColorSpace:   m.ColorSpace,
+			DPI:          m.DPI,
+			TakenAt:      m.TakenAt,
+			CameraMake:   m.CameraMake,
+			CameraModel:  m.CameraModel,
+			ExposureTime: m.ExposureTime,
+			ISO:          m.ISO,
 		}
+	}
+	return response
+}
 
-		examination.AddImage(image.ID)
+// parsePosition normalizes an optional finger position.
+func parsePosition(position string) (string, error) {
+	if position == "" {
+		return "", nil
 	}
+	parsed, err := valueobjects.ParsePosition(position)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPosition, err)
+	}
+	return parsed, nil
+}
 
-	return uc.examinationRepo.Update(ctx, examination)
+func capturedPositions(images []*entities.Image) map[string]int {
+	captured := make(map[string]int)
+	for _, image := range images {
+		if image.Position != "" {
+			captured[image.Position]++
+		}
+	}
+	return captured
+}
+
+func describeMissing(missing []valueobjects.PositionProgress) string {
+	parts := make([]string, 0, len(missing))
+	for _, progress := range missing {
+		parts = append(parts, fmt.Sprintf("%s %d of %d", progress.Position, progress.Captured, progress.Required))
+	}
+	return strings.Join(parts, ", ")
 }
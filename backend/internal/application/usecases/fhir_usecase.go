@@ -0,0 +1,263 @@
+package usecases
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+	"github.com/project-capillary/backend/internal/infrastructure/fhir"
+)
+
+const maxImportLineSize = 1024 * 1024
+
+type FHIRUseCase struct {
+	patientRepo     repositories.PatientRepository
+	examinationRepo repositories.ExaminationRepository
+	imageRepo       repositories.ImageRepository
+	analysisRepo    repositories.AnalysisRepository
+	reportRepo      repositories.ReportRepository
+}
+
+func NewFHIRUseCase(
+	patientRepo repositories.PatientRepository,
+	examinationRepo repositories.ExaminationRepository,
+	imageRepo repositories.ImageRepository,
+	analysisRepo repositories.AnalysisRepository,
+	reportRepo repositories.ReportRepository,
+) *FHIRUseCase {
+	return &FHIRUseCase{
+		patientRepo:     patientRepo,
+		examinationRepo: examinationRepo,
+		imageRepo:       imageRepo,
+		analysisRepo:    analysisRepo,
+		reportRepo:      reportRepo,
+	}
+}
+
+func (uc *FHIRUseCase) GetPatient(ctx context.Context, id string) (*fhir.Patient, error) {
+	patient, err := uc.patientRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, ErrPatientNotFound
+	}
+	return fhir.PatientResource(patient), nil
+}
+
+func (uc *FHIRUseCase) GetDiagnosticReport(ctx context.Context, id string) (*fhir.DiagnosticReport, error) {
+	report, err := uc.reportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+
+	examination, err := uc.getExamination(ctx, report.ExaminationID)
+	if err != nil {
+		return nil, err
+	}
+
+	analyses, err := uc.analysisRepo.GetByExaminationID(ctx, examination.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	observations := fhir.ObservationResources(examination, analyses)
+	return fhir.DiagnosticReportResource(report, examination, observations), nil
+}
+
+func (uc *FHIRUseCase) ExportExamination(ctx context.Context, examinationID, baseURL string) (*fhir.Bundle, error) {
+	examination, err := uc.getExamination(ctx, examinationID)
+	if err != nil {
+		return nil, err
+	}
+
+	patient, err := uc.patientRepo.GetByID(ctx, examination.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, ErrPatientNotFound
+	}
+
+	images, err := uc.imageRepo.GetByExaminationID(ctx, examination.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	analyses, err := uc.analysisRepo.GetByExaminationID(ctx, examination.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := uc.reportRepo.GetByExaminationID(ctx, examination.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	observations := fhir.ObservationResources(examination, analyses)
+
+	bundle := fhir.NewBundle(examination.ID, time.Now())
+	bundle.Add(baseURL, "Patient", patient.ID, fhir.PatientResource(patient))
+	bundle.Add(baseURL, "Procedure", examination.ID, fhir.ProcedureResource(examination))
+	bundle.Add(baseURL, "ImagingStudy", examination.ID, fhir.ImagingStudyResource(examination, images))
+	for _, observation := range observations {
+		bundle.Add(baseURL, "Observation", observation.ID, observation)
+	}
+	if report != nil {
+		bundle.Add(baseURL, "DiagnosticReport", report.ID, fhir.DiagnosticReportResource(report, examination, observations))
+	}
+
+	return bundle, nil
+}
+
+// UpsertPatient matches the resource on our own patient identifier first and
+// on the external identifier second, updating the match or creating a new
+// patient. The returned flag reports whether a patient was created.
+func (uc *FHIRUseCase) UpsertPatient(ctx context.Context, data []byte) (*fhir.Patient, bool, error) {
+	resource, err := fhir.ParsePatient(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidFHIRResource, err)
+	}
+
+	patient, created, err := uc.upsertPatient(ctx, resource)
+	if err != nil {
+		return nil, false, err
+	}
+	return fhir.PatientResource(patient), created, nil
+}
+
+// ImportPatients upserts newline-delimited FHIR Patient resources. A bad
+// record is reported and skipped; only read failures abort the import.
+func (uc *FHIRUseCase) ImportPatients(ctx context.Context, reader io.Reader) (*dto.PatientImportResult, error) {
+	result := &dto.PatientImportResult{Records: []dto.PatientImportRecord{}}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		result.Total++
+		record := dto.PatientImportRecord{Line: line}
+
+		resource, err := fhir.ParsePatient(data)
+		if err != nil {
+			record.Status = "failed"
+			record.Error = fmt.Sprintf("%v: %v", ErrInvalidFHIRResource, err)
+			result.Failed++
+			result.Records = append(result.Records, record)
+			continue
+		}
+
+		patient, created, err := uc.upsertPatient(ctx, resource)
+		switch {
+		case err != nil:
+			record.Status = "failed"
+			record.Error = err.Error()
+			result.Failed++
+		case created:
+			record.Status = "created"
+			record.PatientID = patient.ID
+			result.Created++
+		default:
+			record.Status = "updated"
+			record.PatientID = patient.ID
+			result.Updated++
+		}
+		result.Records = append(result.Records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read import at line %d: %w", line+1, err)
+	}
+	return result, nil
+}
+
+func (uc *FHIRUseCase) upsertPatient(ctx context.Context, resource *fhir.Patient) (*entities.Patient, bool, error) {
+	demographics, err := fhir.PatientDemographicsFrom(resource)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidFHIRResource, err)
+	}
+
+	var patient *entities.Patient
+	if demographics.ID != "" {
+		if _, parseErr := uuid.Parse(demographics.ID); parseErr == nil {
+			patient, err = uc.patientRepo.GetByID(ctx, demographics.ID)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if patient == nil && demographics.ExternalID != "" {
+		patient, err = uc.patientRepo.GetByExternalID(ctx, demographics.ExternalSystem, demographics.ExternalID)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if patient == nil {
+		if demographics.ExternalID == "" {
+			return nil, false, ErrPatientNotFound
+		}
+
+		patient = entities.NewPatient(
+			uuid.New().String(),
+			demographics.FirstName,
+			demographics.LastName,
+			demographics.MiddleName,
+			demographics.DateOfBirth,
+			demographics.Gender,
+			demographics.Phone,
+			demographics.Email,
+		)
+		patient.OrganizationID = auth.OrganizationID(ctx)
+		patient.SetExternalIdentifier(demographics.ExternalSystem, demographics.ExternalID)
+		if err := uc.patientRepo.Create(ctx, patient); err != nil {
+			return nil, false, err
+		}
+		return patient, true, nil
+	}
+
+	// A resource without telecom leaves the contacts alone; one with telecom
+	// replaces them, clearing a phone or email it does not list.
+	phone, email := patient.Phone, patient.Email
+	if demographics.HasTelecom {
+		phone, email = demographics.Phone, demographics.Email
+	}
+	patient.Update(demographics.FirstName, demographics.LastName, demographics.MiddleName, phone, email)
+	patient.UpdateDemographics(demographics.DateOfBirth, demographics.Gender)
+	if demographics.ExternalID != "" {
+		patient.SetExternalIdentifier(demographics.ExternalSystem, demographics.ExternalID)
+	}
+	if err := uc.patientRepo.Update(ctx, patient); err != nil {
+		return nil, false, err
+	}
+	return patient, false, nil
+}
+
+func (uc *FHIRUseCase) getExamination(ctx context.Context, id string) (*entities.Examination, error) {
+	examination, err := uc.examinationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if examination == nil {
+		return nil, ErrExaminationNotFound
+	}
+	return examination, nil
+}
@@ -0,0 +1,223 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/hl7"
+)
+
+// HL7 v2.5.1 limits MSH-10 to 20 characters.
+const maxControlIDLength = 20
+
+type HL7UseCase struct {
+	reportRepo      repositories.ReportRepository
+	examinationRepo repositories.ExaminationRepository
+	patientRepo     repositories.PatientRepository
+	analysisRepo    repositories.AnalysisRepository
+	messageRepo     repositories.HL7MessageRepository
+	client          *hl7.Client
+	header          hl7.Header
+	maxAttempts     int
+	retryDelay      time.Duration
+}
+
+// NewHL7UseCase creates the result delivery use case. A nil client disables
+// delivery; messages are then neither built nor recorded.
+func NewHL7UseCase(
+	reportRepo repositories.ReportRepository,
+	examinationRepo repositories.ExaminationRepository,
+	patientRepo repositories.PatientRepository,
+	analysisRepo repositories.AnalysisRepository,
+	messageRepo repositories.HL7MessageRepository,
+	client *hl7.Client,
+	header hl7.Header,
+	maxAttempts int,
+	retryDelay time.Duration,
+) *HL7UseCase {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &HL7UseCase{
+		reportRepo:      reportRepo,
+		examinationRepo: examinationRepo,
+		patientRepo:     patientRepo,
+		analysisRepo:    analysisRepo,
+		messageRepo:     messageRepo,
+		client:          client,
+		header:          header,
+		maxAttempts:     maxAttempts,
+		retryDelay:      retryDelay,
+	}
+}
+
+func (uc *HL7UseCase) Enabled() bool {
+	return uc.client != nil
+}
+
+// SendReport builds an ORU^R01 message for the report and delivers it over
+// MLLP, retrying transport failures and AE/CE acknowledgments with a linear
+// backoff. The outcome of every attempt is recorded on the message.
+func (uc *HL7UseCase) SendReport(ctx context.Context, reportID string) (*dto.HL7MessageResponse, error) {
+	message, err := uc.record(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.deliver(ctx, message); err != nil {
+		return nil, err
+	}
+	return toHL7MessageResponse(message), nil
+}
+
+// QueueReport records an ORU^R01 message for the report and delivers it in
+// the background like SendReport, so the caller does not wait out the
+// retries. The returned message is still pending; its outcome is recorded
+// as with SendReport.
+func (uc *HL7UseCase) QueueReport(ctx context.Context, reportID string) (*dto.HL7MessageResponse, error) {
+	message, err := uc.record(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	response := toHL7MessageResponse(message)
+
+	go func() {
+		if err := uc.deliver(context.Background(), message); err != nil {
+			log.Printf("Failed to record HL7 delivery of message %s: %v", message.ControlID, err)
+		}
+	}()
+	return response, nil
+}
+
+// record builds the ORU^R01 message for a report and stores it as pending.
+func (uc *HL7UseCase) record(ctx context.Context, reportID string) (*entities.HL7Message, error) {
+	if !uc.Enabled() {
+		return nil, ErrHL7Disabled
+	}
+
+	report, err := uc.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+
+	examination, err := uc.examinationRepo.GetByID(ctx, report.ExaminationID)
+	if err != nil {
+		return nil, err
+	}
+	if examination == nil {
+		return nil, ErrExaminationNotFound
+	}
+
+	patient, err := uc.patientRepo.GetByID(ctx, examination.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, ErrPatientNotFound
+	}
+
+	analyses, err := uc.analysisRepo.GetByExaminationID(ctx, examination.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	controlID := strings.ReplaceAll(uuid.New().String(), "-", "")[:maxControlIDLength]
+	oru := hl7.NewORU(uc.header, controlID, time.Now(), patient, examination, report, analyses)
+
+	message := entities.NewHL7Message(
+		uuid.New().String(),
+		report.ID,
+		controlID,
+		hl7.MessageTypeORU,
+		uc.client.Address(),
+		oru.String(),
+	)
+	if err := uc.messageRepo.Create(ctx, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (uc *HL7UseCase) ListReportMessages(ctx context.Context, reportID string) ([]*dto.HL7MessageResponse, error) {
+	report, err := uc.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+
+	messages, err := uc.messageRepo.ListByReportID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.HL7MessageResponse, len(messages))
+	for i, message := range messages {
+		responses[i] = toHL7MessageResponse(message)
+	}
+	return responses, nil
+}
+
+func (uc *HL7UseCase) deliver(ctx context.Context, message *entities.HL7Message) error {
+	for attempt := 1; attempt <= uc.maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				message.GiveUp()
+				return uc.messageRepo.Update(ctx, message)
+			case <-time.After(uc.retryDelay * time.Duration(attempt-1)):
+			}
+		}
+
+		ack, err := uc.client.Send(ctx, []byte(message.Payload))
+		switch {
+		case err != nil:
+			message.RecordFailure("", err.Error())
+		case ack.ControlID != message.ControlID:
+			message.RecordFailure(ack.Code, fmt.Sprintf("acknowledgment references control ID %q", ack.ControlID))
+		case ack.Retryable():
+			message.RecordFailure(ack.Code, ack.Text)
+		default:
+			message.Acknowledge(ack.Code, ack.Text, ack.Accepted())
+		}
+
+		if err := uc.messageRepo.Update(ctx, message); err != nil {
+			return err
+		}
+		if message.Status != entities.HL7MessagePending {
+			return nil
+		}
+	}
+
+	message.GiveUp()
+	return uc.messageRepo.Update(ctx, message)
+}
+
+func toHL7MessageResponse(message *entities.HL7Message) *dto.HL7MessageResponse {
+	return &dto.HL7MessageResponse{
+		ID:             message.ID,
+		ReportID:       message.ReportID,
+		ControlID:      message.ControlID,
+		MessageType:    message.MessageType,
+		Destination:    message.Destination,
+		Status:         string(message.Status),
+		Attempts:       message.Attempts,
+		AckCode:        message.AckCode,
+		AckMessage:     message.AckMessage,
+		LastError:      message.LastError,
+		Payload:        message.Payload,
+		CreatedAt:      message.CreatedAt,
+		UpdatedAt:      message.UpdatedAt,
+		AcknowledgedAt: message.AcknowledgedAt,
+	}
+}
@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+	"github.com/project-capillary/backend/internal/infrastructure/imaging"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
+)
+
+type ImageUseCase struct {
+	imageRepo       repositories.ImageRepository
+	examinationRepo repositories.ExaminationRepository
+	blobs           storage.BlobStore
+}
+
+func NewImageUseCase(
+	imageRepo repositories.ImageRepository,
+	examinationRepo repositories.ExaminationRepository,
+	blobs storage.BlobStore,
+) *ImageUseCase {
+	return &ImageUseCase{
+		imageRepo:       imageRepo,
+		examinationRepo: examinationRepo,
+		blobs:           blobs,
+	}
+}
+
+// GetImage returns the stored file of an image, verified against its
+// checksum.
+func (uc *ImageUseCase) GetImage(ctx context.Context, imageID string) (*dto.ImageContent, error) {
+	image, err := uc.image(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := storage.PhotoKey(image.Filename)
+	data, info, err := storage.ReadVerified(ctx, uc.blobs, key, image.SHA256)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrPhotoNotFound, image.Filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := image.MimeType
+	if contentType == "" {
+		contentType = info.ContentType
+	}
+	etag := image.SHA256
+	if etag == "" {
+		etag = storage.Checksum(data)
+	}
+	return &dto.ImageContent{
+		Name:        key,
+		ContentType: contentType,
+		ETag:        etag,
+		Data:        data,
+	}, nil
+}
+
+// GetRendition returns the named rendition of an image, rendering it from
+// the original when it is not cached yet.
+func (uc *ImageUseCase) GetRendition(ctx context.Context, imageID, name string) (*dto.ImageContent, error) {
+	rendition, ok := imaging.LookupRendition(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRendition, name)
+	}
+	image, err := uc.image(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := storage.PhotoKey(image.Filename)
+	data, err := uc.blobs.Get(ctx, storage.RenditionKey(rendition.Name, key))
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		original, _, readErr := storage.ReadVerified(ctx, uc.blobs, key, image.SHA256)
+		if errors.Is(readErr, storage.ErrBlobNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrPhotoNotFound, image.Filename)
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+		data, err = storeRendition(ctx, uc.blobs, key, original, rendition)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ImageContent{
+		Name:        rendition.Name + ".jpg",
+		ContentType: "image/jpeg",
+		ETag:        storage.Checksum(data),
+		Data:        data,
+	}, nil
+}
+
+// image loads an image the caller may see: both the image and the
+// examination it belongs to must be visible in the caller's organization.
+// Requests with a signed URL carry no organization and see any image.
+func (uc *ImageUseCase) image(ctx context.Context, imageID string) (*entities.Image, error) {
+	image, err := uc.imageRepo.GetByID(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+	if image == nil {
+		return nil, ErrImageNotFound
+	}
+	examination, err := uc.examinationRepo.GetByID(ctx, image.ExaminationID)
+	if err != nil {
+		return nil, err
+	}
+	if examination == nil {
+		return nil, ErrImageNotFound
+	}
+	return image, nil
+}
+
+// imageInfo links to an image and its thumbnail with URLs signed by urls.
+func imageInfo(urls *auth.URLSigner, image *entities.Image) dto.ImageInfo {
+	return dto.ImageInfo{
+		ID:           image.ID,
+		Filename:     image.Filename,
+		URL:          urls.Sign("/api/images/" + image.ID + "/file"),
+		ThumbnailURL: urls.Sign("/api/images/" + image.ID + "/thumbnail?size=" + imaging.Thumbnail.Name),
+	}
+}
+
+// storeRenditions renders and caches every rendition of a photo that is not
+// cached yet, so lists can show it without rendering on first view.
+func storeRenditions(ctx context.Context, blobs storage.BlobStore, key string, data []byte) error {
+	for _, rendition := range imaging.Renditions {
+		_, err := blobs.Stat(ctx, storage.RenditionKey(rendition.Name, key))
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, storage.ErrBlobNotFound) {
+			return err
+		}
+		if _, err := storeRendition(ctx, blobs, key, data, rendition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func storeRendition(ctx context.Context, blobs storage.BlobStore, key string, data []byte, rendition imaging.Rendition) ([]byte, error) {
+	rendered, err := imaging.Render(data, rendition)
+	if err != nil {
+		return nil, fmt.Errorf("render %s of %s: %w", rendition.Name, key, err)
+	}
+	err = blobs.Put(ctx, storage.RenditionKey(rendition.Name, key), bytes.NewReader(rendered), int64(len(rendered)), "image/jpeg")
+	if err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
+
+// deleteRenditions removes the cached renditions of a photo.
+func deleteRenditions(ctx context.Context, blobs storage.BlobStore, key string) error {
+	for _, rendition := range imaging.Renditions {
+		if err := blobs.Delete(ctx, storage.RenditionKey(rendition.Name, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
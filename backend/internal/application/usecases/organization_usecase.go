@@ -0,0 +1,65 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+)
+
+type OrganizationUseCase struct {
+	organizationRepo repositories.OrganizationRepository
+}
+
+func NewOrganizationUseCase(organizationRepo repositories.OrganizationRepository) *OrganizationUseCase {
+	return &OrganizationUseCase{organizationRepo: organizationRepo}
+}
+
+// GetCurrent returns the caller's clinic.
+func (uc *OrganizationUseCase) GetCurrent(ctx context.Context) (*dto.OrganizationResponse, error) {
+	organization, err := uc.getCurrent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toOrganizationResponse(organization), nil
+}
+
+func (uc *OrganizationUseCase) UpdateCurrent(ctx context.Context, req dto.UpdateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	organization, err := uc.getCurrent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	organization.Rename(strings.TrimSpace(req.Name))
+	if err := uc.organizationRepo.Update(ctx, organization); err != nil {
+		return nil, err
+	}
+	return toOrganizationResponse(organization), nil
+}
+
+func (uc *OrganizationUseCase) getCurrent(ctx context.Context) (*entities.Organization, error) {
+	id := auth.OrganizationID(ctx)
+	if id == "" {
+		return nil, ErrOrganizationNotFound
+	}
+	organization, err := uc.organizationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if organization == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	return organization, nil
+}
+
+func toOrganizationResponse(organization *entities.Organization) *dto.OrganizationResponse {
+	return &dto.OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		CreatedAt: organization.CreatedAt,
+		UpdatedAt: organization.UpdatedAt,
+	}
+}
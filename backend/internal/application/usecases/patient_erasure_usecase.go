@@ -0,0 +1,188 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
+)
+
+type PatientErasureUseCase struct {
+	patientRepo     repositories.PatientRepository
+	examinationRepo repositories.ExaminationRepository
+	imageRepo       repositories.ImageRepository
+	captureRepo     repositories.PhotoCaptureRepository
+	erasureRepo     repositories.PatientErasureRepository
+	userRepo        repositories.UserRepository
+	blobs           storage.BlobStore
+	retentionYears  int
+}
+
+func NewPatientErasureUseCase(
+	patientRepo repositories.PatientRepository,
+	examinationRepo repositories.ExaminationRepository,
+	imageRepo repositories.ImageRepository,
+	captureRepo repositories.PhotoCaptureRepository,
+	erasureRepo repositories.PatientErasureRepository,
+	userRepo repositories.UserRepository,
+	blobs storage.BlobStore,
+	retentionYears int,
+) *PatientErasureUseCase {
+	return &PatientErasureUseCase{
+		patientRepo:     patientRepo,
+		examinationRepo: examinationRepo,
+		imageRepo:       imageRepo,
+		captureRepo:     captureRepo,
+		erasureRepo:     erasureRepo,
+		userRepo:        userRepo,
+		blobs:           blobs,
+		retentionYears:  retentionYears,
+	}
+}
+
+// ErasePatient removes a patient's personal data once no legal hold or
+// retention period applies. Photo files are deleted before the database is
+// changed: a failed transaction leaves rows pointing at missing files, and a
+// retry skips files that are already gone. The erasure is recorded as
+// requested by the caller.
+func (uc *PatientErasureUseCase) ErasePatient(ctx context.Context, id string, req dto.ErasePatientRequest) (*dto.PatientErasureResponse, error) {
+	claims := auth.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, ErrUnauthenticated
+	}
+	patient, err := uc.patientRepo.GetByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, ErrPatientNotFound
+	}
+	if patient.IsErased() {
+		return nil, ErrPatientErased
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, ErrUserNotFound
+	}
+
+	examinations, err := uc.examinationRepo.GetByPatientID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastActivity time.Time
+	var images []*entities.Image
+	var captures []*entities.PhotoCapture
+	for _, examination := range examinations {
+		if examination.CreatedAt.After(lastActivity) {
+			lastActivity = examination.CreatedAt
+		}
+		examinationImages, err := uc.imageRepo.GetByExaminationID(ctx, examination.ID)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, examinationImages...)
+		examinationCaptures, err := uc.captureRepo.ListByExaminationID(ctx, examination.ID)
+		if err != nil {
+			return nil, err
+		}
+		captures = append(captures, examinationCaptures...)
+	}
+
+	if erasableAt := patient.ErasableAt(lastActivity, uc.retentionYears); time.Now().Before(erasableAt) {
+		return nil, fmt.Errorf("%w until %s", ErrErasureNotAllowed, erasableAt.Format("2006-01-02"))
+	}
+
+	mode := entities.ErasureMode(req.Mode)
+	if mode == "" {
+		mode = entities.ErasureModeAnonymize
+	}
+	erasure := entities.NewPatientErasure(uuid.New().String(), patient.ID, user.ID, req.Reason, mode)
+	erasure.ExaminationCount = len(examinations)
+	erasure.ImageCount = len(images)
+
+	// Identical photos share one blob, also across organizations; keep
+	// those still referred to by anything but this patient's images and
+	// their captures.
+	references := make(map[string]int64)
+	for _, image := range images {
+		if image.Filename != "" {
+			references[image.Filename]++
+		}
+	}
+	for _, capture := range captures {
+		if capture.Filename != "" {
+			references[capture.Filename]++
+		}
+	}
+	for filename, own := range references {
+		total, err := uc.imageRepo.CountReferences(ctx, filename)
+		if err != nil {
+			return nil, err
+		}
+		if total > own {
+			continue
+		}
+
+		key := storage.PhotoKey(filename)
+		if err := deleteRenditions(ctx, uc.blobs, key); err != nil {
+			return nil, fmt.Errorf("delete renditions of %s: %w", filename, err)
+		}
+		_, err = uc.blobs.Stat(ctx, key)
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			continue
+		}
+		if err == nil {
+			err = uc.blobs.Delete(ctx, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("delete photo %s: %w", filename, err)
+		}
+		erasure.FilesDeleted++
+	}
+
+	patient.Anonymize()
+	if err := uc.erasureRepo.Erase(ctx, erasure, patient); err != nil {
+		return nil, err
+	}
+
+	return toPatientErasureResponse(erasure), nil
+}
+
+func (uc *PatientErasureUseCase) ListErasures(ctx context.Context, patientID string) ([]*dto.PatientErasureResponse, error) {
+	erasures, err := uc.erasureRepo.ListByPatientID(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.PatientErasureResponse, 0, len(erasures))
+	for _, erasure := range erasures {
+		response = append(response, toPatientErasureResponse(erasure))
+	}
+	return response, nil
+}
+
+func toPatientErasureResponse(erasure *entities.PatientErasure) *dto.PatientErasureResponse {
+	return &dto.PatientErasureResponse{
+		ID:               erasure.ID,
+		PatientID:        erasure.PatientID,
+		RequestedBy:      erasure.RequestedBy,
+		Reason:           erasure.Reason,
+		Mode:             string(erasure.Mode),
+		ExaminationCount: erasure.ExaminationCount,
+		ImageCount:       erasure.ImageCount,
+		FilesDeleted:     erasure.FilesDeleted,
+		CreatedAt:        erasure.CreatedAt,
+	}
+}
@@ -2,19 +2,39 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/project-capillary/backend/internal/application/dto"
 	"github.com/project-capillary/backend/internal/domain/entities"
 	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
 )
 
+// maxDuplicateCandidates bounds the possible duplicates reported on create.
+const maxDuplicateCandidates = 5
+
 type PatientUseCase struct {
 	patientRepo repositories.PatientRepository
+	mergeRepo   repositories.PatientMergeRepository
+	userRepo    repositories.UserRepository
+	txManager   repositories.TransactionManager
 }
 
-func NewPatientUseCase(patientRepo repositories.PatientRepository) *PatientUseCase {
-	return &PatientUseCase{patientRepo: patientRepo}
+func NewPatientUseCase(
+	patientRepo repositories.PatientRepository,
+	mergeRepo repositories.PatientMergeRepository,
+	userRepo repositories.UserRepository,
+	txManager repositories.TransactionManager,
+) *PatientUseCase {
+	return &PatientUseCase{
+		patientRepo: patientRepo,
+		mergeRepo:   mergeRepo,
+		userRepo:    userRepo,
+		txManager:   txManager,
+	}
 }
 
 func (uc *PatientUseCase) CreatePatient(ctx context.Context, req dto.CreatePatientRequest) (*dto.PatientResponse, error) {
@@ -28,24 +48,24 @@ func (uc *PatientUseCase) CreatePatient(ctx context.Context, req dto.CreatePatie
 		req.Phone,
 		req.Email,
 	)
+	patient.OrganizationID = auth.OrganizationID(ctx)
+	if req.ExternalID != "" {
+		patient.SetExternalIdentifier(req.ExternalSystem, req.ExternalID)
+	}
 
 	err := uc.patientRepo.Create(ctx, patient)
 	if err != nil {
 		return nil, err
 	}
 
-	return &dto.PatientResponse{
-		ID:          patient.ID,
-		FirstName:   patient.FirstName,
-		LastName:    patient.LastName,
-		MiddleName:  patient.MiddleName,
-		DateOfBirth: patient.DateOfBirth,
-		Gender:      patient.Gender,
-		Phone:       patient.Phone,
-		Email:       patient.Email,
-		CreatedAt:   patient.CreatedAt,
-		UpdatedAt:   patient.UpdatedAt,
-	}, nil
+	duplicates, err := uc.findDuplicates(ctx, patient)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toPatientResponse(patient)
+	response.PossibleDuplicates = duplicates
+	return response, nil
 }
 
 func (uc *PatientUseCase) GetPatient(ctx context.Context, id string) (*dto.PatientResponse, error) {
@@ -54,54 +74,304 @@ func (uc *PatientUseCase) GetPatient(ctx context.Context, id string) (*dto.Patie
 		return nil, err
 	}
 
-	return &dto.PatientResponse{
-		ID:          patient.ID,
-		FirstName:   patient.FirstName,
-		LastName:    patient.LastName,
-		MiddleName:  patient.MiddleName,
-		DateOfBirth: patient.DateOfBirth,
-		Gender:      patient.Gender,
-		Phone:       patient.Phone,
-		Email:       patient.Email,
-		CreatedAt:   patient.CreatedAt,
-		UpdatedAt:   patient.UpdatedAt,
-	}, nil
-}
-
-func (uc *PatientUseCase) UpdatePatient(ctx context.Context, id string, req dto.UpdatePatientRequest) error {
+	return toPatientResponse(patient), nil
+}
+
+// UpdatePatient applies req if the patient is still at version, which the
+// client read it at; 0 skips the check.
+func (uc *PatientUseCase) UpdatePatient(ctx context.Context, id string, version int, req dto.UpdatePatientRequest) (*dto.PatientResponse, error) {
 	patient, err := uc.patientRepo.GetByID(ctx, id)
-	if err != nil || patient == nil {
-		return err
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, ErrPatientNotFound
+	}
+	if version != 0 && patient.Version != version {
+		return nil, ErrVersionMismatch
 	}
 
 	patient.Update(req.FirstName, req.LastName, req.MiddleName, req.Phone, req.Email)
-	return uc.patientRepo.Update(ctx, patient)
+	err = uc.patientRepo.Update(ctx, patient)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return nil, ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPatientResponse(patient), nil
 }
 
+// DeletePatient moves the patient to the recycle bin; nothing is removed
+// until the patient is erased through PatientErasureUseCase.
 func (uc *PatientUseCase) DeletePatient(ctx context.Context, id string) error {
-	return uc.patientRepo.Delete(ctx, id)
+	patient, err := uc.patientRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if patient == nil {
+		return ErrPatientNotFound
+	}
+
+	patient.SoftDelete()
+	return uc.patientRepo.Update(ctx, patient)
+}
+
+func (uc *PatientUseCase) RestorePatient(ctx context.Context, id string) (*dto.PatientResponse, error) {
+	patient, err := uc.getIncludingDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if patient.IsDeleted() {
+		patient.Restore()
+		if err := uc.patientRepo.Update(ctx, patient); err != nil {
+			return nil, err
+		}
+	}
+	return toPatientResponse(patient), nil
+}
+
+func (uc *PatientUseCase) PlaceLegalHold(ctx context.Context, id string, req dto.LegalHoldRequest) (*dto.PatientResponse, error) {
+	patient, err := uc.getIncludingDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	patient.PlaceLegalHold(req.Until, req.Reason)
+	if err := uc.patientRepo.Update(ctx, patient); err != nil {
+		return nil, err
+	}
+	return toPatientResponse(patient), nil
+}
+
+func (uc *PatientUseCase) ReleaseLegalHold(ctx context.Context, id string) (*dto.PatientResponse, error) {
+	patient, err := uc.getIncludingDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	patient.ReleaseLegalHold()
+	if err := uc.patientRepo.Update(ctx, patient); err != nil {
+		return nil, err
+	}
+	return toPatientResponse(patient), nil
+}
+
+// getIncludingDeleted loads a patient that may be in the recycle bin, but
+// not one whose data has already been erased.
+func (uc *PatientUseCase) getIncludingDeleted(ctx context.Context, id string) (*entities.Patient, error) {
+	patient, err := uc.patientRepo.GetByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil {
+		return nil, ErrPatientNotFound
+	}
+	if patient.IsErased() {
+		return nil, ErrPatientErased
+	}
+	return patient, nil
 }
 
-func (uc *PatientUseCase) ListPatients(ctx context.Context, limit, offset int) ([]*dto.PatientResponse, error) {
-	patients, err := uc.patientRepo.List(ctx, limit, offset)
+func (uc *PatientUseCase) ListPatients(ctx context.Context, query dto.PatientListQuery) (*dto.PaginatedResponse, error) {
+	limit, offset, page := query.Bounds()
+	filter := repositories.PatientFilter{
+		Query:    query.Query,
+		Gender:   query.Gender,
+		SortBy:   strings.TrimPrefix(query.Sort, "-"),
+		SortDesc: query.Sort == "" || strings.HasPrefix(query.Sort, "-"),
+		Deleted:  query.Deleted,
+		Limit:    limit,
+		Offset:   offset,
+	}
+	if query.Sort == "" && strings.TrimSpace(query.Query) != "" {
+		filter.SortBy = repositories.PatientSortRelevance
+	}
+	if dob, ok := parseSearchDate(query.Query); ok {
+		filter.QueryDate = &dob
+	}
+	filter.DateOfBirthFrom, filter.DateOfBirthTo = birthDateRange(time.Now(), query.MinAge, query.MaxAge)
+
+	patients, err := uc.patientRepo.Search(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []*dto.PatientResponse
+	total, err := uc.patientRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.PatientResponse, 0, len(patients))
 	for _, p := range patients {
-		response = append(response, &dto.PatientResponse{
-			ID:          p.ID,
-			FirstName:   p.FirstName,
-			LastName:    p.LastName,
-			MiddleName:  p.MiddleName,
-			DateOfBirth: p.DateOfBirth,
-			Gender:      p.Gender,
-			Phone:       p.Phone,
-			Email:       p.Email,
-			CreatedAt:   p.CreatedAt,
-			UpdatedAt:   p.UpdatedAt,
+		response = append(response, toPatientResponse(p))
+	}
+	return dto.NewPaginatedResponse(response, total, page, limit), nil
+}
+
+var searchDateLayouts = []string{"2006-01-02", "02.01.2006"}
+
+func parseSearchDate(query string) (time.Time, bool) {
+	query = strings.TrimSpace(query)
+	for _, layout := range searchDateLayouts {
+		if t, err := time.Parse(layout, query); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// birthDateRange converts an inclusive age range into inclusive date of birth
+// bounds relative to now.
+func birthDateRange(now time.Time, minAge, maxAge *int) (from, to *time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if minAge != nil {
+		t := today.AddDate(-*minAge, 0, 0)
+		to = &t
+	}
+	if maxAge != nil {
+		t := today.AddDate(-*maxAge-1, 0, 1)
+		from = &t
+	}
+	return from, to
+}
+
+func (uc *PatientUseCase) ListDuplicates(ctx context.Context, pageRequest dto.PageRequest) ([]dto.PatientDuplicatePair, error) {
+	limit, offset, _ := pageRequest.Bounds()
+	pairs, err := uc.patientRepo.ListDuplicatePairs(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.PatientDuplicatePair, 0, len(pairs))
+	for _, pair := range pairs {
+		match := entities.MatchPatients(pair.First, pair.Second)
+		if !match.IsDuplicate() {
+			continue
+		}
+		response = append(response, dto.PatientDuplicatePair{
+			First:   toPatientResponse(pair.First),
+			Second:  toPatientResponse(pair.Second),
+			Score:   match.Score,
+			Reasons: match.Reasons,
 		})
 	}
 	return response, nil
 }
+
+// MergePatients folds the duplicate into the surviving patient: examinations
+// with their images and reports move over, empty fields on the survivor are
+// filled from the duplicate, and the duplicate is removed. The merge is
+// recorded as made by the caller.
+func (uc *PatientUseCase) MergePatients(ctx context.Context, survivorID string, req dto.MergePatientRequest) (*dto.PatientMergeResponse, error) {
+	claims := auth.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, ErrUnauthenticated
+	}
+	if survivorID == req.DuplicateID {
+		return nil, ErrMergeSamePatient
+	}
+
+	var merge *entities.PatientMerge
+	err := uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		survivor, err := uc.patientRepo.GetByID(ctx, survivorID)
+		if err != nil {
+			return err
+		}
+		duplicate, err := uc.patientRepo.GetByID(ctx, req.DuplicateID)
+		if err != nil {
+			return err
+		}
+		if survivor == nil || duplicate == nil {
+			return ErrPatientNotFound
+		}
+
+		user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil || !user.IsActive {
+			return ErrUserNotFound
+		}
+
+		merge = entities.NewPatientMerge(uuid.New().String(), survivor, duplicate, user.ID)
+		survivor.Absorb(duplicate)
+		return uc.mergeRepo.Merge(ctx, merge, survivor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toPatientMergeResponse(merge), nil
+}
+
+func (uc *PatientUseCase) ListMerges(ctx context.Context, id string) ([]*dto.PatientMergeResponse, error) {
+	merges, err := uc.mergeRepo.ListBySurvivorID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.PatientMergeResponse, 0, len(merges))
+	for _, merge := range merges {
+		response = append(response, toPatientMergeResponse(merge))
+	}
+	return response, nil
+}
+
+func (uc *PatientUseCase) findDuplicates(ctx context.Context, patient *entities.Patient) ([]dto.PatientDuplicate, error) {
+	candidates, err := uc.patientRepo.FindDuplicateCandidates(ctx, patient, maxDuplicateCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var duplicates []dto.PatientDuplicate
+	for _, candidate := range candidates {
+		match := entities.MatchPatients(patient, candidate)
+		if !match.IsDuplicate() {
+			continue
+		}
+		duplicates = append(duplicates, dto.PatientDuplicate{
+			Patient: toPatientResponse(candidate),
+			Score:   match.Score,
+			Reasons: match.Reasons,
+		})
+	}
+	return duplicates, nil
+}
+
+func toPatientResponse(p *entities.Patient) *dto.PatientResponse {
+	return &dto.PatientResponse{
+		ID:             p.ID,
+		FirstName:      p.FirstName,
+		LastName:       p.LastName,
+		MiddleName:     p.MiddleName,
+		DateOfBirth:    p.DateOfBirth,
+		Gender:         p.Gender,
+		Phone:          p.Phone,
+		Email:          p.Email,
+		ExternalSystem: p.ExternalSystem,
+		ExternalID:     p.ExternalID,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+		Version:        p.Version,
+
+		DeletedAt:       p.DeletedAt,
+		LegalHoldUntil:  p.LegalHoldUntil,
+		LegalHoldReason: p.LegalHoldReason,
+	}
+}
+
+func toPatientMergeResponse(merge *entities.PatientMerge) *dto.PatientMergeResponse {
+	return &dto.PatientMergeResponse{
+		ID:             merge.ID,
+		SurvivorID:     merge.SurvivorID,
+		MergedID:       merge.MergedID,
+		MergedBy:       merge.MergedBy,
+		MergedPatient:  toPatientResponse(merge.MergedPatient),
+		ExaminationIDs: merge.ExaminationIDs,
+		ImageIDs:       merge.ImageIDs,
+		ReportIDs:      merge.ReportIDs,
+		CreatedAt:      merge.CreatedAt,
+	}
+}
@@ -0,0 +1,267 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+	"github.com/project-capillary/backend/internal/infrastructure/imaging"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
+)
+
+const staleCaptureBatch = 100
+
+// PhotoInboxUseCase keeps the photos captured on devices until they are
+// attached to an examination.
+type PhotoInboxUseCase struct {
+	captureRepo repositories.PhotoCaptureRepository
+	imageRepo   repositories.ImageRepository
+	blobs       storage.BlobStore
+	urls        *auth.URLSigner
+}
+
+func NewPhotoInboxUseCase(
+	captureRepo repositories.PhotoCaptureRepository,
+	imageRepo repositories.ImageRepository,
+	blobs storage.BlobStore,
+	urls *auth.URLSigner,
+) *PhotoInboxUseCase {
+	return &PhotoInboxUseCase{
+		captureRepo: captureRepo,
+		imageRepo:   imageRepo,
+		blobs:       blobs,
+		urls:        urls,
+	}
+}
+
+// Capture stores a photo taken on deviceID and puts it in the caller's
+// inbox. Capturing the same photo again returns the existing entry.
+func (uc *PhotoInboxUseCase) Capture(ctx context.Context, data []byte, deviceID string) (*dto.PhotoCaptureResponse, error) {
+	if err := requireOrganization(ctx); err != nil {
+		return nil, err
+	}
+	info, err := imaging.Inspect(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	key, sum, _, err := storage.PutContent(ctx, uc.blobs, data, info.Ext, info.MimeType)
+	if err != nil {
+		return nil, err
+	}
+	// Renditions missing after a failure here are rendered on first request.
+	_ = storeRenditions(ctx, uc.blobs, key, data)
+
+	capture, err := uc.captureRepo.GetUnassignedByFilename(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if capture == nil {
+		capturedBy := auth.ClaimsFromContext(ctx).UserID
+		capture = entities.NewPhotoCapture(uuid.New().String(), key, sum, info.MimeType, int64(len(data)), capturedBy, deviceID)
+		capture.OrganizationID = auth.OrganizationID(ctx)
+		if err := uc.captureRepo.Create(ctx, capture); err != nil {
+			return nil, err
+		}
+	}
+
+	response := uc.toResponse(capture)
+	return &response, nil
+}
+
+// Inbox returns the newest unassigned captures, up to dto.MaxPageSize.
+func (uc *PhotoInboxUseCase) Inbox(ctx context.Context) ([]dto.PhotoCaptureResponse, error) {
+	if err := requireOrganization(ctx); err != nil {
+		return nil, err
+	}
+	captures, err := uc.captureRepo.ListUnassigned(ctx, dto.MaxPageSize, 0)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]dto.PhotoCaptureResponse, 0, len(captures))
+	for _, capture := range captures {
+		response = append(response, uc.toResponse(capture))
+	}
+	return response, nil
+}
+
+func (uc *PhotoInboxUseCase) ListInbox(ctx context.Context, pageRequest dto.PageRequest) (*dto.PaginatedResponse, error) {
+	if err := requireOrganization(ctx); err != nil {
+		return nil, err
+	}
+	limit, offset, page := pageRequest.Bounds()
+	captures, err := uc.captureRepo.ListUnassigned(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	total, err := uc.captureRepo.CountUnassigned(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.PhotoCaptureResponse, 0, len(captures))
+	for _, capture := range captures {
+		response = append(response, uc.toResponse(capture))
+	}
+	return dto.NewPaginatedResponse(response, total, page, limit), nil
+}
+
+// GetPhoto returns a stored photo the caller may see: one in their
+// organization's inbox or attached to one of its examinations. Requests with
+// a signed URL carry no organization and see any photo.
+func (uc *PhotoInboxUseCase) GetPhoto(ctx context.Context, filename string) (*dto.ImageContent, error) {
+	key := storage.PhotoKey(filename)
+	if auth.OrganizationID(ctx) != "" {
+		if err := photoVisible(ctx, uc.captureRepo, uc.imageRepo, key); err != nil {
+			return nil, err
+		}
+	}
+
+	data, info, err := storage.ReadVerified(ctx, uc.blobs, key, "")
+	if errors.Is(err, storage.ErrBlobNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return nil, fmt.Errorf("%w: %s", ErrPhotoNotFound, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	etag, ok := storage.HashFromKey(key)
+	if !ok {
+		etag = storage.Checksum(data)
+	}
+	return &dto.ImageContent{
+		Name:        key,
+		ContentType: info.ContentType,
+		ETag:        etag,
+		Data:        data,
+	}, nil
+}
+
+// DeletePhoto removes a photo from the caller's inbox and deletes the
+// stored file unless something else still refers to it. Photos attached to
+// an examination cannot be deleted here, and photos stored before the inbox
+// only by the default clinic.
+func (uc *PhotoInboxUseCase) DeletePhoto(ctx context.Context, filename string) error {
+	if err := requireOrganization(ctx); err != nil {
+		return err
+	}
+	key := storage.PhotoKey(filename)
+	attached, err := uc.imageRepo.CountByFilename(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attached > 0 {
+		return fmt.Errorf("%w: %s", ErrPhotoInUse, key)
+	}
+
+	capture, err := uc.captureRepo.GetUnassignedByFilename(ctx, key)
+	if err != nil {
+		return err
+	}
+	if capture == nil {
+		// Photos stored before the inbox existed have no entry; they belong
+		// to the default clinic, like all data from before organizations.
+		if auth.OrganizationID(ctx) != entities.DefaultOrganizationID {
+			return fmt.Errorf("%w: %s", ErrPhotoNotFound, key)
+		}
+		_, err := uc.blobs.Stat(ctx, key)
+		if errors.Is(err, storage.ErrBlobNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return fmt.Errorf("%w: %s", ErrPhotoNotFound, key)
+		}
+		if err != nil {
+			return err
+		}
+	} else if err := uc.captureRepo.Delete(ctx, capture.ID); err != nil {
+		return err
+	}
+	return uc.deleteUnreferenced(ctx, key)
+}
+
+// CleanupStale removes captures left in the inbox for longer than maxAge,
+// with their files, and returns how many were removed. Run unscoped, it
+// cleans up every organization's inbox.
+func (uc *PhotoInboxUseCase) CleanupStale(ctx context.Context, maxAge time.Duration) (int, error) {
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for {
+		captures, err := uc.captureRepo.ListStale(ctx, cutoff, staleCaptureBatch)
+		if err != nil {
+			return removed, err
+		}
+		for _, capture := range captures {
+			if err := uc.captureRepo.Delete(ctx, capture.ID); err != nil {
+				return removed, err
+			}
+			if err := uc.deleteUnreferenced(ctx, capture.Filename); err != nil {
+				return removed, fmt.Errorf("delete photo %s: %w", capture.Filename, err)
+			}
+			removed++
+		}
+		if len(captures) < staleCaptureBatch {
+			return removed, nil
+		}
+	}
+}
+
+// deleteUnreferenced deletes a stored photo and its renditions once no
+// image and no capture of any organization refers to it; identical photos
+// share one file.
+func (uc *PhotoInboxUseCase) deleteUnreferenced(ctx context.Context, key string) error {
+	references, err := uc.imageRepo.CountReferences(ctx, key)
+	if err != nil {
+		return err
+	}
+	if references > 0 {
+		return nil
+	}
+	if err := deleteRenditions(ctx, uc.blobs, key); err != nil {
+		return err
+	}
+	return uc.blobs.Delete(ctx, key)
+}
+
+// photoVisible fails with ErrPhotoNotFound unless the stored photo is in
+// the caller's inbox or attached to one of the caller's examinations.
+// Stored files are shared across organizations, so existing is not enough.
+func photoVisible(ctx context.Context, captureRepo repositories.PhotoCaptureRepository, imageRepo repositories.ImageRepository, key string) error {
+	capture, err := captureRepo.GetUnassignedByFilename(ctx, key)
+	if err != nil || capture != nil {
+		return err
+	}
+	attached, err := imageRepo.CountByFilename(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attached == 0 {
+		return fmt.Errorf("%w: %s", ErrPhotoNotFound, key)
+	}
+	return nil
+}
+
+// requireOrganization rejects callers without an organization. Repositories
+// read an unscoped context as every organization, which only maintenance
+// jobs such as CleanupStale may use.
+func requireOrganization(ctx context.Context) error {
+	if auth.OrganizationID(ctx) == "" {
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+func (uc *PhotoInboxUseCase) toResponse(capture *entities.PhotoCapture) dto.PhotoCaptureResponse {
+	return dto.PhotoCaptureResponse{
+		ID:         capture.ID,
+		Filename:   capture.Filename,
+		URL:        uc.urls.Sign("/api/photos/" + url.PathEscape(capture.Filename)),
+		FileSize:   capture.FileSize,
+		MimeType:   capture.MimeType,
+		CapturedBy: capture.CapturedBy,
+		DeviceID:   capture.DeviceID,
+		CapturedAt: capture.CapturedAt,
+	}
+}
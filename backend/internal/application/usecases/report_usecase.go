@@ -2,12 +2,17 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/project-capillary/backend/internal/application/dto"
 	"github.com/project-capillary/backend/internal/domain/entities"
 	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+	"github.com/project-capillary/backend/internal/infrastructure/signing"
+	"github.com/project-capillary/backend/internal/infrastructure/storage"
 )
 
 type ReportUseCase struct {
@@ -15,6 +20,11 @@ type ReportUseCase struct {
 	examinationRepo repositories.ExaminationRepository
 	analysisRepo    repositories.AnalysisRepository
 	imageRepo       repositories.ImageRepository
+	userRepo        repositories.UserRepository
+	signer          *signing.Signer
+	blobs           storage.BlobStore
+	urls            *auth.URLSigner
+	onReportSigned  func(report *entities.Report)
 }
 
 func NewReportUseCase(
@@ -22,16 +32,43 @@ func NewReportUseCase(
 	examinationRepo repositories.ExaminationRepository,
 	analysisRepo repositories.AnalysisRepository,
 	imageRepo repositories.ImageRepository,
+	userRepo repositories.UserRepository,
+	signer *signing.Signer,
+	blobs storage.BlobStore,
+	urls *auth.URLSigner,
 ) *ReportUseCase {
 	return &ReportUseCase{
 		reportRepo:      reportRepo,
 		examinationRepo: examinationRepo,
 		analysisRepo:    analysisRepo,
 		imageRepo:       imageRepo,
+		userRepo:        userRepo,
+		signer:          signer,
+		blobs:           blobs,
+		urls:            urls,
 	}
 }
 
+func (uc *ReportUseCase) SetReportSignedCallback(callback func(report *entities.Report)) {
+	uc.onReportSigned = callback
+}
+
 func (uc *ReportUseCase) CreateReport(ctx context.Context, req dto.CreateReportRequest) (*dto.ReportResponse, error) {
+	examination, err := uc.examinationRepo.GetByID(ctx, req.ExaminationID)
+	if err != nil {
+		return nil, err
+	}
+	if examination == nil {
+		return nil, ErrExaminationNotFound
+	}
+	author, err := uc.userRepo.GetByID(ctx, req.GeneratedBy)
+	if err != nil {
+		return nil, err
+	}
+	if author == nil {
+		return nil, ErrUserNotFound
+	}
+
 	report := entities.NewReport(
 		uuid.New().String(),
 		req.ExaminationID,
@@ -43,7 +80,7 @@ func (uc *ReportUseCase) CreateReport(ctx context.Context, req dto.CreateReportR
 		req.GeneratedBy,
 	)
 
-	err := uc.reportRepo.Create(ctx, report)
+	err = uc.reportRepo.Create(ctx, report)
 	if err != nil {
 		return nil, err
 	}
@@ -57,8 +94,12 @@ func (uc *ReportUseCase) CreateReport(ctx context.Context, req dto.CreateReportR
 		Diagnosis:       report.Diagnosis,
 		Recommendations: report.Recommendations,
 		GeneratedBy:     report.GeneratedBy,
+		Status:          string(report.Status),
+		SignedBy:        report.SignedBy,
+		SignedAt:        report.SignedAt,
 		CreatedAt:       report.CreatedAt,
 		UpdatedAt:       report.UpdatedAt,
+		Version:         report.Version,
 	}, nil
 }
 
@@ -75,11 +116,7 @@ func (uc *ReportUseCase) GetReport(ctx context.Context, id string) (*dto.ReportR
 
 	imageInfos := make([]dto.ImageInfo, 0, len(images))
 	for _, img := range images {
-		imageInfos = append(imageInfos, dto.ImageInfo{
-			ID:       img.ID,
-			Filename: img.Filename,
-			URL:      "/api/photos/" + img.Filename,
-		})
+		imageInfos = append(imageInfos, imageInfo(uc.urls, img))
 	}
 
 	return &dto.ReportResponse{
@@ -91,9 +128,13 @@ func (uc *ReportUseCase) GetReport(ctx context.Context, id string) (*dto.ReportR
 		Diagnosis:       report.Diagnosis,
 		Recommendations: report.Recommendations,
 		GeneratedBy:     report.GeneratedBy,
+		Status:          string(report.Status),
+		SignedBy:        report.SignedBy,
+		SignedAt:        report.SignedAt,
 		Images:          imageInfos,
 		CreatedAt:       report.CreatedAt,
 		UpdatedAt:       report.UpdatedAt,
+		Version:         report.Version,
 	}, nil
 }
 
@@ -110,11 +151,7 @@ func (uc *ReportUseCase) GetReportByExamination(ctx context.Context, examination
 
 	imageInfos := make([]dto.ImageInfo, 0, len(images))
 	for _, img := range images {
-		imageInfos = append(imageInfos, dto.ImageInfo{
-			ID:       img.ID,
-			Filename: img.Filename,
-			URL:      "/api/photos/" + img.Filename,
-		})
+		imageInfos = append(imageInfos, imageInfo(uc.urls, img))
 	}
 
 	return &dto.ReportResponse{
@@ -126,19 +163,29 @@ func (uc *ReportUseCase) GetReportByExamination(ctx context.Context, examination
 		Diagnosis:       report.Diagnosis,
 		Recommendations: report.Recommendations,
 		GeneratedBy:     report.GeneratedBy,
+		Status:          string(report.Status),
+		SignedBy:        report.SignedBy,
+		SignedAt:        report.SignedAt,
 		Images:          imageInfos,
 		CreatedAt:       report.CreatedAt,
 		UpdatedAt:       report.UpdatedAt,
+		Version:         report.Version,
 	}, nil
 }
 
-func (uc *ReportUseCase) ListReports(ctx context.Context, limit, offset int) ([]*dto.ReportResponse, error) {
+func (uc *ReportUseCase) ListReports(ctx context.Context, pageRequest dto.PageRequest) (*dto.PaginatedResponse, error) {
+	limit, offset, page := pageRequest.Bounds()
 	reports, err := uc.reportRepo.List(ctx, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []*dto.ReportResponse
+	total, err := uc.reportRepo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.ReportResponse, 0, len(reports))
 	for _, report := range reports {
 		response = append(response, &dto.ReportResponse{
 			ID:              report.ID,
@@ -149,19 +196,35 @@ func (uc *ReportUseCase) ListReports(ctx context.Context, limit, offset int) ([]
 			Diagnosis:       report.Diagnosis,
 			Recommendations: report.Recommendations,
 			GeneratedBy:     report.GeneratedBy,
+			Status:          string(report.Status),
+			SignedBy:        report.SignedBy,
+			SignedAt:        report.SignedAt,
 			CreatedAt:       report.CreatedAt,
 			UpdatedAt:       report.UpdatedAt,
+			Version:         report.Version,
 		})
 	}
 
-	return response, nil
+	return dto.NewPaginatedResponse(response, total, page, limit), nil
 }
 
-func (uc *ReportUseCase) UpdateReport(ctx context.Context, id string, req dto.UpdateReportRequest) (*dto.ReportResponse, error) {
+// UpdateReport applies req if the report is still at version, which the
+// client read it at; 0 skips the check.
+func (uc *ReportUseCase) UpdateReport(ctx context.Context, id string, version int, req dto.UpdateReportRequest) (*dto.ReportResponse, error) {
 	report, err := uc.reportRepo.GetByID(ctx, id)
-	if err != nil || report == nil {
+	if err != nil {
 		return nil, err
 	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+	if version != 0 && report.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
+	if report.IsSigned() {
+		return nil, ErrReportSigned
+	}
 
 	if req.Content != "" {
 		report.Content = req.Content
@@ -179,9 +242,168 @@ func (uc *ReportUseCase) UpdateReport(ctx context.Context, id string, req dto.Up
 	report.UpdatedAt = time.Now()
 
 	err = uc.reportRepo.Update(ctx, report)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return nil, ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.GetReport(ctx, id)
+}
+
+// SignReport signs a report as the calling doctor.
+func (uc *ReportUseCase) SignReport(ctx context.Context, id string) (*dto.ReportResponse, error) {
+	claims := auth.ClaimsFromContext(ctx)
+	if claims == nil || claims.Role != entities.RoleDoctor {
+		return nil, ErrSignerNotAllowed
+	}
+	report, err := uc.reportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+	if report.IsSigned() {
+		return nil, ErrReportSigned
+	}
+
+	doctor, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if doctor == nil || !doctor.IsActive || doctor.Role != entities.RoleDoctor {
+		return nil, ErrSignerNotAllowed
+	}
+
+	key, err := uc.signer.KeyForUser(ctx, doctor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	digests, err := uc.imageDigests(ctx, report.ExaminationID)
+	if err != nil {
+		return nil, err
+	}
+
+	signedAt := time.Now().UTC().Truncate(time.Second)
+	payload, err := signing.NewPayload(report, doctor.ID, key.ID, signedAt, digests).Canonical()
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signing.Sign(key, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Sign(doctor.ID, key.ID, signedAt, signature)
+	err = uc.reportRepo.Update(ctx, report)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return nil, ErrVersionMismatch
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if uc.onReportSigned != nil {
+		uc.onReportSigned(report)
+	}
+
 	return uc.GetReport(ctx, id)
 }
+
+func (uc *ReportUseCase) VerifyReport(ctx context.Context, id string) (*dto.ReportVerificationResponse, error) {
+	report, err := uc.reportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+
+	response := &dto.ReportVerificationResponse{
+		ReportID: report.ID,
+		SignedBy: report.SignedBy,
+		SignedAt: report.SignedAt,
+	}
+
+	if !report.IsSigned() || report.SignedAt == nil {
+		response.Reason = "report is not signed"
+		return response, nil
+	}
+
+	key, err := uc.signer.GetKey(ctx, report.SignatureKeyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		response.Reason = "signing key not found"
+		return response, nil
+	}
+
+	digests, err := uc.imageDigests(ctx, report.ExaminationID)
+	if err != nil {
+		response.Reason = err.Error()
+		return response, nil
+	}
+
+	payload, err := signing.NewPayload(report, report.SignedBy, key.ID, *report.SignedAt, digests).Canonical()
+	if err != nil {
+		return nil, err
+	}
+
+	response.Bundle = signing.NewBundle(key, payload, report.Signature)
+
+	if err := signing.Verify(key.PublicKey, payload, report.Signature); err != nil {
+		response.Reason = "report content or images changed after signing"
+		return response, nil
+	}
+
+	response.Valid = true
+	return response, nil
+}
+
+func (uc *ReportUseCase) imageDigests(ctx context.Context, examinationID string) ([]signing.ImageDigest, error) {
+	images, err := uc.imageRepo.GetByExaminationID(ctx, examinationID)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := make([]signing.ImageDigest, 0, len(images))
+	for _, img := range images {
+		checksum, err := uc.blobChecksum(ctx, storage.PhotoKey(img.Filename), img.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum image %s: %w", img.Filename, err)
+		}
+		digests = append(digests, signing.ImageDigest{
+			ID:       img.ID,
+			Filename: img.Filename,
+			SHA256:   checksum,
+		})
+	}
+	return digests, nil
+}
+
+// blobChecksum hashes the stored photo and refuses to sign over one that no
+// longer matches the checksum recorded when it was captured.
+func (uc *ReportUseCase) blobChecksum(ctx context.Context, key, want string) (string, error) {
+	body, _, err := uc.blobs.Stream(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	checksum, err := signing.ReaderSHA256(body)
+	if err != nil {
+		return "", err
+	}
+	if want == "" {
+		want, _ = storage.HashFromKey(key)
+	}
+	if want != "" && checksum != want {
+		return "", fmt.Errorf("%w: %s", storage.ErrChecksumMismatch, key)
+	}
+	return checksum, nil
+}
@@ -2,24 +2,74 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/project-capillary/backend/internal/application/dto"
 	"github.com/project-capillary/backend/internal/domain/entities"
 	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUseCase struct {
-	userRepo repositories.UserRepository
+	userRepo         repositories.UserRepository
+	organizationRepo repositories.OrganizationRepository
+	txManager        repositories.TransactionManager
+	tokens           *auth.TokenIssuer
 }
 
-func NewUserUseCase(userRepo repositories.UserRepository) *UserUseCase {
-	return &UserUseCase{userRepo: userRepo}
+func NewUserUseCase(
+	userRepo repositories.UserRepository,
+	organizationRepo repositories.OrganizationRepository,
+	txManager repositories.TransactionManager,
+	tokens *auth.TokenIssuer,
+) *UserUseCase {
+	return &UserUseCase{userRepo: userRepo, organizationRepo: organizationRepo, txManager: txManager, tokens: tokens}
 }
 
+// reservedUsernames cannot be registered: a user named "system" would pass
+// for the application itself.
+var reservedUsernames = map[string]bool{"system": true}
+
+// CreateUser adds a user to the calling administrator's clinic, or, for an
+// anonymous caller, registers a new clinic with the user as its first
+// administrator. Usernames and emails are unique across clinics, but an
+// administrator's lookups only see their own clinic, so a clash with another
+// clinic is only caught when the user is stored.
 func (uc *UserUseCase) CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+	claims := auth.ClaimsFromContext(ctx)
+	role := entities.UserRole(req.Role)
+	switch {
+	case claims == nil:
+		if strings.TrimSpace(req.OrganizationName) == "" {
+			return nil, ErrOrganizationRequired
+		}
+		role = entities.RoleAdmin
+	case claims.Role != entities.RoleAdmin:
+		return nil, ErrAdminRequired
+	case role == "":
+		return nil, ErrRoleRequired
+	}
+
+	if reservedUsernames[strings.ToLower(strings.TrimSpace(req.Username))] {
+		return nil, ErrUsernameReserved
+	}
+	if existing, err := uc.userRepo.GetByUsername(ctx, req.Username); err != nil || existing != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrUserExists
+	}
+	if existing, err := uc.userRepo.GetByEmail(ctx, req.Email); err != nil || existing != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrUserExists
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -30,32 +80,36 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, req dto.CreateUserRequest
 		req.Username,
 		req.Email,
 		string(hashedPassword),
-		entities.UserRole(req.Role),
+		role,
 		req.FirstName,
 		req.LastName,
 	)
 
-	err = uc.userRepo.Create(ctx, user)
+	err = uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if claims != nil {
+			user.OrganizationID = claims.OrganizationID
+		} else {
+			organization := entities.NewOrganization(uuid.New().String(), strings.TrimSpace(req.OrganizationName))
+			if err := uc.organizationRepo.Create(ctx, organization); err != nil {
+				return err
+			}
+			user.OrganizationID = organization.ID
+		}
+		return uc.userRepo.Create(ctx, user)
+	})
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return nil, ErrUserExists
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      string(user.Role),
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}, nil
+	return toUserResponse(user), nil
 }
 
 func (uc *UserUseCase) Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, error) {
 	user, err := uc.userRepo.GetByUsername(ctx, req.Username)
-	if err != nil || user == nil {
+	if err != nil || user == nil || !user.IsActive {
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
@@ -64,21 +118,23 @@ func (uc *UserUseCase) Login(ctx context.Context, req dto.LoginRequest) (*dto.Lo
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	token := uuid.New().String()
+	organization, err := uc.organizationRepo.GetByID(ctx, user.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if organization == nil {
+		return nil, ErrOrganizationNotFound
+	}
+
+	token, err := uc.tokens.Issue(user)
+	if err != nil {
+		return nil, err
+	}
 
 	return &dto.LoginResponse{
-		Token: token,
-		User: dto.UserResponse{
-			ID:        user.ID,
-			Username:  user.Username,
-			Email:     user.Email,
-			Role:      string(user.Role),
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		},
+		Token:        token,
+		User:         *toUserResponse(user),
+		Organization: *toOrganizationResponse(organization),
 	}, nil
 }
 
@@ -88,15 +144,20 @@ func (uc *UserUseCase) GetUser(ctx context.Context, id string) (*dto.UserRespons
 		return nil, err
 	}
 
+	return toUserResponse(user), nil
+}
+
+func toUserResponse(user *entities.User) *dto.UserResponse {
 	return &dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      string(user.Role),
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}, nil
+		ID:             user.ID,
+		OrganizationID: user.OrganizationID,
+		Username:       user.Username,
+		Email:          user.Email,
+		Role:           string(user.Role),
+		FirstName:      user.FirstName,
+		LastName:       user.LastName,
+		IsActive:       user.IsActive,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
 }
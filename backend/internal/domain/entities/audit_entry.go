@@ -0,0 +1,84 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionRead   = "read"
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditEntry records one access to the system. Entries form a hash chain:
+// each Hash covers the entry's fields and the previous entry's Hash, so any
+// edit, deletion or reordering breaks every hash that follows.
+type AuditEntry struct {
+	ID             string
+	Sequence       int64
+	OrganizationID string
+	ActorID        string
+	ActorRole      string
+	Action         string
+	ResourceType   string
+	ResourceID     string
+	Route          string
+	Status         int
+	ClientIP       string
+	RequestID      string
+	CreatedAt      time.Time
+	PrevHash       string
+	Hash           string
+}
+
+func NewAuditEntry(id, action, resourceType, resourceID string) *AuditEntry {
+	return &AuditEntry{
+		ID:           id,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		// Postgres keeps microseconds; truncate so the hash survives a round trip.
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+// Seal places the entry after the one with prevHash and computes its hash.
+func (e *AuditEntry) Seal(sequence int64, prevHash string) {
+	e.Sequence = sequence
+	e.PrevHash = prevHash
+	e.Hash = e.computeHash()
+}
+
+// Verify reports whether the entry follows prevHash and is unmodified.
+func (e *AuditEntry) Verify(prevHash string) bool {
+	return e.PrevHash == prevHash && e.Hash == e.computeHash()
+}
+
+func (e *AuditEntry) computeHash() string {
+	fields := []interface{}{
+		e.Sequence,
+		e.ID,
+		e.ActorID,
+		e.ActorRole,
+		e.Action,
+		e.ResourceType,
+		e.ResourceID,
+		e.Route,
+		e.Status,
+		e.ClientIP,
+		e.RequestID,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.PrevHash,
+	}
+	// Entries recorded before organizations existed hash without one.
+	if e.OrganizationID != "" {
+		fields = append(fields, e.OrganizationID)
+	}
+	canonical, _ := json.Marshal(fields)
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
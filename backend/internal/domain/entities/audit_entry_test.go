@@ -0,0 +1,41 @@
+package entities
+
+import "testing"
+
+func TestAuditEntryChain(t *testing.T) {
+	first := NewAuditEntry("a1", AuditActionRead, "patient", "p1")
+	first.ActorID = "u1"
+	first.Seal(1, "")
+	second := NewAuditEntry("a2", AuditActionUpdate, "patient", "p1")
+	second.ActorID = "u1"
+	second.OrganizationID = "o1"
+	second.Seal(2, first.Hash)
+
+	tests := []struct {
+		name     string
+		modify   func(e *AuditEntry)
+		prevHash string
+		want     bool
+	}{
+		{name: "intact", modify: func(e *AuditEntry) {}, prevHash: first.Hash, want: true},
+		{name: "edited actor", modify: func(e *AuditEntry) { e.ActorID = "u2" }, prevHash: first.Hash, want: false},
+		{name: "edited resource", modify: func(e *AuditEntry) { e.ResourceID = "p2" }, prevHash: first.Hash, want: false},
+		{name: "moved to another organization", modify: func(e *AuditEntry) { e.OrganizationID = "o2" }, prevHash: first.Hash, want: false},
+		{name: "reordered", modify: func(e *AuditEntry) { e.Sequence = 1 }, prevHash: first.Hash, want: false},
+		{name: "predecessor removed", modify: func(e *AuditEntry) {}, prevHash: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := *second
+			tt.modify(&entry)
+			if got := entry.Verify(tt.prevHash); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !first.Verify("") {
+		t.Error("first entry must verify against the empty genesis hash")
+	}
+}
@@ -13,16 +13,17 @@ const (
 )
 
 type Device struct {
-	ID         string
-	Name       string
-	DeviceID   string
-	Label      string
-	Status     DeviceStatus
-	LastSeen   time.Time
-	Brightness float64
-	Zoom       float64
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID             string
+	OrganizationID string
+	Name           string
+	DeviceID       string
+	Label          string
+	Status         DeviceStatus
+	LastSeen       time.Time
+	Brightness     float64
+	Zoom           float64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 func NewDevice(id, name, deviceID, label string) *Device {
@@ -41,6 +42,12 @@ func NewDevice(id, name, deviceID, label string) *Device {
 	}
 }
 
+func (d *Device) Rename(name, label string) {
+	d.Name = name
+	d.Label = label
+	d.UpdatedAt = time.Now()
+}
+
 func (d *Device) UpdateStatus(status DeviceStatus) {
 	d.Status = status
 	d.LastSeen = time.Now()
@@ -2,6 +2,8 @@ package entities
 
 import (
 	"time"
+
+	"github.com/project-capillary/backend/internal/domain/valueobjects"
 )
 
 type ExaminationStatus string
@@ -14,15 +16,17 @@ const (
 )
 
 type Examination struct {
-	ID          string
-	PatientID   string
-	DoctorID    string
-	Status      ExaminationStatus
-	Description string
-	Images      []string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	CompletedAt *time.Time
+	ID             string
+	OrganizationID string
+	PatientID      string
+	DoctorID       string
+	Status         ExaminationStatus
+	ExamType       string
+	Description    string
+	Images         []string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	CompletedAt    *time.Time
 }
 
 func NewExamination(id, patientID, doctorID, description string) *Examination {
@@ -32,6 +36,7 @@ func NewExamination(id, patientID, doctorID, description string) *Examination {
 		PatientID:   patientID,
 		DoctorID:    doctorID,
 		Status:      StatusPending,
+		ExamType:    valueobjects.ExamTypeGeneral,
 		Description: description,
 		Images:      []string{},
 		CreatedAt:   now,
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"time"
+)
+
+type HL7MessageStatus string
+
+const (
+	HL7MessagePending  HL7MessageStatus = "pending"
+	HL7MessageAccepted HL7MessageStatus = "accepted"
+	HL7MessageRejected HL7MessageStatus = "rejected"
+	HL7MessageFailed   HL7MessageStatus = "failed"
+)
+
+// HL7Message is an outbound result message together with its delivery state.
+type HL7Message struct {
+	ID             string
+	ReportID       string
+	ControlID      string
+	MessageType    string
+	Destination    string
+	Payload        string
+	Status         HL7MessageStatus
+	Attempts       int
+	AckCode        string
+	AckMessage     string
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	AcknowledgedAt *time.Time
+}
+
+func NewHL7Message(id, reportID, controlID, messageType, destination, payload string) *HL7Message {
+	now := time.Now()
+	return &HL7Message{
+		ID:          id,
+		ReportID:    reportID,
+		ControlID:   controlID,
+		MessageType: messageType,
+		Destination: destination,
+		Payload:     payload,
+		Status:      HL7MessagePending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Acknowledge records the receiver's answer to a delivery attempt.
+func (m *HL7Message) Acknowledge(code, text string, accepted bool) {
+	now := time.Now()
+	m.Attempts++
+	m.AckCode = code
+	m.AckMessage = text
+	m.LastError = ""
+	if accepted {
+		m.Status = HL7MessageAccepted
+	} else {
+		m.Status = HL7MessageRejected
+	}
+	m.AcknowledgedAt = &now
+	m.UpdatedAt = now
+}
+
+// RecordFailure records a delivery attempt that got no usable acknowledgment
+// or a transient error acknowledgment, keeping the message eligible for retry.
+func (m *HL7Message) RecordFailure(ackCode, err string) {
+	m.Attempts++
+	m.AckCode = ackCode
+	m.LastError = err
+	m.UpdatedAt = time.Now()
+}
+
+// GiveUp marks a message that is still unacknowledged after all retries.
+func (m *HL7Message) GiveUp() {
+	if m.Status == HL7MessagePending {
+		m.Status = HL7MessageFailed
+	}
+	m.UpdatedAt = time.Now()
+}
@@ -2,6 +2,8 @@ package entities
 
 import (
 	"time"
+
+	"github.com/project-capillary/backend/internal/domain/valueobjects"
 )
 
 type Image struct {
@@ -13,8 +15,15 @@ type Image struct {
 	MimeType      string
 	Width         int
 	Height        int
-	CapturedAt    time.Time
-	CreatedAt     time.Time
+	// Position is the finger the image was taken of, e.g. "R4", empty when
+	// not recorded.
+	Position string
+	// SHA256 is the hex checksum of the stored photo, empty for photos
+	// stored before content addressing.
+	SHA256     string
+	Metadata   *valueobjects.ImageMetadata
+	CapturedAt time.Time
+	CreatedAt  time.Time
 }
 
 func NewImage(id, examinationID, filename, filepath, mimeType string, fileSize int64, width, height int) *Image {
@@ -32,3 +41,16 @@ func NewImage(id, examinationID, filename, filepath, mimeType string, fileSize i
 		CreatedAt:     now,
 	}
 }
+
+// ApplyMetadata records what was read from the stored file. The EXIF capture
+// time, when present, replaces the time the image was attached.
+func (i *Image) ApplyMetadata(mimeType string, metadata *valueobjects.ImageMetadata) {
+	i.MimeType = mimeType
+	i.Width = metadata.Width
+	i.Height = metadata.Height
+	i.FileSize = metadata.SizeBytes
+	i.Metadata = metadata
+	if metadata.TakenAt != nil {
+		i.CapturedAt = *metadata.TakenAt
+	}
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+)
+
+// DefaultOrganizationID is the clinic that data recorded before
+// organizations existed was assigned to (migration 010).
+const DefaultOrganizationID = "00000000-0000-0000-0000-000000000001"
+
+// Organization is a clinic. Users, patients, devices and examinations
+// belong to exactly one organization and are never visible to another.
+type Organization struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewOrganization(id, name string) *Organization {
+	now := time.Now()
+	return &Organization{
+		ID:        id,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (o *Organization) Rename(name string) {
+	o.Name = name
+	o.UpdatedAt = time.Now()
+}
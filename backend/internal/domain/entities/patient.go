@@ -5,16 +5,24 @@ import (
 )
 
 type Patient struct {
-	ID          string
-	FirstName   string
-	LastName    string
-	MiddleName  string
-	DateOfBirth time.Time
-	Gender      string
-	Phone       string
-	Email       string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID              string
+	OrganizationID  string
+	FirstName       string
+	LastName        string
+	MiddleName      string
+	DateOfBirth     time.Time
+	Gender          string
+	Phone           string
+	Email           string
+	ExternalSystem  string
+	ExternalID      string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
+	ErasedAt        *time.Time
+	LegalHoldUntil  *time.Time
+	LegalHoldReason string
+	Version         int
 }
 
 func NewPatient(id, firstName, lastName, middleName string, dateOfBirth time.Time, gender, phone, email string) *Patient {
@@ -30,6 +38,7 @@ func NewPatient(id, firstName, lastName, middleName string, dateOfBirth time.Tim
 		Email:       email,
 		CreatedAt:   now,
 		UpdatedAt:   now,
+		Version:     1,
 	}
 }
 
@@ -42,6 +51,100 @@ func (p *Patient) Update(firstName, lastName, middleName, phone, email string) {
 	p.UpdatedAt = time.Now()
 }
 
+func (p *Patient) UpdateDemographics(dateOfBirth time.Time, gender string) {
+	p.DateOfBirth = dateOfBirth
+	p.Gender = gender
+	p.UpdatedAt = time.Now()
+}
+
+func (p *Patient) SetExternalIdentifier(system, value string) {
+	p.ExternalSystem = system
+	p.ExternalID = value
+	p.UpdatedAt = time.Now()
+}
+
+// Absorb fills fields the survivor is missing from the merged record.
+func (p *Patient) Absorb(merged *Patient) {
+	if p.MiddleName == "" {
+		p.MiddleName = merged.MiddleName
+	}
+	if p.Phone == "" {
+		p.Phone = merged.Phone
+	}
+	if p.Email == "" {
+		p.Email = merged.Email
+	}
+	if p.ExternalID == "" && merged.ExternalID != "" {
+		p.ExternalSystem = merged.ExternalSystem
+		p.ExternalID = merged.ExternalID
+	}
+	p.UpdatedAt = time.Now()
+}
+
+func (p *Patient) SoftDelete() {
+	now := time.Now()
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+}
+
+func (p *Patient) Restore() {
+	p.DeletedAt = nil
+	p.UpdatedAt = time.Now()
+}
+
+func (p *Patient) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+func (p *Patient) IsErased() bool {
+	return p.ErasedAt != nil
+}
+
+func (p *Patient) PlaceLegalHold(until time.Time, reason string) {
+	p.LegalHoldUntil = &until
+	p.LegalHoldReason = reason
+	p.UpdatedAt = time.Now()
+}
+
+func (p *Patient) ReleaseLegalHold() {
+	p.LegalHoldUntil = nil
+	p.LegalHoldReason = ""
+	p.UpdatedAt = time.Now()
+}
+
+// ErasableAt returns the earliest time the patient's data may be erased: the
+// end of any legal hold, and retentionYears after lastActivity (the latest
+// examination, or record creation when there is none).
+func (p *Patient) ErasableAt(lastActivity time.Time, retentionYears int) time.Time {
+	if lastActivity.Before(p.CreatedAt) {
+		lastActivity = p.CreatedAt
+	}
+	erasableAt := lastActivity.AddDate(retentionYears, 0, 0)
+	if p.LegalHoldUntil != nil && p.LegalHoldUntil.After(erasableAt) {
+		erasableAt = *p.LegalHoldUntil
+	}
+	return erasableAt
+}
+
+// Anonymize replaces personal data with placeholders, keeping only the birth
+// year and gender for statistics, and marks the record erased and deleted.
+func (p *Patient) Anonymize() {
+	now := time.Now()
+	p.FirstName = "-"
+	p.LastName = "Anonymized"
+	p.MiddleName = ""
+	p.DateOfBirth = time.Date(p.DateOfBirth.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	p.Phone = ""
+	p.Email = ""
+	p.ExternalSystem = ""
+	p.ExternalID = ""
+	p.LegalHoldUntil = nil
+	p.LegalHoldReason = ""
+	p.ErasedAt = &now
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+}
+
 func (p *Patient) FullName() string {
 	if p.MiddleName != "" {
 		return p.LastName + " " + p.FirstName + " " + p.MiddleName
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+)
+
+type ErasureMode string
+
+const (
+	// ErasureModeAnonymize keeps de-identified clinical data (examinations,
+	// analyses, reports) and removes personal data and photos.
+	ErasureModeAnonymize ErasureMode = "anonymize"
+	// ErasureModeDelete removes the patient and every dependent record.
+	ErasureModeDelete ErasureMode = "delete"
+)
+
+// PatientErasure is the audit record of an erasure. It deliberately holds no
+// personal data of the erased patient.
+type PatientErasure struct {
+	ID               string
+	PatientID        string
+	RequestedBy      string
+	Reason           string
+	Mode             ErasureMode
+	ExaminationCount int
+	ImageCount       int
+	FilesDeleted     int
+	CreatedAt        time.Time
+}
+
+func NewPatientErasure(id, patientID, requestedBy, reason string, mode ErasureMode) *PatientErasure {
+	return &PatientErasure{
+		ID:          id,
+		PatientID:   patientID,
+		RequestedBy: requestedBy,
+		Reason:      reason,
+		Mode:        mode,
+		CreatedAt:   time.Now(),
+	}
+}
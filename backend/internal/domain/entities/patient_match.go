@@ -0,0 +1,130 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	MatchReasonDateOfBirth = "same_date_of_birth"
+	MatchReasonName        = "similar_name"
+	MatchReasonPhone       = "same_phone"
+	MatchReasonEmail       = "same_email"
+
+	// DuplicateThreshold is the lowest score reported as a likely duplicate.
+	// A shared date of birth with an identical name reaches it on its own; a
+	// shared phone or email compensates for a misspelt name.
+	DuplicateThreshold = 0.6
+
+	similarNameThreshold = 0.8
+	minPhoneDigits       = 7
+	phoneSuffixDigits    = 10
+)
+
+type PatientMatch struct {
+	Score   float64
+	Reasons []string
+}
+
+func (m PatientMatch) IsDuplicate() bool {
+	return m.Score >= DuplicateThreshold
+}
+
+// MatchPatients scores how likely two records describe the same person.
+// Records with different dates of birth never match.
+func MatchPatients(a, b *Patient) PatientMatch {
+	if a.DateOfBirth.Format("2006-01-02") != b.DateOfBirth.Format("2006-01-02") {
+		return PatientMatch{}
+	}
+
+	match := PatientMatch{Score: 0.3, Reasons: []string{MatchReasonDateOfBirth}}
+
+	nameSimilarity := similarity(NormalizeName(a.FullName()), NormalizeName(b.FullName()))
+	if short := similarity(NormalizeName(a.LastName+" "+a.FirstName), NormalizeName(b.LastName+" "+b.FirstName)); short > nameSimilarity {
+		nameSimilarity = short
+	}
+	match.Score += 0.4 * nameSimilarity
+	if nameSimilarity >= similarNameThreshold {
+		match.Reasons = append(match.Reasons, MatchReasonName)
+	}
+
+	if phone := PhoneKey(a.Phone); phone != "" && phone == PhoneKey(b.Phone) {
+		match.Score += 0.15
+		match.Reasons = append(match.Reasons, MatchReasonPhone)
+	}
+
+	if email := strings.ToLower(strings.TrimSpace(a.Email)); email != "" && email == strings.ToLower(strings.TrimSpace(b.Email)) {
+		match.Score += 0.15
+		match.Reasons = append(match.Reasons, MatchReasonEmail)
+	}
+
+	return match
+}
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "i", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia", 'y': "i", 'j': "i",
+}
+
+// NormalizeName lower-cases a name and transliterates Cyrillic to Latin, as
+// the capillary_translit SQL function does, so Cyrillic and Latin spellings
+// of a name compare equal.
+func NormalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.Join(strings.Fields(name), " ")) {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.ReplaceAll(b.String(), "kh", "h")
+}
+
+// PhoneKey returns the last ten digits of a phone number, or "" when there
+// are too few digits to compare.
+func PhoneKey(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) < minPhoneDigits {
+		return ""
+	}
+	if len(digits) > phoneSuffixDigits {
+		digits = digits[len(digits)-phoneSuffixDigits:]
+	}
+	return digits
+}
+
+// similarity returns 1 - normalized Levenshtein distance.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
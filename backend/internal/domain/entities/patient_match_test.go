@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatchPatients(t *testing.T) {
+	dob := time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC)
+	base := NewPatient("p1", "Иван", "Иванов", "Иванович", dob, "male", "+7 (999) 123-45-67", "ivan@example.com")
+
+	tests := []struct {
+		name      string
+		other     *Patient
+		duplicate bool
+		reasons   []string
+	}{
+		{
+			name:      "same person re-entered",
+			other:     NewPatient("p2", "Иван", "Иванов", "Иванович", dob, "male", "", ""),
+			duplicate: true,
+			reasons:   []string{MatchReasonDateOfBirth, MatchReasonName},
+		},
+		{
+			name:      "latin spelling",
+			other:     NewPatient("p2", "Ivan", "Ivanov", "", dob, "male", "", ""),
+			duplicate: true,
+			reasons:   []string{MatchReasonDateOfBirth, MatchReasonName},
+		},
+		{
+			name:      "typo with same phone",
+			other:     NewPatient("p2", "Иван", "Иваноф", "", dob, "male", "89991234567", ""),
+			duplicate: true,
+			reasons:   []string{MatchReasonDateOfBirth, MatchReasonName, MatchReasonPhone},
+		},
+		{
+			name:      "different person with shared email",
+			other:     NewPatient("p2", "Мария", "Петрова", "", dob, "female", "", "IVAN@example.com"),
+			duplicate: false,
+			reasons:   []string{MatchReasonDateOfBirth, MatchReasonEmail},
+		},
+		{
+			name:      "different date of birth",
+			other:     NewPatient("p2", "Иван", "Иванов", "Иванович", dob.AddDate(0, 0, 1), "male", "", ""),
+			duplicate: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := MatchPatients(base, tt.other)
+			if match.IsDuplicate() != tt.duplicate {
+				t.Errorf("IsDuplicate() = %v, want %v (score %.2f)", match.IsDuplicate(), tt.duplicate, match.Score)
+			}
+			if !reflect.DeepEqual(match.Reasons, tt.reasons) {
+				t.Errorf("Reasons = %v, want %v", match.Reasons, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestPatientAbsorb(t *testing.T) {
+	dob := time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC)
+	survivor := NewPatient("p1", "Иван", "Иванов", "", dob, "male", "", "ivan@example.com")
+	merged := NewPatient("p2", "Иван", "Иванов", "Иванович", dob, "male", "+79991234567", "other@example.com")
+	merged.SetExternalIdentifier("urn:oid:1.2.3", "MRN-1")
+
+	survivor.Absorb(merged)
+
+	if survivor.MiddleName != "Иванович" || survivor.Phone != "+79991234567" {
+		t.Errorf("missing fields not filled: %+v", survivor)
+	}
+	if survivor.Email != "ivan@example.com" {
+		t.Errorf("Email = %v, survivor value must win", survivor.Email)
+	}
+	if survivor.ExternalID != "MRN-1" || survivor.ExternalSystem != "urn:oid:1.2.3" {
+		t.Errorf("external identifier not taken over: %v/%v", survivor.ExternalSystem, survivor.ExternalID)
+	}
+}
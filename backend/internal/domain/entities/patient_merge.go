@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+)
+
+// PatientMerge records that MergedPatient was folded into SurvivorID. The
+// snapshot and the moved record IDs are kept so a merge can be audited and,
+// if needed, undone by hand.
+type PatientMerge struct {
+	ID             string
+	SurvivorID     string
+	MergedID       string
+	MergedBy       string
+	MergedPatient  *Patient
+	ExaminationIDs []string
+	ImageIDs       []string
+	ReportIDs      []string
+	CreatedAt      time.Time
+}
+
+func NewPatientMerge(id string, survivor, merged *Patient, mergedBy string) *PatientMerge {
+	snapshot := *merged
+	return &PatientMerge{
+		ID:            id,
+		SurvivorID:    survivor.ID,
+		MergedID:      merged.ID,
+		MergedBy:      mergedBy,
+		MergedPatient: &snapshot,
+		CreatedAt:     time.Now(),
+	}
+}
@@ -100,3 +100,55 @@ func TestPatient_FullName(t *testing.T) {
 		}
 	})
 }
+
+func TestPatientErasableAt(t *testing.T) {
+	created := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	lastExamination := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	hold := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		lastActivity   time.Time
+		retentionYears int
+		legalHold      *time.Time
+		want           time.Time
+	}{
+		{name: "no retention", lastActivity: lastExamination, want: lastExamination},
+		{name: "retention after last examination", lastActivity: lastExamination, retentionYears: 5, want: lastExamination.AddDate(5, 0, 0)},
+		{name: "no examinations uses creation", lastActivity: time.Time{}, retentionYears: 1, want: created.AddDate(1, 0, 0)},
+		{name: "legal hold outlasts retention", lastActivity: lastExamination, retentionYears: 5, legalHold: &hold, want: hold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patient := NewPatient("p1", "Ivan", "Ivanov", "", time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), "male", "", "")
+			patient.CreatedAt = created
+			if tt.legalHold != nil {
+				patient.PlaceLegalHold(*tt.legalHold, "litigation")
+			}
+			if got := patient.ErasableAt(tt.lastActivity, tt.retentionYears); !got.Equal(tt.want) {
+				t.Errorf("ErasableAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatientAnonymize(t *testing.T) {
+	patient := NewPatient("p1", "Ivan", "Ivanov", "Ivanovich", time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), "male", "+79991234567", "ivan@example.com")
+	patient.SetExternalIdentifier("urn:oid:1.2.3", "MRN-1")
+
+	patient.Anonymize()
+
+	if patient.FirstName == "Ivan" || patient.LastName == "Ivanov" || patient.MiddleName != "" {
+		t.Errorf("name not anonymized: %s", patient.FullName())
+	}
+	if patient.Phone != "" || patient.Email != "" || patient.ExternalID != "" {
+		t.Error("contact data and identifiers must be cleared")
+	}
+	if patient.DateOfBirth.Year() != 1990 || patient.DateOfBirth.YearDay() != 1 {
+		t.Errorf("DateOfBirth = %v, want 1990-01-01", patient.DateOfBirth)
+	}
+	if !patient.IsErased() || !patient.IsDeleted() {
+		t.Error("anonymized patient must be erased and deleted")
+	}
+}
@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"time"
+)
+
+// PhotoCapture is a photo taken with a capture device. Until it is attached
+// to an examination it waits in the photo inbox.
+type PhotoCapture struct {
+	ID             string
+	OrganizationID string
+	Filename       string
+	SHA256         string
+	FileSize       int64
+	MimeType       string
+	CapturedBy     string
+	DeviceID       string
+	ImageID        string
+	CapturedAt     time.Time
+	AssignedAt     *time.Time
+}
+
+func NewPhotoCapture(id, filename, sha256, mimeType string, fileSize int64, capturedBy, deviceID string) *PhotoCapture {
+	return &PhotoCapture{
+		ID:         id,
+		Filename:   filename,
+		SHA256:     sha256,
+		FileSize:   fileSize,
+		MimeType:   mimeType,
+		CapturedBy: capturedBy,
+		DeviceID:   deviceID,
+		CapturedAt: time.Now(),
+	}
+}
+
+// Assign records the image the capture was attached as, taking it out of
+// the inbox.
+func (c *PhotoCapture) Assign(imageID string) {
+	now := time.Now()
+	c.ImageID = imageID
+	c.AssignedAt = &now
+}
+
+func (c *PhotoCapture) Assigned() bool {
+	return c.ImageID != ""
+}
@@ -4,6 +4,13 @@ import (
 	"time"
 )
 
+type ReportStatus string
+
+const (
+	ReportStatusDraft  ReportStatus = "draft"
+	ReportStatusSigned ReportStatus = "signed"
+)
+
 type Report struct {
 	ID              string
 	ExaminationID   string
@@ -13,8 +20,14 @@ type Report struct {
 	Diagnosis       string
 	Recommendations string
 	GeneratedBy     string
+	Status          ReportStatus
+	SignedBy        string
+	SignedAt        *time.Time
+	SignatureKeyID  string
+	Signature       []byte
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
+	Version         int
 }
 
 func NewReport(id, examinationID, title, content, summary, diagnosis, recommendations, generatedBy string) *Report {
@@ -28,8 +41,10 @@ func NewReport(id, examinationID, title, content, summary, diagnosis, recommenda
 		Diagnosis:       diagnosis,
 		Recommendations: recommendations,
 		GeneratedBy:     generatedBy,
+		Status:          ReportStatusDraft,
 		CreatedAt:       now,
 		UpdatedAt:       now,
+		Version:         1,
 	}
 }
 
@@ -40,3 +55,16 @@ func (r *Report) Update(content, summary, diagnosis, recommendations string) {
 	r.Recommendations = recommendations
 	r.UpdatedAt = time.Now()
 }
+
+func (r *Report) Sign(signedBy, keyID string, signedAt time.Time, signature []byte) {
+	r.Status = ReportStatusSigned
+	r.SignedBy = signedBy
+	r.SignedAt = &signedAt
+	r.SignatureKeyID = keyID
+	r.Signature = signature
+	r.UpdatedAt = time.Now()
+}
+
+func (r *Report) IsSigned() bool {
+	return r.Status == ReportStatusSigned
+}
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+)
+
+const SigningAlgorithmEd25519 = "ed25519"
+
+type SigningKey struct {
+	ID         string
+	UserID     string
+	Algorithm  string
+	PublicKey  []byte
+	PrivateKey []byte
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+func NewSigningKey(id, userID string, publicKey, privateKey []byte) *SigningKey {
+	return &SigningKey{
+		ID:         id,
+		UserID:     userID,
+		Algorithm:  SigningAlgorithmEd25519,
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func (k *SigningKey) Revoke() {
+	now := time.Now()
+	k.RevokedAt = &now
+}
+
+func (k *SigningKey) IsActive() bool {
+	return k.RevokedAt == nil
+}
@@ -13,16 +13,17 @@ const (
 )
 
 type User struct {
-	ID           string
-	Username     string
-	Email        string
-	PasswordHash string
-	Role         UserRole
-	FirstName    string
-	LastName     string
-	IsActive     bool
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID             string
+	OrganizationID string
+	Username       string
+	Email          string
+	PasswordHash   string
+	Role           UserRole
+	FirstName      string
+	LastName       string
+	IsActive       bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 func NewUser(id, username, email, passwordHash string, role UserRole, firstName, lastName string) *User {
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type AuditRepository interface {
+	// Append seals the entry after the current last entry and stores it.
+	// Appends are serialized so the chain never forks.
+	Append(ctx context.Context, entry *entities.AuditEntry) error
+	List(ctx context.Context, filter AuditFilter) ([]*entities.AuditEntry, error)
+	Count(ctx context.Context, filter AuditFilter) (int64, error)
+	// ListAfter returns up to limit entries with Sequence greater than
+	// afterSequence, in chain order.
+	ListAfter(ctx context.Context, afterSequence int64, limit int) ([]*entities.AuditEntry, error)
+}
+
+// AuditFilter narrows List and Count; List returns the newest entries first.
+type AuditFilter struct {
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
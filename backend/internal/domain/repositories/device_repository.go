@@ -8,9 +8,12 @@ import (
 
 type DeviceRepository interface {
 	Create(ctx context.Context, device *entities.Device) error
+	Upsert(ctx context.Context, device *entities.Device) error
 	GetByID(ctx context.Context, id string) (*entities.Device, error)
 	GetByDeviceID(ctx context.Context, deviceID string) (*entities.Device, error)
 	Update(ctx context.Context, device *entities.Device) error
+	MarkAllOffline(ctx context.Context) (int64, error)
+	MarkOffline(ctx context.Context, organizationID, deviceID string) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]*entities.Device, error)
 	GetOnlineDevices(ctx context.Context) ([]*entities.Device, error)
@@ -0,0 +1,11 @@
+package repositories
+
+import "errors"
+
+// ErrVersionConflict is returned by versioned Update methods when the row no
+// longer has the version the entity was read at.
+var ErrVersionConflict = errors.New("record was modified concurrently")
+
+// ErrDuplicate is returned by Create methods when a unique value is already
+// taken, possibly by a row of another organization the caller cannot see.
+var ErrDuplicate = errors.New("record already exists")
@@ -9,6 +9,7 @@ import (
 type ExaminationRepository interface {
 	Create(ctx context.Context, examination *entities.Examination) error
 	GetByID(ctx context.Context, id string) (*entities.Examination, error)
+	GetByIDForUpdate(ctx context.Context, id string) (*entities.Examination, error)
 	Update(ctx context.Context, examination *entities.Examination) error
 	Delete(ctx context.Context, id string) error
 	GetByPatientID(ctx context.Context, patientID string) ([]*entities.Examination, error)
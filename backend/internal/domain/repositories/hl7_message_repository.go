@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type HL7MessageRepository interface {
+	Create(ctx context.Context, message *entities.HL7Message) error
+	GetByID(ctx context.Context, id string) (*entities.HL7Message, error)
+	Update(ctx context.Context, message *entities.HL7Message) error
+	ListByReportID(ctx context.Context, reportID string) ([]*entities.HL7Message, error)
+}
@@ -13,4 +13,8 @@ type ImageRepository interface {
 	GetByExaminationID(ctx context.Context, examinationID string) ([]*entities.Image, error)
 	List(ctx context.Context, limit, offset int) ([]*entities.Image, error)
 	Count(ctx context.Context) (int64, error)
+	CountByFilename(ctx context.Context, filename string) (int64, error)
+	// CountReferences counts the images and captures referring to a stored
+	// photo in every organization, since organizations share stored files.
+	CountReferences(ctx context.Context, filename string) (int64, error)
 }
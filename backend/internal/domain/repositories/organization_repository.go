@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+// Records owned by an organization are read and written only within the
+// organization of the caller in ctx; a context without a caller, as in the
+// worker, is not scoped.
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *entities.Organization) error
+	GetByID(ctx context.Context, id string) (*entities.Organization, error)
+	Update(ctx context.Context, organization *entities.Organization) error
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type PatientErasureRepository interface {
+	// Erase applies the erasure in one transaction and records it. In
+	// anonymize mode the patient is saved as given (already anonymized),
+	// image rows and captures lose their file references, merge snapshots
+	// are scrubbed and sent HL7 payloads are blanked; in delete mode the patient and all dependent records are
+	// removed.
+	Erase(ctx context.Context, erasure *entities.PatientErasure, patient *entities.Patient) error
+	ListByPatientID(ctx context.Context, patientID string) ([]*entities.PatientErasure, error)
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type PatientMergeRepository interface {
+	// Merge moves the merged patient's examinations, with their images and
+	// reports, to the survivor, deletes the merged patient, saves the
+	// survivor and records the merge, all in one transaction. The moved IDs
+	// are filled in on merge.
+	Merge(ctx context.Context, merge *entities.PatientMerge, survivor *entities.Patient) error
+	ListBySurvivorID(ctx context.Context, survivorID string) ([]*entities.PatientMerge, error)
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/project-capillary/backend/internal/domain/entities"
 )
@@ -9,9 +10,50 @@ import (
 type PatientRepository interface {
 	Create(ctx context.Context, patient *entities.Patient) error
 	GetByID(ctx context.Context, id string) (*entities.Patient, error)
+	GetByIDIncludingDeleted(ctx context.Context, id string) (*entities.Patient, error)
+	GetByExternalID(ctx context.Context, system, value string) (*entities.Patient, error)
 	Update(ctx context.Context, patient *entities.Patient) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]*entities.Patient, error)
-	Search(ctx context.Context, query string) ([]*entities.Patient, error)
-	Count(ctx context.Context) (int64, error)
+	Search(ctx context.Context, filter PatientFilter) ([]*entities.Patient, error)
+	Count(ctx context.Context, filter PatientFilter) (int64, error)
+	FindDuplicateCandidates(ctx context.Context, patient *entities.Patient, limit int) ([]*entities.Patient, error)
+	ListDuplicatePairs(ctx context.Context, limit, offset int) ([]PatientPair, error)
+}
+
+// PatientPair is a pair of records that share a date of birth and look alike
+// by name, phone or email; scoring is left to entities.MatchPatients.
+type PatientPair struct {
+	First  *entities.Patient
+	Second *entities.Patient
+}
+
+const (
+	PatientSortCreatedAt   = "created_at"
+	PatientSortUpdatedAt   = "updated_at"
+	PatientSortLastName    = "last_name"
+	PatientSortDateOfBirth = "date_of_birth"
+	PatientSortRelevance   = "relevance"
+)
+
+// PatientFilter narrows Search and Count. Soft-deleted patients are excluded
+// unless Deleted is set, which lists only them (the recycle bin); erased
+// patients are never listed. Query matches names (including
+// "Фамилия И.О." initials and Latin transliterations), phone and email;
+// QueryDate, when set, additionally matches the date of birth. Personal data
+// is stored encrypted, so names match by whole word, word prefix or enough
+// shared trigrams, the phone by any run of at least three digits, and email
+// exactly. The date of birth bounds are inclusive. Relevance sorting only
+// applies with a Query.
+type PatientFilter struct {
+	Query           string
+	QueryDate       *time.Time
+	Gender          string
+	DateOfBirthFrom *time.Time
+	DateOfBirthTo   *time.Time
+	SortBy          string
+	SortDesc        bool
+	Deleted         bool
+	Limit           int
+	Offset          int
 }
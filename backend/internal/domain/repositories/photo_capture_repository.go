@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type PhotoCaptureRepository interface {
+	Create(ctx context.Context, capture *entities.PhotoCapture) error
+	Delete(ctx context.Context, id string) error
+	// GetUnassignedByFilename returns the inbox entry for a stored photo, or
+	// nil when it has none.
+	GetUnassignedByFilename(ctx context.Context, filename string) (*entities.PhotoCapture, error)
+	// ListUnassigned lists the inbox, newest first.
+	ListUnassigned(ctx context.Context, limit, offset int) ([]*entities.PhotoCapture, error)
+	CountUnassigned(ctx context.Context) (int64, error)
+	// ListStale lists inbox entries captured before cutoff.
+	ListStale(ctx context.Context, cutoff time.Time, limit int) ([]*entities.PhotoCapture, error)
+	Assign(ctx context.Context, capture *entities.PhotoCapture) error
+	// ListByExaminationID lists the captures attached to an examination's
+	// images.
+	ListByExaminationID(ctx context.Context, examinationID string) ([]*entities.PhotoCapture, error)
+}
@@ -13,4 +13,5 @@ type ReportRepository interface {
 	Delete(ctx context.Context, id string) error
 	GetByExaminationID(ctx context.Context, examinationID string) (*entities.Report, error)
 	List(ctx context.Context, limit, offset int) ([]*entities.Report, error)
+	Count(ctx context.Context) (int64, error)
 }
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *entities.SigningKey) error
+	GetByID(ctx context.Context, id string) (*entities.SigningKey, error)
+	GetActiveByUserID(ctx context.Context, userID string) (*entities.SigningKey, error)
+	Revoke(ctx context.Context, id string) error
+}
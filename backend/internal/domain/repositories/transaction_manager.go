@@ -0,0 +1,11 @@
+package repositories
+
+import "context"
+
+// TransactionManager runs fn in one database transaction. Repository calls
+// made with the context passed to fn take part in it; it commits when fn
+// returns nil and rolls back otherwise. Nested calls join the outer
+// transaction.
+type TransactionManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
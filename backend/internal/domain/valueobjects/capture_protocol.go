@@ -0,0 +1,118 @@
+package valueobjects
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Examination types. General examinations have no capture protocol.
+const (
+	ExamTypeGeneral           = "general"
+	ExamTypeNailfoldStandard  = "nailfold_standard"
+	ExamTypeNailfoldScreening = "nailfold_screening"
+)
+
+// ParsePosition normalizes a finger position: the hand, L or R, followed by
+// the finger number from 1 (thumb) to 5 (little finger), e.g. "R4".
+func ParsePosition(s string) (string, error) {
+	position := strings.ToUpper(strings.TrimSpace(s))
+	if len(position) != 2 || (position[0] != 'L' && position[0] != 'R') || position[1] < '1' || position[1] > '5' {
+		return "", fmt.Errorf("invalid finger position %q", s)
+	}
+	return position, nil
+}
+
+var fingerNames = [...]string{"I", "II", "III", "IV", "V"}
+
+// PositionLabel names a finger position for reports.
+func PositionLabel(position string) string {
+	if len(position) != 2 || position[1] < '1' || position[1] > '5' {
+		return position
+	}
+	hand := "левая рука"
+	if position[0] == 'R' {
+		hand = "правая рука"
+	}
+	return fmt.Sprintf("%s палец, %s", fingerNames[position[1]-'1'], hand)
+}
+
+type PositionRequirement struct {
+	Position string
+	Images   int
+}
+
+// CaptureProtocol lists the finger positions an examination type must
+// capture before it can be analyzed, with the number of fields each.
+type CaptureProtocol struct {
+	ExamType     string
+	Requirements []PositionRequirement
+}
+
+var captureProtocols = map[string]CaptureProtocol{
+	ExamTypeGeneral: {ExamType: ExamTypeGeneral},
+	// Fingers 2-5 of both hands, four adjacent fields of each nailfold.
+	ExamTypeNailfoldStandard: {ExamType: ExamTypeNailfoldStandard, Requirements: fingers(4, "L2", "L3", "L4", "L5", "R2", "R3", "R4", "R5")},
+	// Ring fingers only, whose nailfolds are the most transparent.
+	ExamTypeNailfoldScreening: {ExamType: ExamTypeNailfoldScreening, Requirements: fingers(2, "L4", "R4")},
+}
+
+func fingers(images int, positions ...string) []PositionRequirement {
+	requirements := make([]PositionRequirement, 0, len(positions))
+	for _, position := range positions {
+		requirements = append(requirements, PositionRequirement{Position: position, Images: images})
+	}
+	return requirements
+}
+
+// LookupCaptureProtocol returns the protocol of an examination type; an
+// empty type is general.
+func LookupCaptureProtocol(examType string) (CaptureProtocol, error) {
+	if examType == "" {
+		examType = ExamTypeGeneral
+	}
+	protocol, ok := captureProtocols[examType]
+	if !ok {
+		return CaptureProtocol{}, fmt.Errorf("unknown examination type %q", examType)
+	}
+	return protocol, nil
+}
+
+// RequiresPositions reports whether images must be captured at a position.
+func (p CaptureProtocol) RequiresPositions() bool {
+	return len(p.Requirements) > 0
+}
+
+type PositionProgress struct {
+	Position string
+	Required int
+	Captured int
+}
+
+func (p PositionProgress) Complete() bool {
+	return p.Captured >= p.Required
+}
+
+// Progress compares the images captured per position with the protocol, in
+// protocol order.
+func (p CaptureProtocol) Progress(captured map[string]int) []PositionProgress {
+	progress := make([]PositionProgress, 0, len(p.Requirements))
+	for _, requirement := range p.Requirements {
+		progress = append(progress, PositionProgress{
+			Position: requirement.Position,
+			Required: requirement.Images,
+			Captured: captured[requirement.Position],
+		})
+	}
+	return progress
+}
+
+// Missing returns the positions that still lack images.
+func (p CaptureProtocol) Missing(captured map[string]int) []PositionProgress {
+	var missing []PositionProgress
+	for _, progress := range p.Progress(captured) {
+		if !progress.Complete() {
+			missing = append(missing, progress)
+		}
+	}
+	return missing
+}
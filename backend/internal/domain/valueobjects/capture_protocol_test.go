@@ -0,0 +1,96 @@
+package valueobjects
+
+import "testing"
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "right ring finger", value: "R4", want: "R4"},
+		{name: "lower case with spaces", value: " l2 ", want: "L2"},
+		{name: "thumb", value: "L1", want: "L1"},
+		{name: "empty", value: "", wantErr: true},
+		{name: "unknown hand", value: "X3", wantErr: true},
+		{name: "finger out of range", value: "R6", wantErr: true},
+		{name: "too long", value: "R44", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePosition(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParsePosition(%q) = %q, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParsePosition(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupCaptureProtocol(t *testing.T) {
+	protocol, err := LookupCaptureProtocol("")
+	if err != nil || protocol.ExamType != ExamTypeGeneral || protocol.RequiresPositions() {
+		t.Errorf("LookupCaptureProtocol(\"\") = %+v, %v, want general without positions", protocol, err)
+	}
+	protocol, err = LookupCaptureProtocol(ExamTypeNailfoldStandard)
+	if err != nil || len(protocol.Requirements) != 8 {
+		t.Errorf("LookupCaptureProtocol(standard) = %+v, %v, want 8 positions", protocol, err)
+	}
+	if _, err := LookupCaptureProtocol("dermoscopy"); err == nil {
+		t.Error("LookupCaptureProtocol(dermoscopy) succeeded, want error")
+	}
+}
+
+func TestCaptureProtocolMissing(t *testing.T) {
+	protocol, _ := LookupCaptureProtocol(ExamTypeNailfoldScreening)
+
+	tests := []struct {
+		name     string
+		captured map[string]int
+		want     []PositionProgress
+	}{
+		{
+			name: "nothing captured",
+			want: []PositionProgress{{Position: "L4", Required: 2}, {Position: "R4", Required: 2}},
+		},
+		{
+			name:     "one position short",
+			captured: map[string]int{"L4": 3, "R4": 1},
+			want:     []PositionProgress{{Position: "R4", Required: 2, Captured: 1}},
+		},
+		{
+			name:     "complete with extra positions",
+			captured: map[string]int{"L4": 2, "R4": 2, "R3": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := protocol.Missing(tt.captured)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Missing() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Missing()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPositionLabel(t *testing.T) {
+	if got := PositionLabel("R2"); got != "II палец, правая рука" {
+		t.Errorf("PositionLabel(R2) = %q", got)
+	}
+	if got := PositionLabel(""); got != "" {
+		t.Errorf("PositionLabel(\"\") = %q, want empty", got)
+	}
+}
@@ -1,5 +1,7 @@
 package valueobjects
 
+import "time"
+
 type ImageMetadata struct {
 	Width      int
 	Height     int
@@ -7,6 +9,13 @@ type ImageMetadata struct {
 	SizeBytes  int64
 	ColorSpace string
 	DPI        int
+
+	// Read from EXIF when the camera recorded them.
+	TakenAt      *time.Time
+	CameraMake   string
+	CameraModel  string
+	ExposureTime float64 // seconds
+	ISO          int
 }
 
 func NewImageMetadata(width, height int, format string, sizeBytes int64, colorSpace string, dpi int) *ImageMetadata {
@@ -0,0 +1,52 @@
+package valueobjects
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameQuery is free-text patient search input split into whole words and
+// initials, so "Иванов И.И." looks up the surname and narrows the result by
+// the first letters of the given and middle names.
+type NameQuery struct {
+	Terms    []string
+	Initials []string
+}
+
+func ParseNameQuery(value string) NameQuery {
+	var query NameQuery
+	tokens := strings.FieldsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ','
+	})
+
+	for _, token := range tokens {
+		if !isLetters(strings.ReplaceAll(token, ".", "")) {
+			query.Terms = append(query.Terms, strings.ToLower(token))
+			continue
+		}
+
+		for _, part := range strings.Split(token, ".") {
+			switch len([]rune(part)) {
+			case 0:
+			case 1:
+				query.Initials = append(query.Initials, strings.ToUpper(part))
+			default:
+				query.Terms = append(query.Terms, strings.ToLower(part))
+			}
+		}
+	}
+
+	return query
+}
+
+func isLetters(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != '-' {
+			return false
+		}
+	}
+	return true
+}
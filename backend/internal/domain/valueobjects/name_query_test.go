@@ -0,0 +1,64 @@
+package valueobjects
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNameQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		terms    []string
+		initials []string
+	}{
+		{
+			name:     "surname with joined initials",
+			value:    "Иванов И.И.",
+			terms:    []string{"иванов"},
+			initials: []string{"И", "И"},
+		},
+		{
+			name:     "initials before surname with spaces",
+			value:    "и. и. Иванов",
+			terms:    []string{"иванов"},
+			initials: []string{"И", "И"},
+		},
+		{
+			name:  "latin full name",
+			value: "Ivanov Ivan",
+			terms: []string{"ivanov", "ivan"},
+		},
+		{
+			name:  "double surname",
+			value: "Петров-Водкин",
+			terms: []string{"петров-водкин"},
+		},
+		{
+			name:  "email is kept whole",
+			value: "ivan@mail.ru",
+			terms: []string{"ivan@mail.ru"},
+		},
+		{
+			name:  "date is kept whole",
+			value: "15.05.1990",
+			terms: []string{"15.05.1990"},
+		},
+		{
+			name:  "empty",
+			value: "  ,  ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseNameQuery(tt.value)
+			if !reflect.DeepEqual(got.Terms, tt.terms) {
+				t.Errorf("Terms = %q, want %q", got.Terms, tt.terms)
+			}
+			if !reflect.DeepEqual(got.Initials, tt.initials) {
+				t.Errorf("Initials = %q, want %q", got.Initials, tt.initials)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package auth
+
+import "context"
+
+type claimsKey struct{}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the authenticated user's claims, or nil for an
+// anonymous request.
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*Claims)
+	return claims
+}
+
+// OrganizationID returns the caller's organization, or "" when ctx carries
+// no claims, as for background jobs that act across organizations.
+func OrganizationID(ctx context.Context) string {
+	if claims := ClaimsFromContext(ctx); claims != nil {
+		return claims.OrganizationID
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+func TestTokenIssuer(t *testing.T) {
+	user := &entities.User{ID: "user-1", OrganizationID: "org-1", Role: entities.RoleDoctor}
+	issued := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	issuer := NewTokenIssuer([]byte("secret"), time.Hour)
+	issuer.now = func() time.Time { return issued }
+	token, err := issuer.Issue(user)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	encoded, signature, _ := strings.Cut(token, ".")
+	tests := []struct {
+		name    string
+		token   string
+		secret  string
+		at      time.Time
+		wantErr error
+	}{
+		{name: "valid", token: token, secret: "secret", at: issued.Add(59 * time.Minute)},
+		{name: "expired", token: token, secret: "secret", at: issued.Add(time.Hour), wantErr: ErrTokenExpired},
+		{name: "other secret", token: token, secret: "other", at: issued, wantErr: ErrInvalidToken},
+		{name: "tampered payload", token: encoded + "x." + signature, secret: "secret", at: issued, wantErr: ErrInvalidToken},
+		{name: "no signature", token: encoded, secret: "secret", at: issued, wantErr: ErrInvalidToken},
+		{name: "empty", token: "", secret: "secret", at: issued, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewTokenIssuer([]byte(tt.secret), time.Hour)
+			parser.now = func() time.Time { return tt.at }
+
+			claims, err := parser.Parse(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (claims.UserID != user.ID || claims.OrganizationID != user.OrganizationID || claims.Role != user.Role) {
+				t.Errorf("Parse() = %+v, want user %s organization %s role %s", claims, user.ID, user.OrganizationID, user.Role)
+			}
+		})
+	}
+}
+
+func TestTokenIssuerRequiresOrganization(t *testing.T) {
+	issuer := NewTokenIssuer([]byte("secret"), time.Hour)
+	token, err := issuer.Issue(&entities.User{ID: "user-1", Role: entities.RoleAdmin})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if _, err := issuer.Parse(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
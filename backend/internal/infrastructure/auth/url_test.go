@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	issued := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewURLSigner([]byte("secret"), 5*time.Minute)
+	signer.now = func() time.Time { return issued }
+
+	signed := signer.Sign("/api/images/img-1/thumbnail?size=preview")
+	if !strings.HasPrefix(signed, "/api/images/img-1/thumbnail?") {
+		t.Fatalf("Sign() = %q", signed)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		secret  string
+		at      time.Time
+		wantErr error
+	}{
+		{name: "valid", url: signed, secret: "secret", at: issued.Add(4 * time.Minute)},
+		{name: "expired", url: signed, secret: "secret", at: issued.Add(5 * time.Minute), wantErr: ErrURLExpired},
+		{name: "other secret", url: signed, secret: "other", at: issued, wantErr: ErrInvalidSignature},
+		{name: "other image", url: strings.Replace(signed, "img-1", "img-2", 1), secret: "secret", at: issued, wantErr: ErrInvalidSignature},
+		{name: "other size", url: strings.Replace(signed, "size=preview", "size=thumbnail", 1), secret: "secret", at: issued, wantErr: ErrInvalidSignature},
+		{name: "extended expiry", url: strings.Replace(signed, "expires=", "expires=9", 1), secret: "secret", at: issued, wantErr: ErrInvalidSignature},
+		{name: "unsigned", url: "/api/images/img-1/thumbnail?size=preview", secret: "secret", at: issued, wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewURLSigner([]byte(tt.secret), 5*time.Minute)
+			verifier.now = func() time.Time { return tt.at }
+
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verifier.Verify(u); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestURLSignatureIsNotAToken(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"), time.Hour)
+	u, _ := url.Parse(signer.Sign("/api/images/img-1/file"))
+	token := u.Query().Get("expires") + "." + u.Query().Get("signature")
+	if _, err := NewTokenIssuer([]byte("secret"), time.Hour).Parse(token); err == nil {
+		t.Error("Parse() accepted a URL signature as a token")
+	}
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS examination_images;
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS analyses;
+DROP TABLE IF EXISTS images;
+DROP TABLE IF EXISTS examinations;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS patients;
+DROP TABLE IF EXISTS users;
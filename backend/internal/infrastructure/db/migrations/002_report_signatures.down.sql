@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_reports_status;
+
+ALTER TABLE reports DROP COLUMN IF EXISTS signature;
+ALTER TABLE reports DROP COLUMN IF EXISTS signature_key_id;
+ALTER TABLE reports DROP COLUMN IF EXISTS signed_at;
+ALTER TABLE reports DROP COLUMN IF EXISTS signed_by;
+ALTER TABLE reports DROP COLUMN IF EXISTS status;
+
+DROP TABLE IF EXISTS signing_keys;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    algorithm VARCHAR(50) NOT NULL CHECK (algorithm IN ('ed25519')),
+    public_key BYTEA NOT NULL,
+    private_key BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_signing_keys_user_id ON signing_keys(user_id);
+CREATE UNIQUE INDEX idx_signing_keys_active_user ON signing_keys(user_id) WHERE revoked_at IS NULL;
+
+ALTER TABLE reports ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'signed'));
+ALTER TABLE reports ADD COLUMN signed_by UUID REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE reports ADD COLUMN signed_at TIMESTAMP;
+ALTER TABLE reports ADD COLUMN signature_key_id UUID REFERENCES signing_keys(id) ON DELETE RESTRICT;
+ALTER TABLE reports ADD COLUMN signature BYTEA;
+
+CREATE INDEX idx_reports_status ON reports(status);
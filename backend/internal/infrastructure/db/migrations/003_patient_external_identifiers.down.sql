@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_patients_external_identifier;
+
+ALTER TABLE patients DROP COLUMN IF EXISTS external_id;
+ALTER TABLE patients DROP COLUMN IF EXISTS external_system;
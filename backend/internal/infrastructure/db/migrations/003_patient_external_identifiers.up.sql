@@ -0,0 +1,5 @@
+ALTER TABLE patients ADD COLUMN external_system VARCHAR(255);
+ALTER TABLE patients ADD COLUMN external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_patients_external_identifier ON patients(external_system, external_id)
+    WHERE external_id IS NOT NULL;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS hl7_messages (
+    id UUID PRIMARY KEY,
+    report_id UUID NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    control_id VARCHAR(20) NOT NULL UNIQUE,
+    message_type VARCHAR(20) NOT NULL,
+    destination VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    ack_code VARCHAR(2) NOT NULL DEFAULT '',
+    ack_message TEXT NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at TIMESTAMP
+);
+
+CREATE INDEX idx_hl7_messages_report_id ON hl7_messages(report_id);
+CREATE INDEX idx_hl7_messages_status ON hl7_messages(status);
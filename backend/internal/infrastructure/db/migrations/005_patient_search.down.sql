@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_patients_phone_trgm;
+DROP INDEX IF EXISTS idx_patients_email_trgm;
+DROP INDEX IF EXISTS idx_patients_search_name_trgm;
+DROP INDEX IF EXISTS idx_patients_search_vector;
+
+ALTER TABLE patients DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE patients DROP COLUMN IF EXISTS search_name;
+
+DROP FUNCTION IF EXISTS capillary_translit(TEXT);
+
+-- pg_trgm is left installed; other databases on the server may use it.
@@ -0,0 +1,36 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Lower-cased Latin spelling of Russian names (ICAO 9303 with common
+-- simplifications) so "Ivanov", "Ivanof" and "Иванов" compare equal or close.
+-- Latin input passes through the same normalisation.
+CREATE OR REPLACE FUNCTION capillary_translit(value TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$
+    SELECT replace(
+        translate(
+            replace(replace(replace(replace(replace(replace(replace(replace(
+                lower(value),
+                'щ', 'shch'), 'ж', 'zh'), 'ц', 'ts'), 'ч', 'ch'),
+                'ш', 'sh'), 'ю', 'iu'), 'я', 'ia'), 'х', 'h'),
+            'абвгдеёзийклмнопрстуфыэyjъь',
+            'abvgdeeziiklmnoprstufieii'
+        ),
+        'kh', 'h'
+    )
+$$;
+
+ALTER TABLE patients ADD COLUMN search_name TEXT GENERATED ALWAYS AS (
+    capillary_translit(last_name || ' ' || first_name || ' ' || COALESCE(middle_name, ''))
+) STORED;
+
+ALTER TABLE patients ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', last_name), 'A') ||
+    setweight(to_tsvector('russian', first_name || ' ' || COALESCE(middle_name, '')), 'B') ||
+    setweight(to_tsvector('english', capillary_translit(last_name)), 'A') ||
+    setweight(to_tsvector('english', capillary_translit(first_name || ' ' || COALESCE(middle_name, ''))), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(email, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_patients_search_vector ON patients USING GIN (search_vector);
+CREATE INDEX idx_patients_search_name_trgm ON patients USING GIN (search_name gin_trgm_ops);
+CREATE INDEX idx_patients_email_trgm ON patients USING GIN (email gin_trgm_ops);
+CREATE INDEX idx_patients_phone_trgm ON patients USING GIN (phone gin_trgm_ops);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS patient_merges (
+    id UUID PRIMARY KEY,
+    survivor_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    merged_id UUID NOT NULL,
+    merged_by UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    merged_patient JSONB NOT NULL,
+    examination_ids UUID[] NOT NULL DEFAULT '{}',
+    image_ids UUID[] NOT NULL DEFAULT '{}',
+    report_ids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_patient_merges_survivor_id ON patient_merges(survivor_id);
+CREATE INDEX idx_patient_merges_merged_id ON patient_merges(merged_id);
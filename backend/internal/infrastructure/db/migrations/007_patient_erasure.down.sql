@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS patient_erasures;
+
+DROP INDEX IF EXISTS idx_patients_external_identifier;
+CREATE UNIQUE INDEX idx_patients_external_identifier ON patients(external_system, external_id)
+    WHERE external_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_patients_deleted_at;
+
+ALTER TABLE patients DROP COLUMN IF EXISTS legal_hold_reason;
+ALTER TABLE patients DROP COLUMN IF EXISTS legal_hold_until;
+ALTER TABLE patients DROP COLUMN IF EXISTS erased_at;
+ALTER TABLE patients DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,28 @@
+ALTER TABLE patients ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE patients ADD COLUMN erased_at TIMESTAMP;
+ALTER TABLE patients ADD COLUMN legal_hold_until TIMESTAMP;
+ALTER TABLE patients ADD COLUMN legal_hold_reason TEXT;
+
+CREATE INDEX idx_patients_deleted_at ON patients(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A soft-deleted patient must not block re-registration under the same
+-- external identifier.
+DROP INDEX idx_patients_external_identifier;
+CREATE UNIQUE INDEX idx_patients_external_identifier ON patients(external_system, external_id)
+    WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+
+-- Erasure audit records hold no personal data and outlive the patient row,
+-- so patient_id is intentionally not a foreign key.
+CREATE TABLE IF NOT EXISTS patient_erasures (
+    id UUID PRIMARY KEY,
+    patient_id UUID NOT NULL,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    reason TEXT NOT NULL,
+    mode VARCHAR(20) NOT NULL CHECK (mode IN ('anonymize', 'delete')),
+    examination_count INTEGER NOT NULL DEFAULT 0,
+    image_count INTEGER NOT NULL DEFAULT 0,
+    files_deleted INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_patient_erasures_patient_id ON patient_erasures(patient_id);
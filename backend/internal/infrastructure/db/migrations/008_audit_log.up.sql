@@ -0,0 +1,39 @@
+-- audit_log is a hash-chained, append-only record of who did what; the
+-- triggers below reject UPDATE, DELETE and TRUNCATE.
+--
+-- There is no down migration: dropping the table would destroy the audit
+-- trail it exists to preserve. Retire it by hand if it ever must go.
+CREATE TABLE IF NOT EXISTS audit_log (
+    sequence BIGINT PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    actor_id UUID,
+    actor_role VARCHAR(50),
+    action VARCHAR(50) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(255),
+    route VARCHAR(255),
+    status INTEGER NOT NULL DEFAULT 0,
+    client_ip VARCHAR(64),
+    request_id VARCHAR(64),
+    created_at TIMESTAMP NOT NULL,
+    prev_hash CHAR(64),
+    hash CHAR(64) NOT NULL
+);
+
+CREATE INDEX idx_audit_log_actor_id ON audit_log(actor_id);
+CREATE INDEX idx_audit_log_resource ON audit_log(resource_type, resource_id);
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,43 @@
+-- Personal data moves into the envelope-encrypted pii column, written by the
+-- application. Existing rows keep their plaintext columns until
+-- `piikey reencrypt` seals them and clears the plaintext.
+--
+-- There is no down migration: sealed rows can only be decrypted with the
+-- keyring, which SQL has no access to, and the plaintext search columns
+-- dropped below cannot be rebuilt from them.
+ALTER TABLE patients ADD COLUMN pii BYTEA;
+ALTER TABLE patients ADD COLUMN pii_key_id VARCHAR(64);
+ALTER TABLE patients ADD COLUMN birth_year SMALLINT;
+ALTER TABLE patients ADD COLUMN dob_index CHAR(32);
+ALTER TABLE patients ADD COLUMN phone_index CHAR(32);
+ALTER TABLE patients ADD COLUMN email_index CHAR(32);
+ALTER TABLE patients ADD COLUMN name_tokens TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE patients ADD COLUMN search_tokens TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE patients SET birth_year = EXTRACT(YEAR FROM date_of_birth);
+
+ALTER TABLE patients ALTER COLUMN first_name DROP NOT NULL;
+ALTER TABLE patients ALTER COLUMN last_name DROP NOT NULL;
+ALTER TABLE patients ALTER COLUMN date_of_birth DROP NOT NULL;
+
+-- Plaintext search structures would leak the encrypted data. This drops the
+-- full-text and trigram search of 005: names are matched by whole word and
+-- prefix blind indexes instead, and 019 restores typo tolerance with trigram
+-- blind indexes.
+DROP INDEX IF EXISTS idx_patients_search_vector;
+DROP INDEX IF EXISTS idx_patients_search_name_trgm;
+DROP INDEX IF EXISTS idx_patients_email_trgm;
+DROP INDEX IF EXISTS idx_patients_phone_trgm;
+DROP INDEX IF EXISTS idx_patients_last_name;
+DROP INDEX IF EXISTS idx_patients_email;
+DROP INDEX IF EXISTS idx_patients_phone;
+ALTER TABLE patients DROP COLUMN search_vector;
+ALTER TABLE patients DROP COLUMN search_name;
+
+CREATE INDEX idx_patients_pii_key_id ON patients(pii_key_id);
+CREATE INDEX idx_patients_birth_year ON patients(birth_year);
+CREATE INDEX idx_patients_dob_index ON patients(dob_index);
+CREATE INDEX idx_patients_phone_index ON patients(phone_index);
+CREATE INDEX idx_patients_email_index ON patients(email_index);
+CREATE INDEX idx_patients_name_tokens ON patients USING GIN (name_tokens);
+CREATE INDEX idx_patients_search_tokens ON patients USING GIN (search_tokens);
@@ -0,0 +1,47 @@
+DROP POLICY IF EXISTS tenant_isolation ON patient_merges;
+DROP POLICY IF EXISTS tenant_isolation ON signing_keys;
+DROP POLICY IF EXISTS tenant_isolation ON hl7_messages;
+DROP POLICY IF EXISTS tenant_isolation ON reports;
+DROP POLICY IF EXISTS tenant_isolation ON analyses;
+DROP POLICY IF EXISTS tenant_isolation ON examination_images;
+DROP POLICY IF EXISTS tenant_isolation ON images;
+DROP POLICY IF EXISTS tenant_isolation ON patient_erasures;
+DROP POLICY IF EXISTS tenant_isolation ON examinations;
+DROP POLICY IF EXISTS tenant_isolation ON devices;
+DROP POLICY IF EXISTS tenant_isolation ON patients;
+DROP POLICY IF EXISTS tenant_isolation ON users;
+DROP POLICY IF EXISTS tenant_isolation ON organizations;
+
+ALTER TABLE patient_merges DISABLE ROW LEVEL SECURITY;
+ALTER TABLE signing_keys DISABLE ROW LEVEL SECURITY;
+ALTER TABLE hl7_messages DISABLE ROW LEVEL SECURITY;
+ALTER TABLE reports DISABLE ROW LEVEL SECURITY;
+ALTER TABLE analyses DISABLE ROW LEVEL SECURITY;
+ALTER TABLE examination_images DISABLE ROW LEVEL SECURITY;
+ALTER TABLE images DISABLE ROW LEVEL SECURITY;
+ALTER TABLE patient_erasures DISABLE ROW LEVEL SECURITY;
+ALTER TABLE examinations DISABLE ROW LEVEL SECURITY;
+ALTER TABLE devices DISABLE ROW LEVEL SECURITY;
+ALTER TABLE patients DISABLE ROW LEVEL SECURITY;
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_organization_id();
+
+-- The capillary_app role is cluster-wide and may be used by other
+-- databases, so only this database's grants are removed.
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM capillary_app;
+REVOKE SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public FROM capillary_app;
+REVOKE USAGE ON SCHEMA public FROM capillary_app;
+
+DROP INDEX IF EXISTS idx_patients_external_identifier;
+CREATE UNIQUE INDEX idx_patients_external_identifier ON patients(external_system, external_id)
+    WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+
+ALTER TABLE audit_log DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE patient_erasures DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE examinations DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE devices DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE patients DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE users DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,113 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Data recorded before organizations existed belongs to one default clinic.
+INSERT INTO organizations (id, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default clinic')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE users ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+ALTER TABLE patients ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+ALTER TABLE devices ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+ALTER TABLE examinations ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+ALTER TABLE patient_erasures ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+-- The audit chain spans organizations; entries without one predate them.
+ALTER TABLE audit_log ADD COLUMN organization_id UUID;
+
+UPDATE users SET organization_id = '00000000-0000-0000-0000-000000000001';
+UPDATE patients SET organization_id = '00000000-0000-0000-0000-000000000001';
+UPDATE devices SET organization_id = '00000000-0000-0000-0000-000000000001';
+UPDATE examinations SET organization_id = '00000000-0000-0000-0000-000000000001';
+UPDATE patient_erasures SET organization_id = '00000000-0000-0000-0000-000000000001';
+
+ALTER TABLE users ALTER COLUMN organization_id SET NOT NULL;
+ALTER TABLE patients ALTER COLUMN organization_id SET NOT NULL;
+ALTER TABLE devices ALTER COLUMN organization_id SET NOT NULL;
+ALTER TABLE examinations ALTER COLUMN organization_id SET NOT NULL;
+ALTER TABLE patient_erasures ALTER COLUMN organization_id SET NOT NULL;
+
+CREATE INDEX idx_users_organization_id ON users(organization_id);
+CREATE INDEX idx_patients_organization_id ON patients(organization_id);
+CREATE INDEX idx_devices_organization_id ON devices(organization_id);
+CREATE INDEX idx_examinations_organization_id ON examinations(organization_id, created_at DESC);
+CREATE INDEX idx_patient_erasures_organization_id ON patient_erasures(organization_id);
+CREATE INDEX idx_audit_log_organization_id ON audit_log(organization_id, sequence DESC);
+
+-- Two clinics may use the same external identifiers.
+DROP INDEX idx_patients_external_identifier;
+CREATE UNIQUE INDEX idx_patients_external_identifier ON patients(organization_id, external_system, external_id)
+    WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+
+-- Row-level security. The API switches each authenticated request's
+-- connection to capillary_app and sets app.organization_id; the policies
+-- below then hide every other organization's rows even if a query forgets
+-- to filter. The owner role the services log in as is not subject to them,
+-- which keeps logins, the worker and maintenance commands working.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'capillary_app') THEN
+        CREATE ROLE capillary_app NOLOGIN;
+    END IF;
+END
+$$;
+
+GRANT capillary_app TO CURRENT_USER;
+GRANT USAGE ON SCHEMA public TO capillary_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO capillary_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO capillary_app;
+
+CREATE OR REPLACE FUNCTION app_organization_id() RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.organization_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE organizations ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organizations USING (id = app_organization_id());
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users USING (organization_id = app_organization_id());
+
+ALTER TABLE patients ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON patients USING (organization_id = app_organization_id());
+
+ALTER TABLE devices ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON devices USING (organization_id = app_organization_id());
+
+ALTER TABLE examinations ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON examinations USING (organization_id = app_organization_id());
+
+ALTER TABLE patient_erasures ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON patient_erasures USING (organization_id = app_organization_id());
+
+-- Child rows are visible when their parent is; the subqueries are
+-- themselves filtered by the parent's policy.
+ALTER TABLE images ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON images
+    USING (EXISTS (SELECT 1 FROM examinations e WHERE e.id = examination_id));
+
+ALTER TABLE examination_images ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON examination_images
+    USING (EXISTS (SELECT 1 FROM examinations e WHERE e.id = examination_id));
+
+ALTER TABLE analyses ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON analyses
+    USING (EXISTS (SELECT 1 FROM examinations e WHERE e.id = examination_id));
+
+ALTER TABLE reports ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON reports
+    USING (EXISTS (SELECT 1 FROM examinations e WHERE e.id = examination_id));
+
+ALTER TABLE hl7_messages ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON hl7_messages
+    USING (EXISTS (SELECT 1 FROM reports r WHERE r.id = report_id));
+
+ALTER TABLE signing_keys ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON signing_keys
+    USING (EXISTS (SELECT 1 FROM users u WHERE u.id = user_id));
+
+ALTER TABLE patient_merges ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON patient_merges
+    USING (EXISTS (SELECT 1 FROM patients p WHERE p.id = survivor_id));
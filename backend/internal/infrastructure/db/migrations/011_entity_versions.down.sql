@@ -0,0 +1,2 @@
+ALTER TABLE reports DROP COLUMN IF EXISTS version;
+ALTER TABLE patients DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Row versions for optimistic concurrency: an update only succeeds against
+-- the version the client read, and bumps it.
+ALTER TABLE patients ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE reports ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
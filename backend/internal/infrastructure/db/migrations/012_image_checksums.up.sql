@@ -0,0 +1,4 @@
+-- Photos are stored under their SHA-256. Images recorded before that keep
+-- a NULL checksum and are read unverified.
+ALTER TABLE images ADD COLUMN sha256 CHAR(64);
+CREATE INDEX idx_images_sha256 ON images(sha256) WHERE sha256 IS NOT NULL;
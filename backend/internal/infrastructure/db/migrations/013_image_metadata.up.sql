@@ -0,0 +1,3 @@
+-- Colour space, DPI and EXIF details read from the photo when it is
+-- attached; dimensions, type and size keep their own columns.
+ALTER TABLE images ADD COLUMN metadata JSONB;
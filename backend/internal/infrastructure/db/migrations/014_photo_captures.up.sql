@@ -0,0 +1,23 @@
+-- The photo inbox: photos captured over the device WebSocket, kept until
+-- they are attached to an examination. Assigned captures stay as a record of
+-- who took the photo and on which device. organization_id is NULL for
+-- captures made over an unauthenticated connection.
+CREATE TABLE IF NOT EXISTS photo_captures (
+    id UUID PRIMARY KEY,
+    organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT,
+    filename VARCHAR(255) NOT NULL,
+    sha256 CHAR(64),
+    file_size BIGINT NOT NULL DEFAULT 0,
+    mime_type VARCHAR(100) NOT NULL,
+    captured_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    device_id VARCHAR(255),
+    image_id UUID REFERENCES images(id) ON DELETE CASCADE,
+    captured_at TIMESTAMP NOT NULL,
+    assigned_at TIMESTAMP
+);
+
+CREATE INDEX idx_photo_captures_inbox ON photo_captures(organization_id, captured_at DESC) WHERE image_id IS NULL;
+CREATE INDEX idx_photo_captures_filename ON photo_captures(filename);
+
+ALTER TABLE photo_captures ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON photo_captures USING (organization_id = app_organization_id());
@@ -0,0 +1,2 @@
+ALTER TABLE images DROP COLUMN IF EXISTS position;
+ALTER TABLE examinations DROP COLUMN IF EXISTS exam_type;
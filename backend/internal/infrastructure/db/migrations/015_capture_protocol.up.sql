@@ -0,0 +1,6 @@
+-- The examination type selects the capture protocol; examinations created
+-- before protocols existed have none.
+ALTER TABLE examinations ADD COLUMN exam_type VARCHAR(50) NOT NULL DEFAULT 'general';
+
+-- Finger the image was taken of, e.g. R4.
+ALTER TABLE images ADD COLUMN position VARCHAR(2);
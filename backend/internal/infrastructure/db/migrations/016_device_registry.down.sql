@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_devices_device_id;
+CREATE INDEX idx_devices_device_id ON devices(device_id);
+ALTER TABLE devices ADD CONSTRAINT devices_device_id_key UNIQUE (device_id);
@@ -0,0 +1,5 @@
+-- Devices register themselves per clinic, so two clinics may report the
+-- same device ID.
+ALTER TABLE devices DROP CONSTRAINT IF EXISTS devices_device_id_key;
+DROP INDEX IF EXISTS idx_devices_device_id;
+CREATE UNIQUE INDEX idx_devices_device_id ON devices(organization_id, device_id);
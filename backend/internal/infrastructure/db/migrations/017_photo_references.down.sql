@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS photo_references(TEXT);
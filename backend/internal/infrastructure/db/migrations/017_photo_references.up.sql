@@ -0,0 +1,10 @@
+-- Identical photos share one stored file across organizations (see 012), so
+-- whether a file is still in use has to be decided over every organization's
+-- images and captures. The function runs as its owner, whom row-level
+-- security does not restrict, and reveals nothing but the count.
+CREATE OR REPLACE FUNCTION photo_references(photo_filename TEXT) RETURNS BIGINT AS $$
+    SELECT (SELECT COUNT(*) FROM images WHERE filename = photo_filename)
+         + (SELECT COUNT(*) FROM photo_captures WHERE filename = photo_filename)
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+GRANT EXECUTE ON FUNCTION photo_references(TEXT) TO capillary_app;
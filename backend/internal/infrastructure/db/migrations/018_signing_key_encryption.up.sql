@@ -0,0 +1,8 @@
+-- Private signing keys are sealed with the PII keyring, like patient data;
+-- private_key_id names the key they are sealed under. Keys created before
+-- this keep their plaintext seed and a NULL private_key_id until
+-- `piikey reencrypt` seals them.
+--
+-- There is no down migration: sealed keys can only be turned back into
+-- plaintext with the keyring, which SQL has no access to.
+ALTER TABLE signing_keys ADD COLUMN private_key_id VARCHAR(64);
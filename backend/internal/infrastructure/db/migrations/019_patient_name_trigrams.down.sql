@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_patients_name_trigrams;
+ALTER TABLE patients DROP COLUMN IF EXISTS name_trigrams;
@@ -0,0 +1,8 @@
+-- Typo-tolerant patient search, lost when 009 dropped the plaintext
+-- search_name trigram index, comes back over blind indexes: name_trigrams
+-- holds the keyed hashes of the trigrams of every transliterated name word.
+-- NULL marks rows written before this migration; `piikey reencrypt` fills
+-- them in.
+ALTER TABLE patients ADD COLUMN name_trigrams TEXT[];
+
+CREATE INDEX idx_patients_name_trigrams ON patients USING GIN (name_trigrams);
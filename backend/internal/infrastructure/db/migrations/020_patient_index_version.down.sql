@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_patients_index_version;
+ALTER TABLE patients DROP COLUMN IF EXISTS index_version;
@@ -0,0 +1,6 @@
+-- index_version records which set of blind indexes a row was written with,
+-- so `piikey reencrypt` can rebuild rows written before new ones, such as
+-- the partial phone tokens, were introduced.
+ALTER TABLE patients ADD COLUMN index_version SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_patients_index_version ON patients(index_version);
@@ -0,0 +1,9 @@
+// Package migrations embeds the versioned schema changes. Each version has
+// an NNN_name.up.sql file and, unless it cannot be reverted without losing
+// data, an NNN_name.down.sql file.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -26,7 +26,7 @@ func (r *AnalysisRepositoryImpl) Create(ctx context.Context, analysis *entities.
 		INSERT INTO analyses (id, examination_id, image_id, status, metrics, error_message, created_at, updated_at, completed_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = scoped(ctx, r.db).ExecContext(ctx, query,
 		analysis.ID, analysis.ExaminationID, analysis.ImageID, analysis.Status,
 		metricsJSON, analysis.ErrorMessage, analysis.CreatedAt, analysis.UpdatedAt, analysis.CompletedAt)
 	return err
@@ -35,12 +35,12 @@ func (r *AnalysisRepositoryImpl) Create(ctx context.Context, analysis *entities.
 func (r *AnalysisRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Analysis, error) {
 	query := `
 		SELECT id, examination_id, image_id, status, metrics, error_message, created_at, updated_at, completed_at
-		FROM analyses WHERE id = $1
+		FROM analyses WHERE id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))
 	`
 	analysis := &entities.Analysis{}
 	var metricsJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, id, tenantID(ctx)).Scan(
 		&analysis.ID, &analysis.ExaminationID, &analysis.ImageID, &analysis.Status,
 		&metricsJSON, &analysis.ErrorMessage, &analysis.CreatedAt, &analysis.UpdatedAt, &analysis.CompletedAt)
 
@@ -73,7 +73,7 @@ func (r *AnalysisRepositoryImpl) Update(ctx context.Context, analysis *entities.
 		SET status = $2, metrics = $3, error_message = $4, updated_at = $5, completed_at = $6
 		WHERE id = $1
 	`
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = scoped(ctx, r.db).ExecContext(ctx, query,
 		analysis.ID, analysis.Status, metricsJSON, analysis.ErrorMessage,
 		analysis.UpdatedAt, analysis.CompletedAt)
 	return err
@@ -82,9 +82,10 @@ func (r *AnalysisRepositoryImpl) Update(ctx context.Context, analysis *entities.
 func (r *AnalysisRepositoryImpl) GetByExaminationID(ctx context.Context, examinationID string) ([]*entities.Analysis, error) {
 	query := `
 		SELECT id, examination_id, image_id, status, metrics, error_message, created_at, updated_at, completed_at
-		FROM analyses WHERE examination_id = $1 ORDER BY created_at DESC
+		FROM analyses WHERE examination_id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))
+		ORDER BY created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, examinationID)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, examinationID, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -117,12 +118,12 @@ func (r *AnalysisRepositoryImpl) GetByExaminationID(ctx context.Context, examina
 func (r *AnalysisRepositoryImpl) GetByImageID(ctx context.Context, imageID string) (*entities.Analysis, error) {
 	query := `
 		SELECT id, examination_id, image_id, status, metrics, error_message, created_at, updated_at, completed_at
-		FROM analyses WHERE image_id = $1
+		FROM analyses WHERE image_id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))
 	`
 	analysis := &entities.Analysis{}
 	var metricsJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, imageID).Scan(
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, imageID, tenantID(ctx)).Scan(
 		&analysis.ID, &analysis.ExaminationID, &analysis.ImageID, &analysis.Status,
 		&metricsJSON, &analysis.ErrorMessage, &analysis.CreatedAt, &analysis.UpdatedAt, &analysis.CompletedAt)
 
@@ -147,9 +148,10 @@ func (r *AnalysisRepositoryImpl) GetByImageID(ctx context.Context, imageID strin
 func (r *AnalysisRepositoryImpl) GetByStatus(ctx context.Context, status entities.AnalysisStatus) ([]*entities.Analysis, error) {
 	query := `
 		SELECT id, examination_id, image_id, status, metrics, error_message, created_at, updated_at, completed_at
-		FROM analyses WHERE status = $1 ORDER BY created_at ASC
+		FROM analyses WHERE status = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))
+		ORDER BY created_at ASC
 	`
-	rows, err := r.db.QueryContext(ctx, query, status)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, status, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -182,9 +184,10 @@ func (r *AnalysisRepositoryImpl) GetByStatus(ctx context.Context, status entitie
 func (r *AnalysisRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*entities.Analysis, error) {
 	query := `
 		SELECT id, examination_id, image_id, status, metrics, error_message, created_at, updated_at, completed_at
-		FROM analyses ORDER BY created_at DESC LIMIT $1 OFFSET $2
+		FROM analyses WHERE ($3 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($3, '')::uuid))
+		ORDER BY created_at DESC LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, limit, offset, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
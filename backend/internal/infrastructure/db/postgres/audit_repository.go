@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+)
+
+// auditAppendLock is the transaction-level advisory lock key serializing
+// appends to the audit hash chain.
+const auditAppendLock = 0x617564
+
+const auditColumns = `
+	sequence, id, COALESCE(actor_id::text, ''), COALESCE(actor_role, ''), action, resource_type,
+	COALESCE(resource_id, ''), COALESCE(route, ''), status, COALESCE(client_ip, ''),
+	COALESCE(request_id, ''), created_at, COALESCE(prev_hash, ''), hash, COALESCE(organization_id::text, '')`
+
+type AuditRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepositoryImpl {
+	return &AuditRepositoryImpl{db: db}
+}
+
+func (r *AuditRepositoryImpl) Append(ctx context.Context, entry *entities.AuditEntry) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditAppendLock); err != nil {
+			return err
+		}
+
+		var sequence int64
+		var prevHash string
+		err := tx.QueryRowContext(ctx, `SELECT sequence, hash FROM audit_log ORDER BY sequence DESC LIMIT 1`).
+			Scan(&sequence, &prevHash)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		entry.Seal(sequence+1, prevHash)
+
+		query := `
+			INSERT INTO audit_log (sequence, id, actor_id, actor_role, action, resource_type, resource_id,
+			                       route, status, client_ip, request_id, created_at, prev_hash, hash, organization_id)
+			VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, ''), $5, $6, NULLIF($7, ''),
+			        NULLIF($8, ''), $9, NULLIF($10, ''), NULLIF($11, ''), $12, NULLIF($13, ''), $14, NULLIF($15, '')::uuid)
+		`
+		_, err = tx.ExecContext(ctx, query,
+			entry.Sequence, entry.ID, entry.ActorID, entry.ActorRole, entry.Action, entry.ResourceType, entry.ResourceID,
+			entry.Route, entry.Status, entry.ClientIP, entry.RequestID, entry.CreatedAt, entry.PrevHash, entry.Hash,
+			entry.OrganizationID)
+		return err
+	})
+}
+
+func (r *AuditRepositoryImpl) List(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	where, args := auditWhere(tenantID(ctx), filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT %s FROM audit_log %s ORDER BY sequence DESC LIMIT $%d OFFSET $%d`,
+		auditColumns, where, len(args)-1, len(args))
+	return r.queryEntries(ctx, query, args...)
+}
+
+func (r *AuditRepositoryImpl) Count(ctx context.Context, filter repositories.AuditFilter) (int64, error) {
+	var count int64
+	where, args := auditWhere(tenantID(ctx), filter)
+	err := scoped(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log `+where, args...).Scan(&count)
+	return count, err
+}
+
+func (r *AuditRepositoryImpl) ListAfter(ctx context.Context, afterSequence int64, limit int) ([]*entities.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE sequence > $1 ORDER BY sequence LIMIT $2`
+	return r.queryEntries(ctx, query, afterSequence, limit)
+}
+
+func (r *AuditRepositoryImpl) queryEntries(ctx context.Context, query string, args ...interface{}) ([]*entities.AuditEntry, error) {
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*entities.AuditEntry
+	for rows.Next() {
+		entry := &entities.AuditEntry{}
+		err := rows.Scan(&entry.Sequence, &entry.ID, &entry.ActorID, &entry.ActorRole, &entry.Action,
+			&entry.ResourceType, &entry.ResourceID, &entry.Route, &entry.Status, &entry.ClientIP,
+			&entry.RequestID, &entry.CreatedAt, &entry.PrevHash, &entry.Hash, &entry.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// auditWhere limits entries to organizationID unless it is empty. The hash
+// chain itself spans all organizations, so ListAfter is never scoped.
+func auditWhere(organizationID string, filter repositories.AuditFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if organizationID != "" {
+		add("organization_id::text = $%d", organizationID)
+	}
+	if filter.ActorID != "" {
+		add("actor_id::text = $%d", filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		add("resource_type = $%d", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		add("resource_id = $%d", filter.ResourceID)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at <= $%d", *filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -15,27 +15,44 @@ func NewDeviceRepository(db *sql.DB) *DeviceRepositoryImpl {
 	return &DeviceRepositoryImpl{db: db}
 }
 
+const deviceColumns = `id, organization_id, name, device_id, COALESCE(label, ''), status, last_seen, brightness, zoom,
+		created_at, updated_at`
+
 func (r *DeviceRepositoryImpl) Create(ctx context.Context, device *entities.Device) error {
 	query := `
-		INSERT INTO devices (id, name, device_id, label, status, last_seen, brightness, zoom, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO devices (id, organization_id, name, device_id, label, status, last_seen, brightness, zoom, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		device.ID, device.Name, device.DeviceID, device.Label, device.Status,
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
+		device.ID, device.OrganizationID, device.Name, device.DeviceID, device.Label, device.Status,
 		device.LastSeen, device.Brightness, device.Zoom, device.CreatedAt, device.UpdatedAt)
 	return err
 }
 
+// Upsert registers a device reporting itself, or updates the status of the
+// registered device with its organization and device ID. A registered
+// device keeps its name, settings and ID; the label is replaced when given.
+// device is updated to what is stored.
+func (r *DeviceRepositoryImpl) Upsert(ctx context.Context, device *entities.Device) error {
+	query := `
+		INSERT INTO devices (id, organization_id, name, device_id, label, status, last_seen, brightness, zoom, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (organization_id, device_id) DO UPDATE SET
+			label = COALESCE(NULLIF(EXCLUDED.label, ''), devices.label),
+			status = EXCLUDED.status, last_seen = EXCLUDED.last_seen, updated_at = EXCLUDED.updated_at
+		RETURNING ` + deviceColumns
+	return scoped(ctx, r.db).QueryRowContext(ctx, query,
+		device.ID, device.OrganizationID, device.Name, device.DeviceID, device.Label, device.Status,
+		device.LastSeen, device.Brightness, device.Zoom, device.CreatedAt, device.UpdatedAt).Scan(deviceDest(device)...)
+}
+
 func (r *DeviceRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Device, error) {
 	query := `
-		SELECT id, name, device_id, label, status, last_seen, brightness, zoom, created_at, updated_at
-		FROM devices WHERE id = $1
+		SELECT ` + deviceColumns + `
+		FROM devices WHERE id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
 	`
 	device := &entities.Device{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&device.ID, &device.Name, &device.DeviceID, &device.Label, &device.Status,
-		&device.LastSeen, &device.Brightness, &device.Zoom, &device.CreatedAt, &device.UpdatedAt)
-
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, id, tenantID(ctx)).Scan(deviceDest(device)...)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -44,14 +61,11 @@ func (r *DeviceRepositoryImpl) GetByID(ctx context.Context, id string) (*entitie
 
 func (r *DeviceRepositoryImpl) GetByDeviceID(ctx context.Context, deviceID string) (*entities.Device, error) {
 	query := `
-		SELECT id, name, device_id, label, status, last_seen, brightness, zoom, created_at, updated_at
-		FROM devices WHERE device_id = $1
+		SELECT ` + deviceColumns + `
+		FROM devices WHERE device_id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
 	`
 	device := &entities.Device{}
-	err := r.db.QueryRowContext(ctx, query, deviceID).Scan(
-		&device.ID, &device.Name, &device.DeviceID, &device.Label, &device.Status,
-		&device.LastSeen, &device.Brightness, &device.Zoom, &device.CreatedAt, &device.UpdatedAt)
-
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, deviceID, tenantID(ctx)).Scan(deviceDest(device)...)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -62,64 +76,84 @@ func (r *DeviceRepositoryImpl) Update(ctx context.Context, device *entities.Devi
 	query := `
 		UPDATE devices 
 		SET name = $2, label = $3, status = $4, last_seen = $5, brightness = $6, zoom = $7, updated_at = $8
-		WHERE id = $1
+		WHERE id = $1 AND organization_id = COALESCE(NULLIF($9, '')::uuid, organization_id)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
 		device.ID, device.Name, device.Label, device.Status, device.LastSeen,
-		device.Brightness, device.Zoom, device.UpdatedAt)
+		device.Brightness, device.Zoom, device.UpdatedAt, tenantID(ctx))
+	return err
+}
+
+// MarkAllOffline sets every online device offline, e.g. when no connection
+// can be open because the API has just started.
+func (r *DeviceRepositoryImpl) MarkAllOffline(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE devices SET status = 'offline', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'online' AND organization_id = COALESCE(NULLIF($1, '')::uuid, organization_id)
+	`
+	result, err := scoped(ctx, r.db).ExecContext(ctx, query, tenantID(ctx))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// MarkOffline sets one device of an organization offline.
+func (r *DeviceRepositoryImpl) MarkOffline(ctx context.Context, organizationID, deviceID string) error {
+	query := `
+		UPDATE devices SET status = 'offline', last_seen = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE organization_id = $1::uuid AND device_id = $2
+	`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, organizationID, deviceID)
 	return err
 }
 
 func (r *DeviceRepositoryImpl) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM devices WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM devices WHERE id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, id, tenantID(ctx))
 	return err
 }
 
 func (r *DeviceRepositoryImpl) List(ctx context.Context) ([]*entities.Device, error) {
 	query := `
-		SELECT id, name, device_id, label, status, last_seen, brightness, zoom, created_at, updated_at
-		FROM devices ORDER BY created_at DESC
+		SELECT ` + deviceColumns + `
+		FROM devices WHERE organization_id = COALESCE(NULLIF($1, '')::uuid, organization_id) ORDER BY created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-
-	var devices []*entities.Device
-	for rows.Next() {
-		device := &entities.Device{}
-		err := rows.Scan(&device.ID, &device.Name, &device.DeviceID, &device.Label, &device.Status,
-			&device.LastSeen, &device.Brightness, &device.Zoom, &device.CreatedAt, &device.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		devices = append(devices, device)
-	}
-	return devices, nil
+	return scanDevices(rows)
 }
 
 func (r *DeviceRepositoryImpl) GetOnlineDevices(ctx context.Context) ([]*entities.Device, error) {
 	query := `
-		SELECT id, name, device_id, label, status, last_seen, brightness, zoom, created_at, updated_at
-		FROM devices WHERE status = 'online' ORDER BY last_seen DESC
+		SELECT ` + deviceColumns + `
+		FROM devices WHERE status = 'online' AND organization_id = COALESCE(NULLIF($1, '')::uuid, organization_id)
+		ORDER BY last_seen DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanDevices(rows)
+}
+
+func deviceDest(d *entities.Device) []interface{} {
+	return []interface{}{&d.ID, &d.OrganizationID, &d.Name, &d.DeviceID, &d.Label, &d.Status,
+		&d.LastSeen, &d.Brightness, &d.Zoom, &d.CreatedAt, &d.UpdatedAt}
+}
 
+func scanDevices(rows *sql.Rows) ([]*entities.Device, error) {
 	var devices []*entities.Device
 	for rows.Next() {
 		device := &entities.Device{}
-		err := rows.Scan(&device.ID, &device.Name, &device.DeviceID, &device.Label, &device.Status,
-			&device.LastSeen, &device.Brightness, &device.Zoom, &device.CreatedAt, &device.UpdatedAt)
-		if err != nil {
+		if err := rows.Scan(deviceDest(device)...); err != nil {
 			return nil, err
 		}
 		devices = append(devices, device)
 	}
-	return devices, nil
+	return devices, rows.Err()
 }
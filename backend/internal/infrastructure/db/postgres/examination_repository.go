@@ -7,6 +7,9 @@ import (
 	"github.com/project-capillary/backend/internal/domain/entities"
 )
 
+const examinationColumns = `id, organization_id, patient_id, doctor_id, status, exam_type, description,
+		created_at, updated_at, completed_at`
+
 type ExaminationRepositoryImpl struct {
 	db *sql.DB
 }
@@ -17,25 +20,33 @@ func NewExaminationRepository(db *sql.DB) *ExaminationRepositoryImpl {
 
 func (r *ExaminationRepositoryImpl) Create(ctx context.Context, examination *entities.Examination) error {
 	query := `
-		INSERT INTO examinations (id, patient_id, doctor_id, status, description, created_at, updated_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO examinations (id, organization_id, patient_id, doctor_id, status, exam_type, description,
+			created_at, updated_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		examination.ID, examination.PatientID, examination.DoctorID, examination.Status,
-		examination.Description, examination.CreatedAt, examination.UpdatedAt, examination.CompletedAt)
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
+		examination.ID, examination.OrganizationID, examination.PatientID, examination.DoctorID, examination.Status,
+		examination.ExamType, examination.Description, examination.CreatedAt, examination.UpdatedAt, examination.CompletedAt)
 	return err
 }
 
 func (r *ExaminationRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Examination, error) {
+	return r.getByID(ctx, id, "")
+}
+
+// GetByIDForUpdate also locks the examination row until the transaction
+// carried by ctx ends, serializing read-modify-write cycles on it.
+func (r *ExaminationRepositoryImpl) GetByIDForUpdate(ctx context.Context, id string) (*entities.Examination, error) {
+	return r.getByID(ctx, id, "FOR UPDATE")
+}
+
+func (r *ExaminationRepositoryImpl) getByID(ctx context.Context, id, lock string) (*entities.Examination, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, status, description, created_at, updated_at, completed_at
-		FROM examinations WHERE id = $1
-	`
+		SELECT ` + examinationColumns + `
+		FROM examinations WHERE id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
+	` + lock
 	examination := &entities.Examination{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&examination.ID, &examination.PatientID, &examination.DoctorID, &examination.Status,
-		&examination.Description, &examination.CreatedAt, &examination.UpdatedAt, &examination.CompletedAt)
-
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, id, tenantID(ctx)).Scan(examinationDest(examination)...)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -44,7 +55,7 @@ func (r *ExaminationRepositoryImpl) GetByID(ctx context.Context, id string) (*en
 	}
 
 	imageQuery := `SELECT image_id FROM examination_images WHERE examination_id = $1 ORDER BY image_order`
-	rows, err := r.db.QueryContext(ctx, imageQuery, id)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, imageQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -63,48 +74,51 @@ func (r *ExaminationRepositoryImpl) GetByID(ctx context.Context, id string) (*en
 }
 
 func (r *ExaminationRepositoryImpl) Update(ctx context.Context, examination *entities.Examination) error {
-	query := `
-		UPDATE examinations 
-		SET status = $2, description = $3, updated_at = $4, completed_at = $5
-		WHERE id = $1
-	`
-	_, err := r.db.ExecContext(ctx, query,
-		examination.ID, examination.Status, examination.Description,
-		examination.UpdatedAt, examination.CompletedAt)
-
-	if err != nil {
-		return err
-	}
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `
+			UPDATE examinations 
+			SET status = $2, description = $3, updated_at = $4, completed_at = $5
+			WHERE id = $1 AND organization_id = COALESCE(NULLIF($6, '')::uuid, organization_id)
+		`
+		result, err := tx.ExecContext(ctx, query,
+			examination.ID, examination.Status, examination.Description,
+			examination.UpdatedAt, examination.CompletedAt, tenantID(ctx))
+		if err != nil {
+			return err
+		}
+		// Leave the images of another organization's examination alone.
+		if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+			return err
+		}
 
-	deleteQuery := `DELETE FROM examination_images WHERE examination_id = $1`
-	if _, err := r.db.ExecContext(ctx, deleteQuery, examination.ID); err != nil {
-		return err
-	}
+		deleteQuery := `DELETE FROM examination_images WHERE examination_id = $1`
+		if _, err := tx.ExecContext(ctx, deleteQuery, examination.ID); err != nil {
+			return err
+		}
 
-	if len(examination.Images) > 0 {
 		insertQuery := `INSERT INTO examination_images (examination_id, image_id, image_order) VALUES ($1, $2, $3)`
 		for i, imageID := range examination.Images {
-			if _, err := r.db.ExecContext(ctx, insertQuery, examination.ID, imageID, i); err != nil {
+			if _, err := tx.ExecContext(ctx, insertQuery, examination.ID, imageID, i); err != nil {
 				return err
 			}
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 func (r *ExaminationRepositoryImpl) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM examinations WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM examinations WHERE id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, id, tenantID(ctx))
 	return err
 }
 
 func (r *ExaminationRepositoryImpl) GetByPatientID(ctx context.Context, patientID string) ([]*entities.Examination, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, status, description, created_at, updated_at, completed_at
-		FROM examinations WHERE patient_id = $1 ORDER BY created_at DESC
+		SELECT ` + examinationColumns + `
+		FROM examinations WHERE patient_id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
+		ORDER BY created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, patientID)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, patientID, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -113,9 +127,7 @@ func (r *ExaminationRepositoryImpl) GetByPatientID(ctx context.Context, patientI
 	var examinations []*entities.Examination
 	for rows.Next() {
 		exam := &entities.Examination{}
-		err := rows.Scan(&exam.ID, &exam.PatientID, &exam.DoctorID, &exam.Status,
-			&exam.Description, &exam.CreatedAt, &exam.UpdatedAt, &exam.CompletedAt)
-		if err != nil {
+		if err := rows.Scan(examinationDest(exam)...); err != nil {
 			return nil, err
 		}
 		exam.Images = []string{}
@@ -126,10 +138,11 @@ func (r *ExaminationRepositoryImpl) GetByPatientID(ctx context.Context, patientI
 
 func (r *ExaminationRepositoryImpl) GetByStatus(ctx context.Context, status entities.ExaminationStatus) ([]*entities.Examination, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, status, description, created_at, updated_at, completed_at
-		FROM examinations WHERE status = $1 ORDER BY created_at DESC
+		SELECT ` + examinationColumns + `
+		FROM examinations WHERE status = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
+		ORDER BY created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, status)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, status, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -138,9 +151,7 @@ func (r *ExaminationRepositoryImpl) GetByStatus(ctx context.Context, status enti
 	var examinations []*entities.Examination
 	for rows.Next() {
 		exam := &entities.Examination{}
-		err := rows.Scan(&exam.ID, &exam.PatientID, &exam.DoctorID, &exam.Status,
-			&exam.Description, &exam.CreatedAt, &exam.UpdatedAt, &exam.CompletedAt)
-		if err != nil {
+		if err := rows.Scan(examinationDest(exam)...); err != nil {
 			return nil, err
 		}
 		exam.Images = []string{}
@@ -151,10 +162,11 @@ func (r *ExaminationRepositoryImpl) GetByStatus(ctx context.Context, status enti
 
 func (r *ExaminationRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*entities.Examination, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, status, description, created_at, updated_at, completed_at
-		FROM examinations ORDER BY created_at DESC LIMIT $1 OFFSET $2
+		SELECT ` + examinationColumns + `
+		FROM examinations WHERE organization_id = COALESCE(NULLIF($3, '')::uuid, organization_id)
+		ORDER BY created_at DESC LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, limit, offset, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -163,9 +175,7 @@ func (r *ExaminationRepositoryImpl) List(ctx context.Context, limit, offset int)
 	var examinations []*entities.Examination
 	for rows.Next() {
 		exam := &entities.Examination{}
-		err := rows.Scan(&exam.ID, &exam.PatientID, &exam.DoctorID, &exam.Status,
-			&exam.Description, &exam.CreatedAt, &exam.UpdatedAt, &exam.CompletedAt)
-		if err != nil {
+		if err := rows.Scan(examinationDest(exam)...); err != nil {
 			return nil, err
 		}
 		exam.Images = []string{}
@@ -176,7 +186,12 @@ func (r *ExaminationRepositoryImpl) List(ctx context.Context, limit, offset int)
 
 func (r *ExaminationRepositoryImpl) Count(ctx context.Context) (int64, error) {
 	var count int64
-	query := `SELECT COUNT(*) FROM examinations`
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	query := `SELECT COUNT(*) FROM examinations WHERE organization_id = COALESCE(NULLIF($1, '')::uuid, organization_id)`
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, tenantID(ctx)).Scan(&count)
 	return count, err
 }
+
+func examinationDest(e *entities.Examination) []interface{} {
+	return []interface{}{&e.ID, &e.OrganizationID, &e.PatientID, &e.DoctorID, &e.Status, &e.ExamType,
+		&e.Description, &e.CreatedAt, &e.UpdatedAt, &e.CompletedAt}
+}
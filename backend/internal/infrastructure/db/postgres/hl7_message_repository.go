@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type HL7MessageRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewHL7MessageRepository(db *sql.DB) *HL7MessageRepositoryImpl {
+	return &HL7MessageRepositoryImpl{db: db}
+}
+
+func (r *HL7MessageRepositoryImpl) Create(ctx context.Context, message *entities.HL7Message) error {
+	query := `
+		INSERT INTO hl7_messages (id, report_id, control_id, message_type, destination, payload, status,
+			attempts, ack_code, ack_message, last_error, created_at, updated_at, acknowledged_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
+		message.ID, message.ReportID, message.ControlID, message.MessageType, message.Destination, message.Payload,
+		message.Status, message.Attempts, message.AckCode, message.AckMessage, message.LastError,
+		message.CreatedAt, message.UpdatedAt, message.AcknowledgedAt)
+	return err
+}
+
+func (r *HL7MessageRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.HL7Message, error) {
+	query := `
+		SELECT id, report_id, control_id, message_type, destination, payload, status,
+			attempts, ack_code, ack_message, last_error, created_at, updated_at, acknowledged_at
+		FROM hl7_messages WHERE id = $1
+	`
+	message := &entities.HL7Message{}
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&message.ID, &message.ReportID, &message.ControlID, &message.MessageType, &message.Destination,
+		&message.Payload, &message.Status, &message.Attempts, &message.AckCode, &message.AckMessage,
+		&message.LastError, &message.CreatedAt, &message.UpdatedAt, &message.AcknowledgedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return message, err
+}
+
+func (r *HL7MessageRepositoryImpl) Update(ctx context.Context, message *entities.HL7Message) error {
+	query := `
+		UPDATE hl7_messages
+		SET status = $2, attempts = $3, ack_code = $4, ack_message = $5, last_error = $6,
+			updated_at = $7, acknowledged_at = $8
+		WHERE id = $1
+	`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
+		message.ID, message.Status, message.Attempts, message.AckCode, message.AckMessage, message.LastError,
+		message.UpdatedAt, message.AcknowledgedAt)
+	return err
+}
+
+func (r *HL7MessageRepositoryImpl) ListByReportID(ctx context.Context, reportID string) ([]*entities.HL7Message, error) {
+	query := `
+		SELECT id, report_id, control_id, message_type, destination, payload, status,
+			attempts, ack_code, ack_message, last_error, created_at, updated_at, acknowledged_at
+		FROM hl7_messages WHERE report_id = $1 ORDER BY created_at DESC
+	`
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*entities.HL7Message
+	for rows.Next() {
+		message := &entities.HL7Message{}
+		err := rows.Scan(
+			&message.ID, &message.ReportID, &message.ControlID, &message.MessageType, &message.Destination,
+			&message.Payload, &message.Status, &message.Attempts, &message.AckCode, &message.AckMessage,
+			&message.LastError, &message.CreatedAt, &message.UpdatedAt, &message.AcknowledgedAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
@@ -3,8 +3,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/valueobjects"
 )
 
 type ImageRepositoryImpl struct {
@@ -17,88 +21,172 @@ func NewImageRepository(db *sql.DB) *ImageRepositoryImpl {
 
 func (r *ImageRepositoryImpl) Create(ctx context.Context, image *entities.Image) error {
 	query := `
-		INSERT INTO images (id, examination_id, filename, file_path, file_size, mime_type, width, height, captured_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO images (id, examination_id, filename, file_path, file_size, mime_type, width, height, position,
+			sha256, metadata, captured_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, '')::jsonb, $12, $13)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	metadata, err := encodeImageMetadata(image.Metadata)
+	if err != nil {
+		return err
+	}
+	_, err = scoped(ctx, r.db).ExecContext(ctx, query,
 		image.ID, image.ExaminationID, image.Filename, image.FilePath, image.FileSize,
-		image.MimeType, image.Width, image.Height, image.CapturedAt, image.CreatedAt)
+		image.MimeType, image.Width, image.Height, image.Position, image.SHA256, metadata, image.CapturedAt, image.CreatedAt)
 	return err
 }
 
 func (r *ImageRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Image, error) {
 	query := `
-		SELECT id, examination_id, filename, file_path, file_size, mime_type, width, height, captured_at, created_at
-		FROM images WHERE id = $1
+		SELECT ` + imageColumns + `
+		FROM images WHERE id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))
 	`
-	image := &entities.Image{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&image.ID, &image.ExaminationID, &image.Filename, &image.FilePath, &image.FileSize,
-		&image.MimeType, &image.Width, &image.Height, &image.CapturedAt, &image.CreatedAt)
-
+	var row imageRow
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, id, tenantID(ctx)).Scan(row.dest()...)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return image, err
+	if err != nil {
+		return nil, err
+	}
+	return row.decode()
 }
 
 func (r *ImageRepositoryImpl) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM images WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM images WHERE id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, id, tenantID(ctx))
 	return err
 }
 
 func (r *ImageRepositoryImpl) GetByExaminationID(ctx context.Context, examinationID string) ([]*entities.Image, error) {
 	query := `
-		SELECT id, examination_id, filename, file_path, file_size, mime_type, width, height, captured_at, created_at
-		FROM images WHERE examination_id = $1 ORDER BY captured_at DESC
+		SELECT ` + imageColumns + `
+		FROM images WHERE examination_id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))
+		ORDER BY captured_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, examinationID)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, examinationID, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var images []*entities.Image
-	for rows.Next() {
-		image := &entities.Image{}
-		err := rows.Scan(&image.ID, &image.ExaminationID, &image.Filename, &image.FilePath,
-			&image.FileSize, &image.MimeType, &image.Width, &image.Height, &image.CapturedAt, &image.CreatedAt)
-		if err != nil {
-			return nil, err
-		}
-		images = append(images, image)
-	}
-	return images, nil
+	return scanImages(rows)
 }
 
 func (r *ImageRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*entities.Image, error) {
 	query := `
-		SELECT id, examination_id, filename, file_path, file_size, mime_type, width, height, captured_at, created_at
-		FROM images ORDER BY captured_at DESC LIMIT $1 OFFSET $2
+		SELECT ` + imageColumns + `
+		FROM images WHERE ($3 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($3, '')::uuid))
+		ORDER BY captured_at DESC LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, limit, offset, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanImages(rows)
+}
+
+func (r *ImageRepositoryImpl) Count(ctx context.Context) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM images WHERE ($1 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($1, '')::uuid))`
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, tenantID(ctx)).Scan(&count)
+	return count, err
+}
+
+// CountByFilename counts the images referring to a stored photo. With
+// content addressing several images can share one.
+func (r *ImageRepositoryImpl) CountByFilename(ctx context.Context, filename string) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM images WHERE filename = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))`
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, filename, tenantID(ctx)).Scan(&count)
+	return count, err
+}
+
+// CountReferences counts the images and captures of every organization
+// that refer to a stored photo; see migration 017.
+func (r *ImageRepositoryImpl) CountReferences(ctx context.Context, filename string) (int64, error) {
+	var count int64
+	err := scoped(ctx, r.db).QueryRowContext(ctx, `SELECT photo_references($1)`, filename).Scan(&count)
+	return count, err
+}
+
+const imageColumns = `id, examination_id, filename, file_path, file_size, mime_type, width, height,
+		COALESCE(position, ''), COALESCE(sha256, ''), metadata, captured_at, created_at`
+
+// imageMetadata is how valueobjects.ImageMetadata is stored in the metadata
+// column.
+type imageMetadata struct {
+	Format       string     `json:"format"`
+	ColorSpace   string     `json:"color_space,omitempty"`
+	DPI          int        `json:"dpi,omitempty"`
+	TakenAt      *time.Time `json:"taken_at,omitempty"`
+	CameraMake   string     `json:"camera_make,omitempty"`
+	CameraModel  string     `json:"camera_model,omitempty"`
+	ExposureTime float64    `json:"exposure_time,omitempty"`
+	ISO          int        `json:"iso,omitempty"`
+}
+
+func encodeImageMetadata(metadata *valueobjects.ImageMetadata) (string, error) {
+	if metadata == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(imageMetadata{
+		Format:       metadata.Format,
+		ColorSpace:   metadata.ColorSpace,
+		DPI:          metadata.DPI,
+		TakenAt:      metadata.TakenAt,
+		CameraMake:   metadata.CameraMake,
+		CameraModel:  metadata.CameraModel,
+		ExposureTime: metadata.ExposureTime,
+		ISO:          metadata.ISO,
+	})
+	return string(data), err
+}
+
+// imageRow receives one image from imageColumns. Images attached before
+// metadata was read have none.
+type imageRow struct {
+	image    entities.Image
+	metadata []byte
+}
+
+func (r *imageRow) dest() []interface{} {
+	i := &r.image
+	return []interface{}{&i.ID, &i.ExaminationID, &i.Filename, &i.FilePath, &i.FileSize, &i.MimeType,
+		&i.Width, &i.Height, &i.Position, &i.SHA256, &r.metadata, &i.CapturedAt, &i.CreatedAt}
+}
+
+func (r *imageRow) decode() (*entities.Image, error) {
+	image := r.image
+	if len(r.metadata) == 0 {
+		return &image, nil
+	}
+	var stored imageMetadata
+	if err := json.Unmarshal(r.metadata, &stored); err != nil {
+		return nil, fmt.Errorf("decode image %s metadata: %w", image.ID, err)
+	}
+	image.Metadata = valueobjects.NewImageMetadata(image.Width, image.Height, stored.Format, image.FileSize, stored.ColorSpace, stored.DPI)
+	image.Metadata.TakenAt = stored.TakenAt
+	image.Metadata.CameraMake = stored.CameraMake
+	image.Metadata.CameraModel = stored.CameraModel
+	image.Metadata.ExposureTime = stored.ExposureTime
+	image.Metadata.ISO = stored.ISO
+	return &image, nil
+}
+
+func scanImages(rows *sql.Rows) ([]*entities.Image, error) {
 	var images []*entities.Image
 	for rows.Next() {
-		image := &entities.Image{}
-		err := rows.Scan(&image.ID, &image.ExaminationID, &image.Filename, &image.FilePath,
-			&image.FileSize, &image.MimeType, &image.Width, &image.Height, &image.CapturedAt, &image.CreatedAt)
+		var row imageRow
+		if err := rows.Scan(row.dest()...); err != nil {
+			return nil, err
+		}
+		image, err := row.decode()
 		if err != nil {
 			return nil, err
 		}
 		images = append(images, image)
 	}
-	return images, nil
-}
-
-func (r *ImageRepositoryImpl) Count(ctx context.Context) (int64, error) {
-	var count int64
-	query := `SELECT COUNT(*) FROM images`
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
-	return count, err
+	return images, rows.Err()
 }
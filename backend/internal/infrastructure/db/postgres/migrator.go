@@ -0,0 +1,351 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationLock is the session-level advisory lock key held while
+// migrations run, so an API and a worker starting together apply each
+// migration once.
+const migrationLock = 0x6d6967
+
+var (
+	ErrIrreversibleMigration = errors.New("migration cannot be reverted")
+	ErrUntrackedSchema       = errors.New("database has a schema but no migration history; run `migrate baseline` first")
+)
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one schema version. Down is empty when the change cannot be
+// reverted without losing data.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
+
+// MigrationStatus describes a known or recorded version. Unknown marks a
+// version recorded in the database that this build has no file for.
+type MigrationStatus struct {
+	Version    int64
+	Name       string
+	AppliedAt  *time.Time
+	Reversible bool
+	Unknown    bool
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations reads NNN_name.up.sql and NNN_name.down.sql files from the
+// root of fsys, ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		match := migrationFilePattern.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 001_name.up.sql", file)
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", file)
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %s: version %d is already used by %s", file, version, migration)
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %s: missing or empty up file", migration)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every migration not yet recorded, each in its own transaction,
+// and returns those applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	conn, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) == 0 {
+		// Databases created before the runner existed were set up by the
+		// Postgres init scripts and must be baselined, not re-migrated.
+		var hasSchema bool
+		if err := conn.QueryRowContext(ctx, `SELECT to_regclass('public.users') IS NOT NULL`).Scan(&hasSchema); err != nil {
+			return nil, err
+		}
+		if hasSchema {
+			return nil, ErrUntrackedSchema
+		}
+	}
+
+	pending := pendingMigrations(m.migrations, applied)
+	for i, migration := range pending {
+		err := inTx(ctx, conn, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+			return err
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("migration %s: %w", migration, err)
+		}
+	}
+	return pending, nil
+}
+
+// Down reverts the latest steps applied migrations, newest first. Nothing is
+// reverted if any of them is irreversible or unknown to this build.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	conn, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := revertPlan(m.migrations, applied, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range plan {
+		err := inTx(ctx, conn, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+			return err
+		})
+		if err != nil {
+			return plan[:i], fmt.Errorf("migration %s: %w", migration, err)
+		}
+	}
+	return plan, nil
+}
+
+// Baseline records every migration up to version as applied without running
+// it, for databases whose schema was created outside the runner.
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]Migration, error) {
+	conn, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded []Migration
+	for _, migration := range pendingMigrations(m.migrations, applied) {
+		if migration.Version > version {
+			break
+		}
+		_, err := conn.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+		if err != nil {
+			return recorded, err
+		}
+		recorded = append(recorded, migration)
+	}
+	return recorded, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, `SELECT to_regclass('public.schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int64]appliedMigration{}
+	if exists {
+		var err error
+		if applied, err = appliedMigrations(ctx, m.db); err != nil {
+			return nil, err
+		}
+	}
+	return migrationStatuses(m.migrations, applied), nil
+}
+
+// lock pins a connection, takes the migration lock on it and makes sure the
+// history table exists. release unlocks and returns the connection.
+func (m *Migrator) lock(ctx context.Context) (*sql.Conn, func(), error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLock); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	release := func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLock); err != nil {
+			// A session lock outlives the request; never pool the connection holding it.
+			discard(conn)
+			return
+		}
+		conn.Close()
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return conn, release, nil
+}
+
+type appliedMigration struct {
+	name      string
+	appliedAt time.Time
+}
+
+func appliedMigrations(ctx context.Context, db queryer) (map[int64]appliedMigration, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var migration appliedMigration
+		if err := rows.Scan(&version, &migration.name, &migration.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = migration
+	}
+	return applied, rows.Err()
+}
+
+func pendingMigrations(migrations []Migration, applied map[int64]appliedMigration) []Migration {
+	var pending []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+func revertPlan(migrations []Migration, applied map[int64]appliedMigration, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	known := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	if steps > len(versions) {
+		steps = len(versions)
+	}
+
+	plan := make([]Migration, 0, steps)
+	for _, version := range versions[:steps] {
+		migration, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %03d_%s is not known to this build", version, applied[version].name)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("%w: %s", ErrIrreversibleMigration, migration)
+		}
+		plan = append(plan, migration)
+	}
+	return plan, nil
+}
+
+func migrationStatuses(migrations []Migration, applied map[int64]appliedMigration) []MigrationStatus {
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name, Reversible: migration.Down != ""}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.appliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range applied {
+		if !known[version] {
+			appliedAt := record.appliedAt
+			statuses = append(statuses, MigrationStatus{Version: version, Name: record.name, AppliedAt: &appliedAt, Unknown: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
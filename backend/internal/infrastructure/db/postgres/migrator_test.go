@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/project-capillary/backend/internal/infrastructure/db/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_column.up.sql":     {Data: []byte("ALTER TABLE t ADD COLUMN c INT;")},
+		"001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (id INT);")},
+		"001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+	got, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Version != 1 || got[1].Version != 2 {
+		t.Fatalf("LoadMigrations() = %+v, want versions 1, 2", got)
+	}
+	if got[0].Name != "create_table" || got[0].Down != "DROP TABLE t;" {
+		t.Errorf("migration 1 = %+v", got[0])
+	}
+	if got[1].Down != "" {
+		t.Errorf("migration 2 must be irreversible, got down %q", got[1].Down)
+	}
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"bad name", fstest.MapFS{"001_create_table.sql": {Data: []byte("SELECT 1;")}}},
+		{"down without up", fstest.MapFS{"001_create_table.down.sql": {Data: []byte("DROP TABLE t;")}}},
+		{"empty up", fstest.MapFS{"001_create_table.up.sql": {Data: []byte("")}}},
+		{"zero version", fstest.MapFS{"000_create_table.up.sql": {Data: []byte("SELECT 1;")}}},
+		{"duplicate version", fstest.MapFS{
+			"001_create_table.up.sql": {Data: []byte("SELECT 1;")},
+			"001_other_table.up.sql":  {Data: []byte("SELECT 1;")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadMigrations(tt.fsys); err == nil {
+				t.Error("LoadMigrations() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	got, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	for i, migration := range got {
+		if migration.Version != int64(i+1) {
+			t.Errorf("migration %s: versions must be consecutive from 1", migration)
+		}
+	}
+}
+
+func TestRevertPlan(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "one", Up: "up", Down: "down"},
+		{Version: 2, Name: "two", Up: "up"},
+		{Version: 3, Name: "three", Up: "up", Down: "down"},
+		{Version: 4, Name: "four", Up: "up", Down: "down"},
+	}
+	applied := func(versions ...int64) map[int64]appliedMigration {
+		m := make(map[int64]appliedMigration)
+		for _, v := range versions {
+			m[v] = appliedMigration{name: "recorded", appliedAt: time.Now()}
+		}
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		applied map[int64]appliedMigration
+		steps   int
+		want    []int64
+		wantErr bool
+	}{
+		{"newest first", applied(1, 2, 3, 4), 2, []int64{4, 3}, false},
+		{"steps beyond history", applied(1), 5, []int64{1}, false},
+		{"nothing applied", applied(), 1, []int64{}, false},
+		{"irreversible", applied(1, 2, 3, 4), 3, nil, true},
+		{"unknown version", applied(1, 2, 3, 4, 5), 1, nil, true},
+		{"non-positive steps", applied(1), 0, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := revertPlan(migrations, tt.applied, tt.steps)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("revertPlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(plan) != len(tt.want) {
+				t.Fatalf("revertPlan() = %v, want versions %v", plan, tt.want)
+			}
+			for i, migration := range plan {
+				if migration.Version != tt.want[i] {
+					t.Errorf("revertPlan()[%d] = %s, want version %d", i, migration, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := revertPlan(migrations, applied(1, 2, 3, 4), 3); !errors.Is(err, ErrIrreversibleMigration) {
+		t.Errorf("revertPlan() error = %v, want ErrIrreversibleMigration", err)
+	}
+}
+
+func TestPendingMigrationsAndStatuses(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "one", Up: "up", Down: "down"},
+		{Version: 2, Name: "two", Up: "up"},
+		{Version: 3, Name: "three", Up: "up"},
+	}
+	applied := map[int64]appliedMigration{
+		1: {name: "one", appliedAt: time.Now()},
+		3: {name: "three", appliedAt: time.Now()},
+		7: {name: "from_newer_build", appliedAt: time.Now()},
+	}
+
+	pending := pendingMigrations(migrations, applied)
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("pendingMigrations() = %v, want [002_two]", pending)
+	}
+
+	statuses := migrationStatuses(migrations, applied)
+	if len(statuses) != 4 {
+		t.Fatalf("migrationStatuses() returned %d rows, want 4", len(statuses))
+	}
+	if statuses[0].AppliedAt == nil || !statuses[0].Reversible {
+		t.Errorf("status 1 = %+v, want applied and reversible", statuses[0])
+	}
+	if statuses[1].AppliedAt != nil || statuses[1].Reversible {
+		t.Errorf("status 2 = %+v, want pending and irreversible", statuses[1])
+	}
+	if last := statuses[3]; last.Version != 7 || !last.Unknown || last.AppliedAt == nil {
+		t.Errorf("status 7 = %+v, want an applied unknown migration", last)
+	}
+}
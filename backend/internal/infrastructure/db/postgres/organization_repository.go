@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type OrganizationRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepositoryImpl {
+	return &OrganizationRepositoryImpl{db: db}
+}
+
+func (r *OrganizationRepositoryImpl) Create(ctx context.Context, organization *entities.Organization) error {
+	query := `INSERT INTO organizations (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4)`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
+		organization.ID, organization.Name, organization.CreatedAt, organization.UpdatedAt)
+	return err
+}
+
+func (r *OrganizationRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Organization, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM organizations WHERE id = $1 AND id = COALESCE(NULLIF($2, '')::uuid, id)
+	`
+	organization := &entities.Organization{}
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, id, tenantID(ctx)).Scan(
+		&organization.ID, &organization.Name, &organization.CreatedAt, &organization.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return organization, err
+}
+
+func (r *OrganizationRepositoryImpl) Update(ctx context.Context, organization *entities.Organization) error {
+	query := `
+		UPDATE organizations SET name = $2, updated_at = $3
+		WHERE id = $1 AND id = COALESCE(NULLIF($4, '')::uuid, id)
+	`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
+		organization.ID, organization.Name, organization.UpdatedAt, tenantID(ctx))
+	return err
+}
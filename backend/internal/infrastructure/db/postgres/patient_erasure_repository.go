@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
+)
+
+type PatientErasureRepositoryImpl struct {
+	db   *sql.DB
+	keys *pii.Keyring
+}
+
+func NewPatientErasureRepository(db *sql.DB, keys *pii.Keyring) *PatientErasureRepositoryImpl {
+	return &PatientErasureRepositoryImpl{db: db, keys: keys}
+}
+
+func (r *PatientErasureRepositoryImpl) Erase(ctx context.Context, erasure *entities.PatientErasure, patient *entities.Patient) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var id string
+		lockQuery := `SELECT id FROM patients WHERE id = $1 AND organization_id = $2 FOR UPDATE`
+		err := tx.QueryRowContext(ctx, lockQuery, erasure.PatientID, patient.OrganizationID).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		switch erasure.Mode {
+		case entities.ErasureModeDelete:
+			if _, err := tx.ExecContext(ctx, `DELETE FROM patient_merges WHERE merged_id = $1`, erasure.PatientID); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM patients WHERE id = $1`, erasure.PatientID); err != nil {
+				return err
+			}
+		default:
+			imagesQuery := `
+				UPDATE images SET filename = '', file_path = '', file_size = 0, sha256 = NULL
+				WHERE examination_id IN (SELECT id FROM examinations WHERE patient_id = $1)
+			`
+			if _, err := tx.ExecContext(ctx, imagesQuery, erasure.PatientID); err != nil {
+				return err
+			}
+
+			// Captures stay as a record of who took the photos, but no
+			// longer point at them.
+			capturesQuery := `
+				UPDATE photo_captures SET filename = '', sha256 = NULL
+				WHERE image_id IN (SELECT i.id FROM images i JOIN examinations e ON e.id = i.examination_id
+				                   WHERE e.patient_id = $1)
+			`
+			if _, err := tx.ExecContext(ctx, capturesQuery, erasure.PatientID); err != nil {
+				return err
+			}
+
+			mergesQuery := `UPDATE patient_merges SET merged_patient = '{}' WHERE survivor_id = $1 OR merged_id = $1`
+			if _, err := tx.ExecContext(ctx, mergesQuery, erasure.PatientID); err != nil {
+				return err
+			}
+
+			// Sent HL7 results carry the patient's name, birth date and
+			// phone in PID; the delivery record stays without them.
+			hl7Query := `
+				UPDATE hl7_messages SET payload = ''
+				WHERE report_id IN (SELECT r.id FROM reports r JOIN examinations e ON e.id = r.examination_id
+				                    WHERE e.patient_id = $1)
+			`
+			if _, err := tx.ExecContext(ctx, hl7Query, erasure.PatientID); err != nil {
+				return err
+			}
+
+			if err := writePatient(ctx, tx, r.keys, patient); err != nil {
+				return err
+			}
+		}
+
+		insertQuery := `
+			INSERT INTO patient_erasures (id, organization_id, patient_id, requested_by, reason, mode,
+			                              examination_count, image_count, files_deleted, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`
+		_, err = tx.ExecContext(ctx, insertQuery,
+			erasure.ID, patient.OrganizationID, erasure.PatientID, erasure.RequestedBy, erasure.Reason, erasure.Mode,
+			erasure.ExaminationCount, erasure.ImageCount, erasure.FilesDeleted, erasure.CreatedAt)
+		return err
+	})
+}
+
+func (r *PatientErasureRepositoryImpl) ListByPatientID(ctx context.Context, patientID string) ([]*entities.PatientErasure, error) {
+	query := `
+		SELECT id, patient_id, requested_by, reason, mode,
+		       examination_count, image_count, files_deleted, created_at
+		FROM patient_erasures
+		WHERE patient_id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
+		ORDER BY created_at DESC
+	`
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, patientID, tenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var erasures []*entities.PatientErasure
+	for rows.Next() {
+		erasure := &entities.PatientErasure{}
+		err := rows.Scan(&erasure.ID, &erasure.PatientID, &erasure.RequestedBy, &erasure.Reason, &erasure.Mode,
+			&erasure.ExaminationCount, &erasure.ImageCount, &erasure.FilesDeleted, &erasure.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		erasures = append(erasures, erasure)
+	}
+	return erasures, nil
+}
@@ -0,0 +1,276 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
+)
+
+type PatientMergeRepositoryImpl struct {
+	db   *sql.DB
+	keys *pii.Keyring
+}
+
+func NewPatientMergeRepository(db *sql.DB, keys *pii.Keyring) *PatientMergeRepositoryImpl {
+	return &PatientMergeRepositoryImpl{db: db, keys: keys}
+}
+
+// sealedSnapshot is how merged_patient is stored: the patientSnapshot JSON
+// sealed with the merge ID as associated data. Merges recorded before
+// encryption hold the plain snapshot instead.
+type sealedSnapshot struct {
+	Sealed []byte `json:"sealed"`
+}
+
+type patientSnapshot struct {
+	ID             string    `json:"id"`
+	FirstName      string    `json:"first_name"`
+	LastName       string    `json:"last_name"`
+	MiddleName     string    `json:"middle_name"`
+	DateOfBirth    time.Time `json:"date_of_birth"`
+	Gender         string    `json:"gender"`
+	Phone          string    `json:"phone"`
+	Email          string    `json:"email"`
+	ExternalSystem string    `json:"external_system,omitempty"`
+	ExternalID     string    `json:"external_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func newPatientSnapshot(p *entities.Patient) patientSnapshot {
+	return patientSnapshot{
+		ID:             p.ID,
+		FirstName:      p.FirstName,
+		LastName:       p.LastName,
+		MiddleName:     p.MiddleName,
+		DateOfBirth:    p.DateOfBirth,
+		Gender:         p.Gender,
+		Phone:          p.Phone,
+		Email:          p.Email,
+		ExternalSystem: p.ExternalSystem,
+		ExternalID:     p.ExternalID,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
+
+func (r *PatientMergeRepositoryImpl) sealSnapshot(mergeID string, p *entities.Patient) ([]byte, error) {
+	snapshot, err := json.Marshal(newPatientSnapshot(p))
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := r.keys.Seal(snapshot, []byte(mergeID))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealedSnapshot{Sealed: sealed})
+}
+
+func (r *PatientMergeRepositoryImpl) openSnapshot(mergeID string, data []byte) (*entities.Patient, error) {
+	var envelope sealedSnapshot
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	if len(envelope.Sealed) > 0 {
+		var err error
+		if data, err = r.keys.Open(envelope.Sealed, []byte(mergeID)); err != nil {
+			return nil, err
+		}
+	}
+
+	var snapshot patientSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot.patient(), nil
+}
+
+func (s patientSnapshot) patient() *entities.Patient {
+	return &entities.Patient{
+		ID:             s.ID,
+		FirstName:      s.FirstName,
+		LastName:       s.LastName,
+		MiddleName:     s.MiddleName,
+		DateOfBirth:    s.DateOfBirth,
+		Gender:         s.Gender,
+		Phone:          s.Phone,
+		Email:          s.Email,
+		ExternalSystem: s.ExternalSystem,
+		ExternalID:     s.ExternalID,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
+
+func (r *PatientMergeRepositoryImpl) Merge(ctx context.Context, merge *entities.PatientMerge, survivor *entities.Patient) error {
+	snapshot, err := r.sealSnapshot(merge.ID, merge.MergedPatient)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		lockQuery := `SELECT id FROM patients WHERE id IN ($1, $2) AND organization_id = $3 ORDER BY id FOR UPDATE`
+		rows, err := tx.QueryContext(ctx, lockQuery, merge.SurvivorID, merge.MergedID, survivor.OrganizationID)
+		if err != nil {
+			return err
+		}
+		locked := 0
+		for rows.Next() {
+			locked++
+		}
+		rows.Close()
+		if locked != 2 {
+			return sql.ErrNoRows
+		}
+
+		merge.ExaminationIDs, err = queryIDs(ctx, tx,
+			`UPDATE examinations SET patient_id = $1, updated_at = $3 WHERE patient_id = $2 RETURNING id`,
+			merge.SurvivorID, merge.MergedID, merge.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		merge.ImageIDs, err = queryIDs(ctx, tx,
+			`SELECT id FROM images WHERE examination_id = ANY($1) ORDER BY id`, pq.Array(merge.ExaminationIDs))
+		if err != nil {
+			return err
+		}
+
+		merge.ReportIDs, err = queryIDs(ctx, tx,
+			`SELECT id FROM reports WHERE examination_id = ANY($1) ORDER BY id`, pq.Array(merge.ExaminationIDs))
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM patients WHERE id = $1`, merge.MergedID); err != nil {
+			return err
+		}
+
+		if err := writePatient(ctx, tx, r.keys, survivor); err != nil {
+			return err
+		}
+
+		insertQuery := `
+			INSERT INTO patient_merges (id, survivor_id, merged_id, merged_by, merged_patient,
+			                            examination_ids, image_ids, report_ids, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`
+		_, err = tx.ExecContext(ctx, insertQuery,
+			merge.ID, merge.SurvivorID, merge.MergedID, merge.MergedBy, snapshot,
+			pq.Array(merge.ExaminationIDs), pq.Array(merge.ImageIDs), pq.Array(merge.ReportIDs), merge.CreatedAt)
+		return err
+	})
+}
+
+func (r *PatientMergeRepositoryImpl) ListBySurvivorID(ctx context.Context, survivorID string) ([]*entities.PatientMerge, error) {
+	query := `
+		SELECT id, survivor_id, merged_id, merged_by, merged_patient,
+		       examination_ids::text[], image_ids::text[], report_ids::text[], created_at
+		FROM patient_merges
+		WHERE survivor_id = $1
+		  AND ($2 = '' OR survivor_id IN (SELECT id FROM patients WHERE organization_id = NULLIF($2, '')::uuid))
+		ORDER BY created_at DESC
+	`
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, survivorID, tenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merges []*entities.PatientMerge
+	for rows.Next() {
+		merge := &entities.PatientMerge{}
+		var snapshotJSON []byte
+		err := rows.Scan(&merge.ID, &merge.SurvivorID, &merge.MergedID, &merge.MergedBy, &snapshotJSON,
+			pq.Array(&merge.ExaminationIDs), pq.Array(&merge.ImageIDs), pq.Array(&merge.ReportIDs), &merge.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		merge.MergedPatient, err = r.openSnapshot(merge.ID, snapshotJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		merges = append(merges, merge)
+	}
+	return merges, nil
+}
+
+// Reencrypt seals every merge snapshot not yet under the active key again,
+// including snapshots recorded before encryption.
+func (r *PatientMergeRepositoryImpl) Reencrypt(ctx context.Context, batchSize int) (int, error) {
+	query := `SELECT id, merged_patient FROM patient_merges WHERE id > $1 ORDER BY id LIMIT $2`
+
+	updated := 0
+	lastID := "00000000-0000-0000-0000-000000000000"
+	for {
+		rows, err := scoped(ctx, r.db).QueryContext(ctx, query, lastID, batchSize)
+		if err != nil {
+			return updated, err
+		}
+		snapshots := map[string][]byte{}
+		var ids []string
+		for rows.Next() {
+			var id string
+			var data []byte
+			if err := rows.Scan(&id, &data); err != nil {
+				rows.Close()
+				return updated, err
+			}
+			ids = append(ids, id)
+			snapshots[id] = data
+		}
+		rows.Close()
+
+		for _, id := range ids {
+			lastID = id
+			var envelope sealedSnapshot
+			if err := json.Unmarshal(snapshots[id], &envelope); err != nil {
+				return updated, err
+			}
+			if keyID, err := pii.KeyID(envelope.Sealed); err == nil && keyID == r.keys.Active {
+				continue
+			}
+
+			patient, err := r.openSnapshot(id, snapshots[id])
+			if err != nil {
+				return updated, err
+			}
+			sealed, err := r.sealSnapshot(id, patient)
+			if err != nil {
+				return updated, err
+			}
+			if _, err := scoped(ctx, r.db).ExecContext(ctx, `UPDATE patient_merges SET merged_patient = $2 WHERE id = $1`, id, sealed); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+		if len(ids) < batchSize {
+			return updated, nil
+		}
+	}
+}
+
+func queryIDs(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
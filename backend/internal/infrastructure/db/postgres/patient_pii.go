@@ -0,0 +1,296 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
+)
+
+// Blind index kinds. Name words are indexed whole and by every prefix of at
+// least minIndexedPrefix letters, so "Иван" finds "Иванов"; the first letters
+// of the given and middle names back "Фамилия И.О." searches. Name words are
+// also indexed by trigram, like pg_trgm did before encryption, so a query
+// word of at least minFuzzyWord letters sharing half its trigrams with a
+// name word still matches: "Ивонов" finds "Иванов". Every run of at least
+// minPhonePart digits of the phone is indexed for partial phone searches.
+const (
+	indexDateOfBirth = "dob"
+	indexPhone       = "phone"
+	indexEmail       = "email"
+	indexName        = "name"
+	indexNamePrefix  = "prefix"
+	indexNameTrigram = "trigram"
+	indexPhonePart   = "phonepart"
+	indexInitial1    = "initial1"
+	indexInitial2    = "initial2"
+
+	minIndexedPrefix = 3
+	minFuzzyWord     = 4
+	minPhonePart     = 3
+	maxPhoneDigits   = 15
+
+	// patientIndexVersion is stored with each row's blind indexes; raise
+	// it when they change so that `piikey reencrypt` rebuilds older rows.
+	patientIndexVersion = 1
+)
+
+// patientPII is the encrypted part of a patients row.
+type patientPII struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	MiddleName  string `json:"middle_name,omitempty"`
+	DateOfBirth string `json:"date_of_birth"`
+	Phone       string `json:"phone,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+func patientColumnList(alias string) string {
+	columns := []string{
+		"id", "%sorganization_id", "COALESCE(%sfirst_name, '')", "COALESCE(%slast_name, '')", "COALESCE(%smiddle_name, '')",
+		"%sdate_of_birth", "%sgender", "COALESCE(%sphone, '')", "COALESCE(%semail, '')",
+		"COALESCE(%sexternal_system, '')", "COALESCE(%sexternal_id, '')", "%screated_at", "%supdated_at",
+		"%sdeleted_at", "%serased_at", "%slegal_hold_until", "COALESCE(%slegal_hold_reason, '')", "%sversion", "%spii",
+	}
+	prefix := ""
+	if alias != "" {
+		prefix = alias + "."
+	}
+	for i, column := range columns {
+		if !strings.Contains(column, "%s") {
+			column = "%s" + column
+		}
+		columns[i] = fmt.Sprintf(column, prefix)
+	}
+	return strings.Join(columns, ", ")
+}
+
+var patientColumns = patientColumnList("")
+
+// patientRow receives one patient from patientColumnList. Rows written
+// before encryption was introduced have no pii and keep plaintext columns.
+type patientRow struct {
+	patient     entities.Patient
+	dateOfBirth sql.NullTime
+	sealed      []byte
+}
+
+func (r *patientRow) dest() []interface{} {
+	p := &r.patient
+	return []interface{}{&p.ID, &p.OrganizationID, &p.FirstName, &p.LastName, &p.MiddleName,
+		&r.dateOfBirth, &p.Gender, &p.Phone, &p.Email,
+		&p.ExternalSystem, &p.ExternalID, &p.CreatedAt, &p.UpdatedAt,
+		&p.DeletedAt, &p.ErasedAt, &p.LegalHoldUntil, &p.LegalHoldReason, &p.Version, &r.sealed}
+}
+
+func (r *patientRow) decode(keys *pii.Keyring) (*entities.Patient, error) {
+	patient := r.patient
+	if r.dateOfBirth.Valid {
+		patient.DateOfBirth = r.dateOfBirth.Time
+	}
+	if len(r.sealed) == 0 {
+		return &patient, nil
+	}
+
+	plaintext, err := keys.Open(r.sealed, []byte(patient.ID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt patient %s: %w", patient.ID, err)
+	}
+	var data patientPII
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, fmt.Errorf("decode patient %s: %w", patient.ID, err)
+	}
+	dateOfBirth, err := time.Parse("2006-01-02", data.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("decode patient %s: %w", patient.ID, err)
+	}
+
+	patient.FirstName = data.FirstName
+	patient.LastName = data.LastName
+	patient.MiddleName = data.MiddleName
+	patient.DateOfBirth = dateOfBirth
+	patient.Phone = data.Phone
+	patient.Email = data.Email
+	return &patient, nil
+}
+
+// patientRecord is the stored form of a patient's personal data: the
+// sealed fields plus the plaintext birth year and blind indexes that keep
+// filtering and search working.
+type patientRecord struct {
+	sealed        []byte
+	keyID         string
+	birthYear     int
+	dobIndex      string
+	phoneIndex    string
+	emailIndex    string
+	nameTokens    []string
+	searchTokens  []string
+	trigramTokens []string
+}
+
+func newPatientRecord(keys *pii.Keyring, p *entities.Patient) (*patientRecord, error) {
+	plaintext, err := json.Marshal(patientPII{
+		FirstName:   p.FirstName,
+		LastName:    p.LastName,
+		MiddleName:  p.MiddleName,
+		DateOfBirth: p.DateOfBirth.Format("2006-01-02"),
+		Phone:       p.Phone,
+		Email:       p.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := keys.Seal(plaintext, []byte(p.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	record := &patientRecord{
+		sealed:        sealed,
+		keyID:         keys.Active,
+		birthYear:     p.DateOfBirth.Year(),
+		dobIndex:      dateOfBirthIndex(keys, p.DateOfBirth),
+		nameTokens:    []string{},
+		searchTokens:  []string{},
+		trigramTokens: []string{},
+	}
+	if phone := entities.PhoneKey(p.Phone); phone != "" {
+		record.phoneIndex = keys.BlindIndex(indexPhone, phone)
+	}
+	if email := normalizeEmail(p.Email); email != "" {
+		record.emailIndex = keys.BlindIndex(indexEmail, email)
+	}
+
+	words := nameWords(p.LastName + " " + p.FirstName + " " + p.MiddleName)
+	for _, word := range words {
+		record.nameTokens = append(record.nameTokens, keys.BlindIndex(indexName, word))
+		letters := []rune(word)
+		for n := minIndexedPrefix; n < len(letters); n++ {
+			record.searchTokens = append(record.searchTokens, keys.BlindIndex(indexNamePrefix, string(letters[:n])))
+		}
+	}
+	record.trigramTokens = append(record.trigramTokens, trigramTokens(keys, words...)...)
+	digits := phoneDigits(p.Phone)
+	for n := minPhonePart; n <= len(digits); n++ {
+		for i := 0; i+n <= len(digits); i++ {
+			record.searchTokens = append(record.searchTokens, keys.BlindIndex(indexPhonePart, digits[i:i+n]))
+		}
+	}
+	if initial := firstLetter(p.FirstName); initial != "" {
+		record.searchTokens = append(record.searchTokens, keys.BlindIndex(indexInitial1, initial))
+	}
+	if initial := firstLetter(p.MiddleName); initial != "" {
+		record.searchTokens = append(record.searchTokens, keys.BlindIndex(indexInitial2, initial))
+	}
+	return record, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// writePatient stores every mutable field of an existing patient, sealing
+// the personal data and clearing any legacy plaintext columns. It fails with
+// ErrVersionConflict unless the row still has p.Version, then bumps it.
+func writePatient(ctx context.Context, db execer, keys *pii.Keyring, p *entities.Patient) error {
+	record, err := newPatientRecord(keys, p)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE patients
+		SET first_name = NULL, last_name = NULL, middle_name = NULL, date_of_birth = NULL, phone = NULL, email = NULL,
+		    gender = $2, external_system = NULLIF($3, ''), external_id = NULLIF($4, ''), updated_at = $5,
+		    deleted_at = $6, erased_at = $7, legal_hold_until = $8, legal_hold_reason = NULLIF($9, ''),
+		    pii = $10, pii_key_id = $11, birth_year = $12, dob_index = $13,
+		    phone_index = NULLIF($14, ''), email_index = NULLIF($15, ''), name_tokens = $16, search_tokens = $17,
+		    name_trigrams = $18, index_version = $19, version = version + 1
+		WHERE id = $1 AND organization_id = COALESCE(NULLIF($20, '')::uuid, organization_id) AND version = $21
+	`
+	result, err := db.ExecContext(ctx, query,
+		p.ID, p.Gender, p.ExternalSystem, p.ExternalID, p.UpdatedAt,
+		p.DeletedAt, p.ErasedAt, p.LegalHoldUntil, p.LegalHoldReason,
+		record.sealed, record.keyID, record.birthYear, record.dobIndex,
+		record.phoneIndex, record.emailIndex, pq.Array(record.nameTokens), pq.Array(record.searchTokens),
+		pq.Array(record.trigramTokens), patientIndexVersion, tenantID(ctx), p.Version)
+	if err != nil {
+		return err
+	}
+	if err := checkVersioned(result); err != nil {
+		return err
+	}
+	p.Version++
+	return nil
+}
+
+func dateOfBirthIndex(keys *pii.Keyring, dateOfBirth time.Time) string {
+	return keys.BlindIndex(indexDateOfBirth, dateOfBirth.Format("2006-01-02"))
+}
+
+// phoneDigits returns the digits of a phone number, at most maxPhoneDigits
+// of them.
+func phoneDigits(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) > maxPhoneDigits {
+		digits = digits[:maxPhoneDigits]
+	}
+	return digits
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// nameWords splits a transliterated name into letter-only words.
+func nameWords(name string) []string {
+	return strings.FieldsFunc(entities.NormalizeName(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// trigramTokens blind-indexes the distinct trigrams of words, each padded
+// the way pg_trgm pads them: two spaces before, one after.
+func trigramTokens(keys *pii.Keyring, words ...string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, word := range words {
+		letters := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(letters); i++ {
+			trigram := string(letters[i : i+3])
+			if !seen[trigram] {
+				seen[trigram] = true
+				tokens = append(tokens, keys.BlindIndex(indexNameTrigram, trigram))
+			}
+		}
+	}
+	return tokens
+}
+
+// fuzzyMatchThreshold is how many of a query word's trigrams a patient's
+// name must share for the word to match.
+func fuzzyMatchThreshold(trigrams int) int {
+	return (trigrams + 1) / 2
+}
+
+func firstLetter(name string) string {
+	for _, r := range entities.NormalizeName(name) {
+		if unicode.IsLetter(r) {
+			return string(r)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
+)
+
+func TestPatientRecordRoundTrip(t *testing.T) {
+	keys, err := pii.NewKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	patient := entities.NewPatient("p1", "Иван", "Иванов", "Иванович",
+		time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), "male", "+7 (999) 123-45-67", "Ivan@Example.com")
+
+	record, err := newPatientRecord(keys, patient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := patientRow{patient: entities.Patient{ID: patient.ID, Gender: patient.Gender}, sealed: record.sealed}
+	got, err := row.decode(keys)
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if got.FullName() != patient.FullName() || !got.DateOfBirth.Equal(patient.DateOfBirth) ||
+		got.Phone != patient.Phone || got.Email != patient.Email {
+		t.Errorf("decode() = %+v, want %+v", got, patient)
+	}
+
+	moved := patientRow{patient: entities.Patient{ID: "p2"}, sealed: record.sealed}
+	if _, err := moved.decode(keys); err == nil {
+		t.Error("decode() must reject pii moved to another row")
+	}
+
+	if record.birthYear != 1990 {
+		t.Errorf("birthYear = %d, want 1990", record.birthYear)
+	}
+	if record.emailIndex != keys.BlindIndex(indexEmail, "ivan@example.com") {
+		t.Error("email index must be case-insensitive")
+	}
+	if record.phoneIndex != keys.BlindIndex(indexPhone, "9991234567") {
+		t.Error("phone index must use the last ten digits")
+	}
+}
+
+func TestPatientRecordSearchTokens(t *testing.T) {
+	keys, err := pii.NewKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	patient := entities.NewPatient("p1", "Иван", "Петров-Водкин", "",
+		time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), "male", "+7 (999) 123-45-67", "")
+	record, err := newPatientRecord(keys, patient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		kind   string
+		value  string
+		tokens []string
+		want   bool
+	}{
+		{name: "whole word", kind: indexName, value: "vodkin", tokens: record.nameTokens, want: true},
+		{name: "latin spelling of cyrillic name", kind: indexName, value: "ivan", tokens: record.nameTokens, want: true},
+		{name: "prefix", kind: indexNamePrefix, value: "pet", tokens: record.searchTokens, want: true},
+		{name: "too short prefix", kind: indexNamePrefix, value: "pe", tokens: record.searchTokens, want: false},
+		{name: "whole word is not a prefix", kind: indexNamePrefix, value: "petrov", tokens: record.searchTokens, want: false},
+		{name: "first initial", kind: indexInitial1, value: "i", tokens: record.searchTokens, want: true},
+		{name: "no middle name", kind: indexInitial2, value: "i", tokens: record.searchTokens, want: false},
+		{name: "part of the phone", kind: indexPhonePart, value: "1234", tokens: record.searchTokens, want: true},
+		{name: "whole phone", kind: indexPhonePart, value: "79991234567", tokens: record.searchTokens, want: true},
+		{name: "too short part of the phone", kind: indexPhonePart, value: "12", tokens: record.searchTokens, want: false},
+		{name: "digits not in the phone", kind: indexPhonePart, value: "4321", tokens: record.searchTokens, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := keys.BlindIndex(tt.kind, tt.value)
+			found := false
+			for _, token := range tt.tokens {
+				found = found || token == index
+			}
+			if found != tt.want {
+				t.Errorf("token %s:%s present = %v, want %v", tt.kind, tt.value, found, tt.want)
+			}
+		})
+	}
+
+	if got := nameWords("Петров-Водкин Иван"); !reflect.DeepEqual(got, []string{"petrov", "vodkin", "ivan"}) {
+		t.Errorf("nameWords() = %q", got)
+	}
+}
+
+func TestPatientRecordTrigramTokens(t *testing.T) {
+	keys, err := pii.NewKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	patient := entities.NewPatient("p1", "Иван", "Иванов", "",
+		time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC), "male", "", "")
+	record, err := newPatientRecord(keys, patient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := make(map[string]bool)
+	for _, token := range record.trigramTokens {
+		stored[token] = true
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{name: "exact", query: "Иванов", want: true},
+		{name: "wrong vowel", query: "Ивонов", want: true},
+		{name: "latin spelling with a typo", query: "ivanof", want: true},
+		{name: "missing letter", query: "Ианов", want: true},
+		{name: "different name", query: "Петров", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := trigramTokens(keys, nameWords(tt.query)...)
+			shared := 0
+			for _, token := range tokens {
+				if stored[token] {
+					shared++
+				}
+			}
+			if got := shared >= fuzzyMatchThreshold(len(tokens)); got != tt.want {
+				t.Errorf("%q shares %d of %d trigrams, match = %v, want %v", tt.query, shared, len(tokens), got, tt.want)
+			}
+		})
+	}
+}
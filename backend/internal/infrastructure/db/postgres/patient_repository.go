@@ -3,101 +3,231 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/lib/pq"
 	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+	"github.com/project-capillary/backend/internal/domain/valueobjects"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
 )
 
+// PatientRepositoryImpl stores names, date of birth, phone and email
+// envelope-encrypted in the pii column; lookups go through blind indexes.
 type PatientRepositoryImpl struct {
-	db *sql.DB
+	db   *sql.DB
+	keys *pii.Keyring
 }
 
-func NewPatientRepository(db *sql.DB) *PatientRepositoryImpl {
-	return &PatientRepositoryImpl{db: db}
+func NewPatientRepository(db *sql.DB, keys *pii.Keyring) *PatientRepositoryImpl {
+	return &PatientRepositoryImpl{db: db, keys: keys}
 }
 
 func (r *PatientRepositoryImpl) Create(ctx context.Context, patient *entities.Patient) error {
+	record, err := newPatientRecord(r.keys, patient)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO patients (id, first_name, last_name, middle_name, date_of_birth, gender, phone, email, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO patients (id, organization_id, gender, external_system, external_id, created_at, updated_at,
+		                      pii, pii_key_id, birth_year, dob_index, phone_index, email_index, name_tokens, search_tokens,
+		                      name_trigrams, index_version, version)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''), $14, $15, $16, $17, $18)
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		patient.ID, patient.FirstName, patient.LastName, patient.MiddleName,
-		patient.DateOfBirth, patient.Gender, patient.Phone, patient.Email,
-		patient.CreatedAt, patient.UpdatedAt)
+	_, err = scoped(ctx, r.db).ExecContext(ctx, query,
+		patient.ID, patient.OrganizationID, patient.Gender, patient.ExternalSystem, patient.ExternalID, patient.CreatedAt, patient.UpdatedAt,
+		record.sealed, record.keyID, record.birthYear, record.dobIndex, record.phoneIndex, record.emailIndex,
+		pq.Array(record.nameTokens), pq.Array(record.searchTokens), pq.Array(record.trigramTokens), patientIndexVersion, patient.Version)
 	return err
 }
 
 func (r *PatientRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Patient, error) {
-	query := `
-		SELECT id, first_name, last_name, middle_name, date_of_birth, gender, phone, email, created_at, updated_at
-		FROM patients WHERE id = $1
-	`
-	patient := &entities.Patient{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&patient.ID, &patient.FirstName, &patient.LastName, &patient.MiddleName,
-		&patient.DateOfBirth, &patient.Gender, &patient.Phone, &patient.Email,
-		&patient.CreatedAt, &patient.UpdatedAt)
+	query := `SELECT ` + patientColumns + `
+		FROM patients WHERE id = $1 AND deleted_at IS NULL AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)`
+	return r.queryPatient(ctx, query, id, tenantID(ctx))
+}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	return patient, nil
+func (r *PatientRepositoryImpl) GetByIDIncludingDeleted(ctx context.Context, id string) (*entities.Patient, error) {
+	query := `SELECT ` + patientColumns + `
+		FROM patients WHERE id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)`
+	return r.queryPatient(ctx, query, id, tenantID(ctx))
+}
+
+func (r *PatientRepositoryImpl) GetByExternalID(ctx context.Context, system, value string) (*entities.Patient, error) {
+	query := `SELECT ` + patientColumns + `
+		FROM patients
+		WHERE external_system = $1 AND external_id = $2 AND deleted_at IS NULL AND organization_id = COALESCE(NULLIF($3, '')::uuid, organization_id)`
+	return r.queryPatient(ctx, query, system, value, tenantID(ctx))
 }
 
 func (r *PatientRepositoryImpl) Update(ctx context.Context, patient *entities.Patient) error {
-	query := `
-		UPDATE patients 
-		SET first_name = $2, last_name = $3, middle_name = $4, phone = $5, email = $6, updated_at = $7
-		WHERE id = $1
-	`
-	_, err := r.db.ExecContext(ctx, query,
-		patient.ID, patient.FirstName, patient.LastName, patient.MiddleName,
-		patient.Phone, patient.Email, patient.UpdatedAt)
-	return err
+	return writePatient(ctx, scoped(ctx, r.db), r.keys, patient)
 }
 
+// Delete removes the patient row; examinations, images and reports go with it
+// through ON DELETE CASCADE. Use Update with SoftDelete for everyday deletes.
 func (r *PatientRepositoryImpl) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM patients WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM patients WHERE id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, id, tenantID(ctx))
 	return err
 }
 
 func (r *PatientRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*entities.Patient, error) {
+	query := `SELECT ` + patientColumns + `
+		FROM patients WHERE deleted_at IS NULL AND organization_id = COALESCE(NULLIF($3, '')::uuid, organization_id)
+		ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+	return r.queryPatients(ctx, query, limit, offset, tenantID(ctx))
+}
+
+// Search pages through the patients matching filter in SQL. The database
+// only knows birth years, so exact date of birth bounds and sorting by name
+// or date of birth load every match instead and filter, sort and page the
+// decrypted patients.
+func (r *PatientRepositoryImpl) Search(ctx context.Context, filter repositories.PatientFilter) ([]*entities.Patient, error) {
+	search := newPatientSearch(r.keys, filter, tenantID(ctx))
+	if decryptedSort(filter) || hasBirthDateBounds(filter) {
+		query := fmt.Sprintf(`SELECT %s FROM patients %s ORDER BY %s`, patientColumns, search.where(), search.orderBy(filter))
+		patients, err := r.queryPatients(ctx, query, search.args...)
+		if err != nil {
+			return nil, err
+		}
+		patients = filterBirthDates(patients, filter)
+		sortPatients(patients, filter)
+		return pagePatients(patients, filter.Offset, filter.Limit), nil
+	}
+
+	limit, offset := search.arg(filter.Limit), search.arg(filter.Offset)
+	searchQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM patients %s
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, patientColumns, search.where(), search.orderBy(filter), limit, offset)
+	return r.queryPatients(ctx, searchQuery, search.args...)
+}
+
+func (r *PatientRepositoryImpl) Count(ctx context.Context, filter repositories.PatientFilter) (int64, error) {
+	var count int64
+	search := newPatientSearch(r.keys, filter, tenantID(ctx))
+	if hasBirthDateBounds(filter) {
+		patients, err := r.queryPatients(ctx, `SELECT `+patientColumns+` FROM patients `+search.where(), search.args...)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(filterBirthDates(patients, filter))), nil
+	}
+	query := `SELECT COUNT(*) FROM patients ` + search.where()
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, search.args...).Scan(&count)
+	return count, err
+}
+
+// FindDuplicateCandidates returns records with the same date of birth that
+// share a name word, the phone or the email; scoring is left to
+// entities.MatchPatients.
+func (r *PatientRepositoryImpl) FindDuplicateCandidates(ctx context.Context, patient *entities.Patient, limit int) ([]*entities.Patient, error) {
+	record, err := newPatientRecord(r.keys, patient)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + patientColumns + `
+		FROM patients
+		WHERE id <> $1 AND deleted_at IS NULL AND organization_id = $7 AND dob_index = $2 AND (
+			name_tokens && $3
+			OR ($4 <> '' AND phone_index = $4)
+			OR ($5 <> '' AND email_index = $5)
+		)
+		ORDER BY created_at
+		LIMIT $6
+	`
+	return r.queryPatients(ctx, query,
+		patient.ID, record.dobIndex, pq.Array(record.nameTokens), record.phoneIndex, record.emailIndex, limit, patient.OrganizationID)
+}
+
+func (r *PatientRepositoryImpl) ListDuplicatePairs(ctx context.Context, limit, offset int) ([]repositories.PatientPair, error) {
 	query := `
-		SELECT id, first_name, last_name, middle_name, date_of_birth, gender, phone, email, created_at, updated_at
-		FROM patients ORDER BY created_at DESC LIMIT $1 OFFSET $2
+		SELECT ` + patientColumnList("a") + `, ` + patientColumnList("b") + `
+		FROM patients a
+		JOIN patients b ON b.organization_id = a.organization_id AND b.dob_index = a.dob_index
+		                AND b.id > a.id AND b.deleted_at IS NULL
+		WHERE a.deleted_at IS NULL AND a.organization_id = COALESCE(NULLIF($3, '')::uuid, a.organization_id) AND (
+		       a.name_tokens && b.name_tokens
+		    OR a.phone_index = b.phone_index
+		    OR a.email_index = b.email_index)
+		ORDER BY a.created_at, a.id, b.id
+		LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, limit, offset, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var patients []*entities.Patient
+	var pairs []repositories.PatientPair
 	for rows.Next() {
-		patient := &entities.Patient{}
-		err := rows.Scan(&patient.ID, &patient.FirstName, &patient.LastName, &patient.MiddleName,
-			&patient.DateOfBirth, &patient.Gender, &patient.Phone, &patient.Email,
-			&patient.CreatedAt, &patient.UpdatedAt)
+		var first, second patientRow
+		if err := rows.Scan(append(first.dest(), second.dest()...)...); err != nil {
+			return nil, err
+		}
+		a, err := first.decode(r.keys)
 		if err != nil {
 			return nil, err
 		}
-		patients = append(patients, patient)
+		b, err := second.decode(r.keys)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, repositories.PatientPair{First: a, Second: b})
 	}
-	return patients, nil
+	return pairs, nil
 }
 
-func (r *PatientRepositoryImpl) Search(ctx context.Context, query string) ([]*entities.Patient, error) {
-	searchQuery := `
-		SELECT id, first_name, last_name, middle_name, date_of_birth, gender, phone, email, created_at, updated_at
-		FROM patients 
-		WHERE first_name ILIKE $1 OR last_name ILIKE $1 OR middle_name ILIKE $1 OR email ILIKE $1
-		ORDER BY created_at DESC
-	`
-	rows, err := r.db.QueryContext(ctx, searchQuery, "%"+query+"%")
+// Reencrypt seals every patient not yet under the active key again, which
+// also encrypts rows written before encryption and rebuilds their blind
+// indexes. Rows whose blind indexes predate patientIndexVersion are
+// rewritten as well. It is safe to interrupt and rerun.
+func (r *PatientRepositoryImpl) Reencrypt(ctx context.Context, batchSize int) (int, error) {
+	query := `SELECT ` + patientColumns + `
+		FROM patients WHERE id > $1 AND (pii_key_id IS DISTINCT FROM $2 OR index_version < $4) ORDER BY id LIMIT $3`
+
+	updated := 0
+	lastID := "00000000-0000-0000-0000-000000000000"
+	for {
+		patients, err := r.queryPatients(ctx, query, lastID, r.keys.Active, batchSize, patientIndexVersion)
+		if err != nil {
+			return updated, err
+		}
+		for _, patient := range patients {
+			if err := writePatient(ctx, scoped(ctx, r.db), r.keys, patient); err != nil {
+				return updated, err
+			}
+			updated++
+			lastID = patient.ID
+		}
+		if len(patients) < batchSize {
+			return updated, nil
+		}
+	}
+}
+
+func (r *PatientRepositoryImpl) queryPatient(ctx context.Context, query string, args ...interface{}) (*entities.Patient, error) {
+	var row patientRow
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(row.dest()...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row.decode(r.keys)
+}
+
+func (r *PatientRepositoryImpl) queryPatients(ctx context.Context, query string, args ...interface{}) ([]*entities.Patient, error) {
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -105,10 +235,11 @@ func (r *PatientRepositoryImpl) Search(ctx context.Context, query string) ([]*en
 
 	var patients []*entities.Patient
 	for rows.Next() {
-		patient := &entities.Patient{}
-		err := rows.Scan(&patient.ID, &patient.FirstName, &patient.LastName, &patient.MiddleName,
-			&patient.DateOfBirth, &patient.Gender, &patient.Phone, &patient.Email,
-			&patient.CreatedAt, &patient.UpdatedAt)
+		var row patientRow
+		if err := rows.Scan(row.dest()...); err != nil {
+			return nil, err
+		}
+		patient, err := row.decode(r.keys)
 		if err != nil {
 			return nil, err
 		}
@@ -117,9 +248,193 @@ func (r *PatientRepositoryImpl) Search(ctx context.Context, query string) ([]*en
 	return patients, nil
 }
 
-func (r *PatientRepositoryImpl) Count(ctx context.Context) (int64, error) {
-	var count int64
-	query := `SELECT COUNT(*) FROM patients`
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
-	return count, err
+var patientSortColumns = map[string][]string{
+	repositories.PatientSortCreatedAt:   {"created_at"},
+	repositories.PatientSortUpdatedAt:   {"updated_at"},
+	repositories.PatientSortDateOfBirth: {"birth_year"},
+}
+
+// patientSearch builds the WHERE clause and relevance expression for a
+// PatientFilter over blind indexes. Every name word in the query must match
+// a whole word or a word prefix of the patient's transliterated name, or,
+// for longer words, share enough trigrams with it to forgive a typo; the
+// query alternatively matches the exact phone, email or date of birth.
+// Relevance counts whole-word matches plus the share of matching trigrams.
+type patientSearch struct {
+	conditions []string
+	args       []interface{}
+	rank       string
+}
+
+func newPatientSearch(keys *pii.Keyring, filter repositories.PatientFilter, organizationID string) *patientSearch {
+	s := &patientSearch{}
+	s.conditions = append(s.conditions, "organization_id = COALESCE(NULLIF("+s.arg(organizationID)+", '')::uuid, organization_id)")
+	if filter.Deleted {
+		s.conditions = append(s.conditions, "deleted_at IS NOT NULL AND erased_at IS NULL")
+	} else {
+		s.conditions = append(s.conditions, "deleted_at IS NULL")
+	}
+	name := valueobjects.ParseNameQuery(filter.Query)
+
+	if strings.TrimSpace(filter.Query) != "" {
+		var matches, words, ranks []string
+		for _, term := range name.Terms {
+			for _, word := range nameWords(term) {
+				whole := s.arg(keys.BlindIndex(indexName, word))
+				ranks = append(ranks, fmt.Sprintf("(name_tokens @> ARRAY[%s])::int", whole))
+				if len([]rune(word)) < minIndexedPrefix {
+					words = append(words, fmt.Sprintf("name_tokens @> ARRAY[%s]", whole))
+					continue
+				}
+				prefix := s.arg(keys.BlindIndex(indexNamePrefix, word))
+				if len([]rune(word)) < minFuzzyWord {
+					words = append(words, fmt.Sprintf("(name_tokens @> ARRAY[%s] OR search_tokens @> ARRAY[%s])", whole, prefix))
+					continue
+				}
+				tokens := trigramTokens(keys, word)
+				trigrams := s.arg(pq.Array(tokens))
+				shared := fmt.Sprintf("cardinality(ARRAY(SELECT unnest(name_trigrams) INTERSECT SELECT unnest(%s::text[])))", trigrams)
+				words = append(words, fmt.Sprintf("(name_tokens @> ARRAY[%s] OR search_tokens @> ARRAY[%s] OR (name_trigrams && %s::text[] AND %s >= %d))",
+					whole, prefix, trigrams, shared, fuzzyMatchThreshold(len(tokens))))
+				ranks = append(ranks, fmt.Sprintf("%s::float / %d", shared, len(tokens)))
+			}
+		}
+		if len(words) > 0 {
+			matches = append(matches, "("+strings.Join(words, " AND ")+")")
+			s.rank = strings.Join(ranks, " + ")
+		}
+
+		if phone := entities.PhoneKey(filter.Query); phone != "" {
+			matches = append(matches, "phone_index = "+s.arg(keys.BlindIndex(indexPhone, phone)))
+		}
+		if digits := phoneDigits(filter.Query); len(digits) >= minPhonePart {
+			matches = append(matches, "search_tokens @> ARRAY["+s.arg(keys.BlindIndex(indexPhonePart, digits))+"]")
+		}
+		if email := normalizeEmail(filter.Query); strings.Contains(email, "@") {
+			matches = append(matches, "email_index = "+s.arg(keys.BlindIndex(indexEmail, email)))
+		}
+		if filter.QueryDate != nil {
+			matches = append(matches, "dob_index = "+s.arg(dateOfBirthIndex(keys, *filter.QueryDate)))
+		}
+
+		if len(matches) == 0 {
+			matches = append(matches, "FALSE")
+		}
+		s.conditions = append(s.conditions, "("+strings.Join(matches, " OR ")+")")
+	}
+
+	initialKinds := []string{indexInitial1, indexInitial2}
+	for i, initial := range name.Initials {
+		if i >= len(initialKinds) {
+			break
+		}
+		if letter := firstLetter(initial); letter != "" {
+			s.conditions = append(s.conditions, "search_tokens @> ARRAY["+s.arg(keys.BlindIndex(initialKinds[i], letter))+"]")
+		}
+	}
+
+	if filter.Gender != "" {
+		s.conditions = append(s.conditions, "gender = "+s.arg(filter.Gender))
+	}
+	if filter.DateOfBirthFrom != nil {
+		s.conditions = append(s.conditions, "birth_year >= "+s.arg(filter.DateOfBirthFrom.Year()))
+	}
+	if filter.DateOfBirthTo != nil {
+		s.conditions = append(s.conditions, "birth_year <= "+s.arg(filter.DateOfBirthTo.Year()))
+	}
+
+	return s
+}
+
+func (s *patientSearch) arg(value interface{}) string {
+	s.args = append(s.args, value)
+	return fmt.Sprintf("$%d", len(s.args))
+}
+
+func (s *patientSearch) where() string {
+	return "WHERE " + strings.Join(s.conditions, " AND ")
+}
+
+func (s *patientSearch) orderBy(filter repositories.PatientFilter) string {
+	if filter.SortBy == repositories.PatientSortRelevance && s.rank != "" {
+		return "(" + s.rank + ") DESC, created_at DESC, id"
+	}
+
+	columns, ok := patientSortColumns[filter.SortBy]
+	if !ok {
+		columns = patientSortColumns[repositories.PatientSortCreatedAt]
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	order := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		order = append(order, column+" "+direction)
+	}
+	return strings.Join(append(order, "id"), ", ")
+}
+
+// decryptedSort reports whether the sort order needs decrypted fields.
+func decryptedSort(filter repositories.PatientFilter) bool {
+	return filter.SortBy == repositories.PatientSortLastName || filter.SortBy == repositories.PatientSortDateOfBirth
+}
+
+func hasBirthDateBounds(filter repositories.PatientFilter) bool {
+	return filter.DateOfBirthFrom != nil || filter.DateOfBirthTo != nil
+}
+
+// filterBirthDates keeps the patients born within the filter's bounds; the
+// query has only narrowed them down to the birth years.
+func filterBirthDates(patients []*entities.Patient, filter repositories.PatientFilter) []*entities.Patient {
+	if !hasBirthDateBounds(filter) {
+		return patients
+	}
+	kept := patients[:0]
+	for _, patient := range patients {
+		if filter.DateOfBirthFrom != nil && patient.DateOfBirth.Before(*filter.DateOfBirthFrom) {
+			continue
+		}
+		if filter.DateOfBirthTo != nil && patient.DateOfBirth.After(*filter.DateOfBirthTo) {
+			continue
+		}
+		kept = append(kept, patient)
+	}
+	return kept
+}
+
+// sortPatients applies a name or date of birth sort to decrypted patients,
+// keeping the query's order among equal ones.
+func sortPatients(patients []*entities.Patient, filter repositories.PatientFilter) {
+	var less func(a, b *entities.Patient) bool
+	switch filter.SortBy {
+	case repositories.PatientSortLastName:
+		less = func(a, b *entities.Patient) bool { return patientNameKey(a) < patientNameKey(b) }
+	case repositories.PatientSortDateOfBirth:
+		less = func(a, b *entities.Patient) bool { return a.DateOfBirth.Before(b.DateOfBirth) }
+	default:
+		return
+	}
+	sort.SliceStable(patients, func(i, j int) bool {
+		if filter.SortDesc {
+			return less(patients[j], patients[i])
+		}
+		return less(patients[i], patients[j])
+	})
+}
+
+func patientNameKey(p *entities.Patient) string {
+	return strings.ToLower(p.LastName + "\x00" + p.FirstName + "\x00" + p.MiddleName)
+}
+
+func pagePatients(patients []*entities.Patient, offset, limit int) []*entities.Patient {
+	if offset >= len(patients) {
+		return nil
+	}
+	patients = patients[offset:]
+	if limit > 0 && limit < len(patients) {
+		patients = patients[:limit]
+	}
+	return patients
 }
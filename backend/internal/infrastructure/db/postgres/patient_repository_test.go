@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+)
+
+func TestDecryptedPatientSearch(t *testing.T) {
+	born := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	patients := func() []*entities.Patient {
+		return []*entities.Patient{
+			entities.NewPatient("p1", "Анна", "Петрова", "", born(1990, 12, 31), "female", "", ""),
+			entities.NewPatient("p2", "Иван", "Иванов", "", born(1990, 1, 1), "male", "", ""),
+			entities.NewPatient("p3", "Пётр", "Иванов", "", born(1991, 6, 15), "male", "", ""),
+			entities.NewPatient("p4", "Анна", "Иванова", "", born(1989, 6, 15), "female", "", ""),
+		}
+	}
+	from, to := born(1990, 6, 1), born(1991, 6, 15)
+
+	tests := []struct {
+		name   string
+		filter repositories.PatientFilter
+		want   []string
+	}{
+		{
+			name:   "birth date bounds are exact and inclusive",
+			filter: repositories.PatientFilter{DateOfBirthFrom: &from, DateOfBirthTo: &to},
+			want:   []string{"p1", "p3"},
+		},
+		{
+			name:   "date of birth within a year",
+			filter: repositories.PatientFilter{SortBy: repositories.PatientSortDateOfBirth},
+			want:   []string{"p4", "p2", "p1", "p3"},
+		},
+		{
+			name:   "last name then first name descending",
+			filter: repositories.PatientFilter{SortBy: repositories.PatientSortLastName, SortDesc: true},
+			want:   []string{"p1", "p4", "p3", "p2"},
+		},
+		{
+			name:   "page",
+			filter: repositories.PatientFilter{SortBy: repositories.PatientSortLastName, Offset: 1, Limit: 2},
+			want:   []string{"p3", "p4"},
+		},
+		{
+			name:   "page past the end",
+			filter: repositories.PatientFilter{SortBy: repositories.PatientSortLastName, Offset: 4, Limit: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterBirthDates(patients(), tt.filter)
+			sortPatients(got, tt.filter)
+			if tt.filter.Limit > 0 {
+				got = pagePatients(got, tt.filter.Offset, tt.filter.Limit)
+			}
+			ids := make([]string, 0, len(got))
+			for _, patient := range got {
+				ids = append(ids, patient.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("patients = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("patients = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type PhotoCaptureRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewPhotoCaptureRepository(db *sql.DB) *PhotoCaptureRepositoryImpl {
+	return &PhotoCaptureRepositoryImpl{db: db}
+}
+
+const photoCaptureColumns = `id, COALESCE(organization_id::text, ''), filename, COALESCE(sha256, ''), file_size, mime_type,
+		COALESCE(captured_by::text, ''), COALESCE(device_id, ''), COALESCE(image_id::text, ''), captured_at, assigned_at`
+
+func (r *PhotoCaptureRepositoryImpl) Create(ctx context.Context, capture *entities.PhotoCapture) error {
+	query := `
+		INSERT INTO photo_captures (id, organization_id, filename, sha256, file_size, mime_type,
+			captured_by, device_id, image_id, captured_at, assigned_at)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, NULLIF($4, ''), $5, $6, NULLIF($7, '')::uuid, NULLIF($8, ''),
+			NULLIF($9, '')::uuid, $10, $11)
+	`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
+		capture.ID, capture.OrganizationID, capture.Filename, capture.SHA256, capture.FileSize, capture.MimeType,
+		capture.CapturedBy, capture.DeviceID, capture.ImageID, capture.CapturedAt, capture.AssignedAt)
+	return err
+}
+
+func (r *PhotoCaptureRepositoryImpl) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM photo_captures WHERE id = $1 AND ($2 = '' OR organization_id = NULLIF($2, '')::uuid)`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, id, tenantID(ctx))
+	return err
+}
+
+func (r *PhotoCaptureRepositoryImpl) GetUnassignedByFilename(ctx context.Context, filename string) (*entities.PhotoCapture, error) {
+	query := `
+		SELECT ` + photoCaptureColumns + `
+		FROM photo_captures
+		WHERE filename = $1 AND image_id IS NULL AND organization_id = NULLIF($2, '')::uuid
+		ORDER BY captured_at LIMIT 1
+	`
+	capture := &entities.PhotoCapture{}
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, filename, tenantID(ctx)).Scan(photoCaptureDest(capture)...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return capture, nil
+}
+
+// ListUnassigned and the other inbox reads serve requests only, so unlike
+// the maintenance queries an unscoped context matches no organization.
+func (r *PhotoCaptureRepositoryImpl) ListUnassigned(ctx context.Context, limit, offset int) ([]*entities.PhotoCapture, error) {
+	query := `
+		SELECT ` + photoCaptureColumns + `
+		FROM photo_captures
+		WHERE image_id IS NULL AND organization_id = NULLIF($3, '')::uuid
+		ORDER BY captured_at DESC LIMIT $1 OFFSET $2
+	`
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, limit, offset, tenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPhotoCaptures(rows)
+}
+
+func (r *PhotoCaptureRepositoryImpl) CountUnassigned(ctx context.Context) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM photo_captures WHERE image_id IS NULL AND organization_id = NULLIF($1, '')::uuid`
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, tenantID(ctx)).Scan(&count)
+	return count, err
+}
+
+func (r *PhotoCaptureRepositoryImpl) ListStale(ctx context.Context, cutoff time.Time, limit int) ([]*entities.PhotoCapture, error) {
+	query := `
+		SELECT ` + photoCaptureColumns + `
+		FROM photo_captures
+		WHERE image_id IS NULL AND captured_at < $1 AND ($3 = '' OR organization_id = NULLIF($3, '')::uuid)
+		ORDER BY captured_at LIMIT $2
+	`
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, cutoff, limit, tenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPhotoCaptures(rows)
+}
+
+func (r *PhotoCaptureRepositoryImpl) Assign(ctx context.Context, capture *entities.PhotoCapture) error {
+	query := `
+		UPDATE photo_captures SET image_id = $2::uuid, assigned_at = $3
+		WHERE id = $1 AND ($4 = '' OR organization_id = NULLIF($4, '')::uuid)
+	`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, capture.ID, capture.ImageID, capture.AssignedAt, tenantID(ctx))
+	return err
+}
+
+func (r *PhotoCaptureRepositoryImpl) ListByExaminationID(ctx context.Context, examinationID string) ([]*entities.PhotoCapture, error) {
+	query := `
+		SELECT ` + photoCaptureColumns + `
+		FROM photo_captures
+		WHERE image_id IN (SELECT id FROM images WHERE examination_id = $1)
+		  AND ($2 = '' OR organization_id = NULLIF($2, '')::uuid)
+		ORDER BY captured_at
+	`
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, examinationID, tenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPhotoCaptures(rows)
+}
+
+func photoCaptureDest(c *entities.PhotoCapture) []interface{} {
+	return []interface{}{&c.ID, &c.OrganizationID, &c.Filename, &c.SHA256, &c.FileSize, &c.MimeType,
+		&c.CapturedBy, &c.DeviceID, &c.ImageID, &c.CapturedAt, &c.AssignedAt}
+}
+
+func scanPhotoCaptures(rows *sql.Rows) ([]*entities.PhotoCapture, error) {
+	var captures []*entities.PhotoCapture
+	for rows.Next() {
+		capture := &entities.PhotoCapture{}
+		if err := rows.Scan(photoCaptureDest(capture)...); err != nil {
+			return nil, err
+		}
+		captures = append(captures, capture)
+	}
+	return captures, rows.Err()
+}
@@ -17,24 +17,29 @@ func NewReportRepository(db *sql.DB) *ReportRepositoryImpl {
 
 func (r *ReportRepositoryImpl) Create(ctx context.Context, report *entities.Report) error {
 	query := `
-		INSERT INTO reports (id, examination_id, title, content, summary, diagnosis, recommendations, generated_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO reports (id, examination_id, title, content, summary, diagnosis, recommendations, generated_by, status, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
 		report.ID, report.ExaminationID, report.Title, report.Content, report.Summary,
-		report.Diagnosis, report.Recommendations, report.GeneratedBy, report.CreatedAt, report.UpdatedAt)
+		report.Diagnosis, report.Recommendations, report.GeneratedBy, report.Status, report.CreatedAt, report.UpdatedAt,
+		report.Version)
 	return err
 }
 
 func (r *ReportRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Report, error) {
 	query := `
-		SELECT id, examination_id, title, content, summary, diagnosis, recommendations, generated_by, created_at, updated_at
-		FROM reports WHERE id = $1
+		SELECT id, examination_id, title, content, summary, diagnosis, recommendations, generated_by,
+		       status, COALESCE(signed_by::text, ''), signed_at, COALESCE(signature_key_id::text, ''), signature,
+		       created_at, updated_at, version
+		FROM reports WHERE id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))
 	`
 	report := &entities.Report{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, id, tenantID(ctx)).Scan(
 		&report.ID, &report.ExaminationID, &report.Title, &report.Content, &report.Summary,
-		&report.Diagnosis, &report.Recommendations, &report.GeneratedBy, &report.CreatedAt, &report.UpdatedAt)
+		&report.Diagnosis, &report.Recommendations, &report.GeneratedBy,
+		&report.Status, &report.SignedBy, &report.SignedAt, &report.SignatureKeyID, &report.Signature,
+		&report.CreatedAt, &report.UpdatedAt, &report.Version)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -45,30 +50,47 @@ func (r *ReportRepositoryImpl) GetByID(ctx context.Context, id string) (*entitie
 func (r *ReportRepositoryImpl) Update(ctx context.Context, report *entities.Report) error {
 	query := `
 		UPDATE reports 
-		SET content = $2, summary = $3, diagnosis = $4, recommendations = $5, updated_at = $6
-		WHERE id = $1
+		SET content = $2, summary = $3, diagnosis = $4, recommendations = $5, updated_at = $6,
+		    status = $7, signed_by = NULLIF($8, '')::uuid, signed_at = $9,
+		    signature_key_id = NULLIF($10, '')::uuid, signature = $11, version = version + 1
+		WHERE id = $1 AND ($12 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($12, '')::uuid))
+		  AND version = $13
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := scoped(ctx, r.db).ExecContext(ctx, query,
 		report.ID, report.Content, report.Summary, report.Diagnosis,
-		report.Recommendations, report.UpdatedAt)
-	return err
+		report.Recommendations, report.UpdatedAt,
+		report.Status, report.SignedBy, report.SignedAt, report.SignatureKeyID, report.Signature,
+		tenantID(ctx), report.Version)
+	if err != nil {
+		return err
+	}
+	if err := checkVersioned(result); err != nil {
+		return err
+	}
+	report.Version++
+	return nil
 }
 
 func (r *ReportRepositoryImpl) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM reports WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM reports WHERE id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, id, tenantID(ctx))
 	return err
 }
 
 func (r *ReportRepositoryImpl) GetByExaminationID(ctx context.Context, examinationID string) (*entities.Report, error) {
 	query := `
-		SELECT id, examination_id, title, content, summary, diagnosis, recommendations, generated_by, created_at, updated_at
-		FROM reports WHERE examination_id = $1 ORDER BY created_at DESC LIMIT 1
+		SELECT id, examination_id, title, content, summary, diagnosis, recommendations, generated_by,
+		       status, COALESCE(signed_by::text, ''), signed_at, COALESCE(signature_key_id::text, ''), signature,
+		       created_at, updated_at, version
+		FROM reports WHERE examination_id = $1 AND ($2 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($2, '')::uuid))
+		ORDER BY created_at DESC LIMIT 1
 	`
 	report := &entities.Report{}
-	err := r.db.QueryRowContext(ctx, query, examinationID).Scan(
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, examinationID, tenantID(ctx)).Scan(
 		&report.ID, &report.ExaminationID, &report.Title, &report.Content, &report.Summary,
-		&report.Diagnosis, &report.Recommendations, &report.GeneratedBy, &report.CreatedAt, &report.UpdatedAt)
+		&report.Diagnosis, &report.Recommendations, &report.GeneratedBy,
+		&report.Status, &report.SignedBy, &report.SignedAt, &report.SignatureKeyID, &report.Signature,
+		&report.CreatedAt, &report.UpdatedAt, &report.Version)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -78,10 +100,13 @@ func (r *ReportRepositoryImpl) GetByExaminationID(ctx context.Context, examinati
 
 func (r *ReportRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*entities.Report, error) {
 	query := `
-		SELECT id, examination_id, title, content, summary, diagnosis, recommendations, generated_by, created_at, updated_at
-		FROM reports ORDER BY created_at DESC LIMIT $1 OFFSET $2
+		SELECT id, examination_id, title, content, summary, diagnosis, recommendations, generated_by,
+		       status, COALESCE(signed_by::text, ''), signed_at, COALESCE(signature_key_id::text, ''), signature,
+		       created_at, updated_at, version
+		FROM reports WHERE ($3 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($3, '')::uuid))
+		ORDER BY created_at DESC LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, limit, offset, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +117,8 @@ func (r *ReportRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*
 		report := &entities.Report{}
 		err := rows.Scan(&report.ID, &report.ExaminationID, &report.Title, &report.Content,
 			&report.Summary, &report.Diagnosis, &report.Recommendations, &report.GeneratedBy,
-			&report.CreatedAt, &report.UpdatedAt)
+			&report.Status, &report.SignedBy, &report.SignedAt, &report.SignatureKeyID, &report.Signature,
+			&report.CreatedAt, &report.UpdatedAt, &report.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -100,3 +126,10 @@ func (r *ReportRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*
 	}
 	return reports, nil
 }
+
+func (r *ReportRepositoryImpl) Count(ctx context.Context) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM reports WHERE ($1 = '' OR examination_id IN (SELECT id FROM examinations WHERE organization_id = NULLIF($1, '')::uuid))`
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, tenantID(ctx)).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/pii"
+)
+
+type SigningKeyRepositoryImpl struct {
+	db   *sql.DB
+	keys *pii.Keyring
+}
+
+func NewSigningKeyRepository(db *sql.DB, keys *pii.Keyring) *SigningKeyRepositoryImpl {
+	return &SigningKeyRepositoryImpl{db: db, keys: keys}
+}
+
+const signingKeyColumns = `id, user_id, algorithm, public_key, private_key, private_key_id, created_at, revoked_at`
+
+// Create stores the key with its private key sealed under the active PII
+// key.
+func (r *SigningKeyRepositoryImpl) Create(ctx context.Context, key *entities.SigningKey) error {
+	sealed, err := r.keys.Seal(key.PrivateKey, []byte(key.ID))
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO signing_keys (id, user_id, algorithm, public_key, private_key, private_key_id, created_at, revoked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = scoped(ctx, r.db).ExecContext(ctx, query,
+		key.ID, key.UserID, key.Algorithm, key.PublicKey, sealed, r.keys.Active, key.CreatedAt, key.RevokedAt)
+	return err
+}
+
+func (r *SigningKeyRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.SigningKey, error) {
+	query := `SELECT ` + signingKeyColumns + ` FROM signing_keys WHERE id = $1`
+	return r.queryKey(ctx, query, id)
+}
+
+func (r *SigningKeyRepositoryImpl) GetActiveByUserID(ctx context.Context, userID string) (*entities.SigningKey, error) {
+	query := `
+		SELECT ` + signingKeyColumns + `
+		FROM signing_keys WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC LIMIT 1
+	`
+	return r.queryKey(ctx, query, userID)
+}
+
+func (r *SigningKeyRepositoryImpl) Revoke(ctx context.Context, id string) error {
+	query := `UPDATE signing_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+// Reencrypt seals every private key not yet under the active PII key again,
+// including keys stored in plaintext before encryption. It is safe to
+// interrupt and rerun.
+func (r *SigningKeyRepositoryImpl) Reencrypt(ctx context.Context, batchSize int) (int, error) {
+	query := `SELECT ` + signingKeyColumns + `
+		FROM signing_keys WHERE id > $1 AND private_key_id IS DISTINCT FROM $2 ORDER BY id LIMIT $3`
+
+	updated := 0
+	lastID := "00000000-0000-0000-0000-000000000000"
+	for {
+		keys, err := r.queryKeys(ctx, query, lastID, r.keys.Active, batchSize)
+		if err != nil {
+			return updated, err
+		}
+		for _, key := range keys {
+			sealed, err := r.keys.Seal(key.PrivateKey, []byte(key.ID))
+			if err != nil {
+				return updated, err
+			}
+			_, err = scoped(ctx, r.db).ExecContext(ctx,
+				`UPDATE signing_keys SET private_key = $2, private_key_id = $3 WHERE id = $1`, key.ID, sealed, r.keys.Active)
+			if err != nil {
+				return updated, err
+			}
+			updated++
+			lastID = key.ID
+		}
+		if len(keys) < batchSize {
+			return updated, nil
+		}
+	}
+}
+
+func (r *SigningKeyRepositoryImpl) queryKey(ctx context.Context, query string, args ...interface{}) (*entities.SigningKey, error) {
+	keys, err := r.queryKeys(ctx, query, args...)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	return keys[0], nil
+}
+
+func (r *SigningKeyRepositoryImpl) queryKeys(ctx context.Context, query string, args ...interface{}) ([]*entities.SigningKey, error) {
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*entities.SigningKey
+	for rows.Next() {
+		key := &entities.SigningKey{}
+		var sealedUnder sql.NullString
+		err := rows.Scan(&key.ID, &key.UserID, &key.Algorithm, &key.PublicKey, &key.PrivateKey, &sealedUnder,
+			&key.CreatedAt, &key.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+		// Keys stored before encryption hold the plaintext seed.
+		if sealedUnder.Valid {
+			seed, err := r.keys.Open(key.PrivateKey, []byte(key.ID))
+			if err != nil {
+				return nil, fmt.Errorf("decrypt signing key %s: %w", key.ID, err)
+			}
+			key.PrivateKey = seed
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
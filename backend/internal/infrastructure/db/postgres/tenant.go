@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+)
+
+// tenantRole is the role tenant-scoped connections switch to. Unlike the
+// owner the application logs in as, it is subject to the row-level security
+// policies keyed on the app.organization_id setting (see migration 010).
+const tenantRole = "capillary_app"
+
+// queryer is implemented by *sql.DB, by the pinned *sql.Conn of a
+// tenant-scoped request and by the *sql.Tx of a unit of work.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type tenantConnKey struct{}
+
+// ScopeTenant pins a connection for the rest of ctx, switches it to
+// tenantRole and sets app.organization_id, so Postgres itself hides other
+// organizations' rows from every repository call made with the returned
+// context. release must be called once the request is done.
+func (p *PostgresDB) ScopeTenant(ctx context.Context, organizationID string) (context.Context, func(), error) {
+	conn, err := p.DB.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = conn.ExecContext(ctx, `SELECT set_config('app.organization_id', $1, false)`, organizationID)
+	if err == nil {
+		_, err = conn.ExecContext(ctx, `SET ROLE `+tenantRole)
+	}
+	if err != nil {
+		discard(conn)
+		return nil, nil, err
+	}
+
+	release := func() {
+		if _, err := conn.ExecContext(context.Background(), `RESET ROLE; RESET app.organization_id`); err != nil {
+			discard(conn)
+			return
+		}
+		conn.Close()
+	}
+	return context.WithValue(ctx, tenantConnKey{}, conn), release, nil
+}
+
+// discard closes conn's driver connection instead of returning it to the
+// pool, where it would carry a tenant's settings into another request.
+func discard(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+// scoped returns the transaction carried by ctx, else the tenant connection
+// pinned to it, else db.
+func scoped(ctx context.Context, db *sql.DB) queryer {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return pinned(ctx, db)
+}
+
+// pinned returns the tenant connection pinned to ctx, or db. Transactions
+// begin on it so they run under the tenant's role and settings.
+func pinned(ctx context.Context, db *sql.DB) interface {
+	queryer
+	txBeginner
+} {
+	if conn, ok := ctx.Value(tenantConnKey{}).(*sql.Conn); ok {
+		return conn
+	}
+	return db
+}
+
+// tenantID is the caller's organization, or "" for an unscoped context such
+// as the worker's. Queries filter with COALESCE(NULLIF($n, ...)::uuid,
+// organization_id) so that "" matches every organization.
+func tenantID(ctx context.Context) string {
+	return auth.OrganizationID(ctx)
+}
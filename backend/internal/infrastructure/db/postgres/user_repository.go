@@ -3,8 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 
+	"github.com/lib/pq"
 	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
 )
 
 type UserRepositoryImpl struct {
@@ -17,23 +20,26 @@ func NewUserRepository(db *sql.DB) *UserRepositoryImpl {
 
 func (r *UserRepositoryImpl) Create(ctx context.Context, user *entities.User) error {
 	query := `
-		INSERT INTO users (id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO users (id, organization_id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		user.ID, user.Username, user.Email, user.PasswordHash, user.Role,
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
+		user.ID, user.OrganizationID, user.Username, user.Email, user.PasswordHash, user.Role,
 		user.FirstName, user.LastName, user.IsActive, user.CreatedAt, user.UpdatedAt)
+	if isUniqueViolation(err) {
+		return repositories.ErrDuplicate
+	}
 	return err
 }
 
 func (r *UserRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at
-		FROM users WHERE id = $1
+		SELECT id, organization_id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at
+		FROM users WHERE id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
 	`
 	user := &entities.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, id, tenantID(ctx)).Scan(
+		&user.ID, &user.OrganizationID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
 		&user.FirstName, &user.LastName, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == sql.ErrNoRows {
@@ -44,12 +50,12 @@ func (r *UserRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.
 
 func (r *UserRepositoryImpl) GetByUsername(ctx context.Context, username string) (*entities.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at
-		FROM users WHERE username = $1
+		SELECT id, organization_id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at
+		FROM users WHERE username = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
 	`
 	user := &entities.User{}
-	err := r.db.QueryRowContext(ctx, query, username).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, username, tenantID(ctx)).Scan(
+		&user.ID, &user.OrganizationID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
 		&user.FirstName, &user.LastName, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == sql.ErrNoRows {
@@ -60,12 +66,12 @@ func (r *UserRepositoryImpl) GetByUsername(ctx context.Context, username string)
 
 func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at
-		FROM users WHERE email = $1
+		SELECT id, organization_id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at
+		FROM users WHERE email = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)
 	`
 	user := &entities.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
+	err := scoped(ctx, r.db).QueryRowContext(ctx, query, email, tenantID(ctx)).Scan(
+		&user.ID, &user.OrganizationID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
 		&user.FirstName, &user.LastName, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == sql.ErrNoRows {
@@ -78,26 +84,27 @@ func (r *UserRepositoryImpl) Update(ctx context.Context, user *entities.User) er
 	query := `
 		UPDATE users 
 		SET email = $2, password_hash = $3, first_name = $4, last_name = $5, is_active = $6, updated_at = $7
-		WHERE id = $1
+		WHERE id = $1 AND organization_id = COALESCE(NULLIF($8, '')::uuid, organization_id)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query,
 		user.ID, user.Email, user.PasswordHash, user.FirstName, user.LastName,
-		user.IsActive, user.UpdatedAt)
+		user.IsActive, user.UpdatedAt, tenantID(ctx))
 	return err
 }
 
 func (r *UserRepositoryImpl) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM users WHERE id = $1 AND organization_id = COALESCE(NULLIF($2, '')::uuid, organization_id)`
+	_, err := scoped(ctx, r.db).ExecContext(ctx, query, id, tenantID(ctx))
 	return err
 }
 
 func (r *UserRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*entities.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at
-		FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2
+		SELECT id, organization_id, username, email, password_hash, role, first_name, last_name, is_active, created_at, updated_at
+		FROM users WHERE organization_id = COALESCE(NULLIF($3, '')::uuid, organization_id)
+		ORDER BY created_at DESC LIMIT $1 OFFSET $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := scoped(ctx, r.db).QueryContext(ctx, query, limit, offset, tenantID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +113,7 @@ func (r *UserRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*en
 	var users []*entities.User
 	for rows.Next() {
 		user := &entities.User{}
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
+		err := rows.Scan(&user.ID, &user.OrganizationID, &user.Username, &user.Email, &user.PasswordHash, &user.Role,
 			&user.FirstName, &user.LastName, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
 		if err != nil {
 			return nil, err
@@ -115,3 +122,8 @@ func (r *UserRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*en
 	}
 	return users, nil
 }
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
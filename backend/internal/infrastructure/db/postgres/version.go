@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/project-capillary/backend/internal/domain/repositories"
+)
+
+// checkVersioned reports ErrVersionConflict when an update guarded by a
+// version condition matched no row.
+func checkVersioned(result sql.Result) error {
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return repositories.ErrVersionConflict
+	}
+	return nil
+}
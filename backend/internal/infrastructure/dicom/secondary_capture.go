@@ -0,0 +1,145 @@
+package dicom
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"strconv"
+	"strings"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+const (
+	dateFormat = "20060102"
+	timeFormat = "150405"
+
+	maxLongString  = 64
+	maxShortString = 16
+)
+
+// NewSecondaryCapture wraps a captured image as a Secondary Capture Image
+// Storage instance. JPEG data is embedded as-is; other formats are re-encoded
+// to baseline JPEG first.
+func NewSecondaryCapture(patient *entities.Patient, examination *entities.Examination, img *entities.Image, instanceNumber int, data []byte) ([]byte, error) {
+	frame, config, err := jpegFrame(data)
+	if err != nil {
+		return nil, fmt.Errorf("image %s: %w", img.Filename, err)
+	}
+	if config.Width > 0xFFFF || config.Height > 0xFFFF {
+		return nil, fmt.Errorf("image %s: %dx%d exceeds DICOM dimensions", img.Filename, config.Width, config.Height)
+	}
+
+	sopInstanceUID := InstanceUID(img.ID)
+
+	ds := NewDataset()
+	ds.String(TagSpecificCharacterSet, "CS", "ISO_IR 192")
+	ds.Strings(TagImageType, "CS", "ORIGINAL", "PRIMARY")
+	ds.String(TagInstanceCreationDate, "DA", img.CreatedAt.Format(dateFormat))
+	ds.String(TagInstanceCreationTime, "TM", img.CreatedAt.Format(timeFormat))
+	ds.String(TagSOPClassUID, "UI", SecondaryCaptureSOPClass)
+	ds.String(TagSOPInstanceUID, "UI", sopInstanceUID)
+	ds.String(TagStudyDate, "DA", examination.CreatedAt.Format(dateFormat))
+	ds.String(TagStudyTime, "TM", examination.CreatedAt.Format(timeFormat))
+	ds.String(TagContentDate, "DA", img.CapturedAt.Format(dateFormat))
+	ds.String(TagContentTime, "TM", img.CapturedAt.Format(timeFormat))
+	ds.String(TagAccessionNumber, "SH", "")
+	ds.String(TagModality, "CS", "XC")
+	ds.String(TagConversionType, "CS", "DI")
+	ds.String(TagManufacturer, "LO", "")
+	ds.String(TagReferringPhysicianName, "PN", "")
+	ds.String(TagStudyDescription, "LO", truncate("Nailfold capillaroscopy "+examination.Description, maxLongString))
+	ds.String(TagSeriesDescription, "LO", "Nailfold capillaroscopy images")
+
+	ds.String(TagPatientName, "PN", personName(patient))
+	if patient.ExternalID != "" {
+		ds.String(TagPatientID, "LO", truncate(patient.ExternalID, maxLongString))
+		ds.String(TagIssuerOfPatientID, "LO", truncate(patient.ExternalSystem, maxLongString))
+	} else {
+		ds.String(TagPatientID, "LO", patient.ID)
+	}
+	ds.String(TagPatientBirthDate, "DA", patient.DateOfBirth.Format(dateFormat))
+	ds.String(TagPatientSex, "CS", patientSex(patient.Gender))
+
+	ds.String(TagStudyInstanceUID, "UI", StudyUID(examination.ID))
+	ds.String(TagSeriesInstanceUID, "UI", SeriesUID(examination.ID))
+	ds.String(TagStudyID, "SH", truncate(strings.ReplaceAll(examination.ID, "-", ""), maxShortString))
+	ds.String(TagSeriesNumber, "IS", "1")
+	ds.String(TagInstanceNumber, "IS", strconv.Itoa(instanceNumber))
+	ds.String(TagPatientOrientation, "CS", "")
+
+	if config.ColorModel == color.GrayModel {
+		ds.Uint16(TagSamplesPerPixel, 1)
+		ds.String(TagPhotometricInterpretation, "CS", "MONOCHROME2")
+	} else {
+		ds.Uint16(TagSamplesPerPixel, 3)
+		ds.String(TagPhotometricInterpretation, "CS", "YBR_FULL_422")
+		ds.Uint16(TagPlanarConfiguration, 0)
+	}
+	ds.Uint16(TagRows, uint16(config.Height))
+	ds.Uint16(TagColumns, uint16(config.Width))
+	ds.Uint16(TagBitsAllocated, 8)
+	ds.Uint16(TagBitsStored, 8)
+	ds.Uint16(TagHighBit, 7)
+	ds.Uint16(TagPixelRepresentation, 0)
+	ds.String(TagLossyImageCompression, "CS", "01")
+	ds.EncapsulatedPixelData(frame)
+
+	return WriteFile(ds, SecondaryCaptureSOPClass, sopInstanceUID, JPEGBaselineSyntax), nil
+}
+
+func jpegFrame(data []byte) ([]byte, image.Config, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, image.Config{}, fmt.Errorf("unsupported image data: %w", err)
+	}
+	if format == "jpeg" {
+		return data, config, nil
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, image.Config{}, fmt.Errorf("failed to decode %s: %w", format, err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, decoded, &jpeg.Options{Quality: 95}); err != nil {
+		return nil, image.Config{}, fmt.Errorf("failed to re-encode %s as JPEG: %w", format, err)
+	}
+
+	config, _, err = image.DecodeConfig(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, image.Config{}, err
+	}
+	return buf.Bytes(), config, nil
+}
+
+func personName(patient *entities.Patient) string {
+	components := []string{patient.LastName, patient.FirstName}
+	if patient.MiddleName != "" {
+		components = append(components, patient.MiddleName)
+	}
+	return strings.Join(components, "^")
+}
+
+func patientSex(gender string) string {
+	switch gender {
+	case "male":
+		return "M"
+	case "female":
+		return "F"
+	default:
+		return "O"
+	}
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(string(runes[:max]))
+}
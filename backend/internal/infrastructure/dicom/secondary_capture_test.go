@@ -0,0 +1,233 @@
+package dicom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+type parsedElement struct {
+	tag   Tag
+	vr    string
+	value []byte
+}
+
+// parseFile walks an explicit VR little endian Part 10 file produced by
+// WriteFile and returns its elements in file order.
+func parseFile(t *testing.T, data []byte) []parsedElement {
+	t.Helper()
+	if len(data) < 132 || string(data[128:132]) != "DICM" {
+		t.Fatal("missing DICM prefix")
+	}
+
+	var elements []parsedElement
+	pos := 132
+	for pos < len(data) {
+		tag := Tag{binary.LittleEndian.Uint16(data[pos:]), binary.LittleEndian.Uint16(data[pos+2:])}
+		vr := string(data[pos+4 : pos+6])
+		pos += 6
+
+		var length uint32
+		if hasLongLength(vr) {
+			length = binary.LittleEndian.Uint32(data[pos+2:])
+			pos += 6
+		} else {
+			length = uint32(binary.LittleEndian.Uint16(data[pos:]))
+			pos += 2
+		}
+
+		if length == 0xFFFFFFFF {
+			var fragments []byte
+			for {
+				itemTag := Tag{binary.LittleEndian.Uint16(data[pos:]), binary.LittleEndian.Uint16(data[pos+2:])}
+				itemLength := binary.LittleEndian.Uint32(data[pos+4:])
+				pos += 8
+				if itemTag == tagSequenceDelimitation {
+					break
+				}
+				fragments = data[pos : pos+int(itemLength)]
+				pos += int(itemLength)
+			}
+			elements = append(elements, parsedElement{tag: tag, vr: vr, value: fragments})
+			continue
+		}
+
+		elements = append(elements, parsedElement{tag: tag, vr: vr, value: data[pos : pos+int(length)]})
+		pos += int(length)
+	}
+	return elements
+}
+
+func find(elements []parsedElement, tag Tag) *parsedElement {
+	for i := range elements {
+		if elements[i].tag == tag {
+			return &elements[i]
+		}
+	}
+	return nil
+}
+
+func stringValue(t *testing.T, elements []parsedElement, tag Tag) string {
+	t.Helper()
+	el := find(elements, tag)
+	if el == nil {
+		t.Fatalf("element %s missing", tag)
+	}
+	return strings.TrimRight(string(el.value), " \x00")
+}
+
+func uint16Value(t *testing.T, elements []parsedElement, tag Tag) uint16 {
+	t.Helper()
+	el := find(elements, tag)
+	if el == nil {
+		t.Fatalf("element %s missing", tag)
+	}
+	return binary.LittleEndian.Uint16(el.value)
+}
+
+func encodeTestImage(t *testing.T, format string, model color.Model) []byte {
+	t.Helper()
+	var img image.Image
+	if model == color.GrayModel {
+		img = image.NewGray(image.Rect(0, 0, 33, 17))
+	} else {
+		rgba := image.NewRGBA(image.Rect(0, 0, 33, 17))
+		rgba.Set(1, 1, color.RGBA{R: 200, A: 255})
+		img = rgba
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func newTestEntities() (*entities.Patient, *entities.Examination, *entities.Image) {
+	dob := time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC)
+	patient := entities.NewPatient("2b8c3a2e-1d11-4c3b-8a6e-5f0d9e7c6b21", "Иван", "Иванов", "Иванович", dob, "male", "", "")
+	examination := entities.NewExamination("7d9f1c1e-4a44-4d55-9d0e-8f3b0c1e2a10", patient.ID, "9a1e2f3d-5c6b-4a7e-8d9c-0b1a2c3d4e5f", "левая рука")
+	img := entities.NewImage("0c1d2e3f-4a5b-4c6d-8e7f-901a2b3c4d5e", examination.ID, "photo_1.jpg", "/photos/photo_1.jpg", "image/jpeg", 0, 0, 0)
+	return patient, examination, img
+}
+
+func TestNewSecondaryCapture_JPEG(t *testing.T) {
+	patient, examination, img := newTestEntities()
+	data := encodeTestImage(t, "jpeg", color.RGBAModel)
+
+	file, err := NewSecondaryCapture(patient, examination, img, 1, data)
+	if err != nil {
+		t.Fatalf("NewSecondaryCapture() error = %v", err)
+	}
+	elements := parseFile(t, file)
+
+	metaLength := 0
+	for i, el := range elements {
+		if i > 0 && el.tag.Group == 0x0002 {
+			metaLength += 8 + len(el.value)
+			if hasLongLength(el.vr) {
+				metaLength += 4
+			}
+		}
+		if i > 0 {
+			prev := elements[i-1].tag
+			if el.tag.Group < prev.Group || (el.tag.Group == prev.Group && el.tag.Element <= prev.Element) {
+				t.Errorf("tag %s written after %s", el.tag, prev)
+			}
+		}
+		if len(el.value)%2 != 0 {
+			t.Errorf("tag %s has odd length %d", el.tag, len(el.value))
+		}
+	}
+	if got := binary.LittleEndian.Uint32(elements[0].value); int(got) != metaLength {
+		t.Errorf("group length = %d, want %d", got, metaLength)
+	}
+
+	if got := stringValue(t, elements, TagTransferSyntaxUID); got != JPEGBaselineSyntax {
+		t.Errorf("TransferSyntaxUID = %v, want %v", got, JPEGBaselineSyntax)
+	}
+	if got := stringValue(t, elements, TagSOPInstanceUID); got != InstanceUID(img.ID) {
+		t.Errorf("SOPInstanceUID = %v, want %v", got, InstanceUID(img.ID))
+	}
+	if got := stringValue(t, elements, TagStudyInstanceUID); got != StudyUID(examination.ID) {
+		t.Errorf("StudyInstanceUID = %v, want %v", got, StudyUID(examination.ID))
+	}
+	if got := stringValue(t, elements, TagPatientName); got != "Иванов^Иван^Иванович" {
+		t.Errorf("PatientName = %v", got)
+	}
+	if got := stringValue(t, elements, TagPatientSex); got != "M" {
+		t.Errorf("PatientSex = %v, want M", got)
+	}
+	if rows, cols := uint16Value(t, elements, TagRows), uint16Value(t, elements, TagColumns); rows != 17 || cols != 33 {
+		t.Errorf("Rows x Columns = %dx%d, want 17x33", rows, cols)
+	}
+	if got := stringValue(t, elements, TagPhotometricInterpretation); got != "YBR_FULL_422" {
+		t.Errorf("PhotometricInterpretation = %v", got)
+	}
+
+	pixel := find(elements, TagPixelData)
+	if pixel == nil || !bytes.Equal(bytes.TrimRight(pixel.value, "\x00"), bytes.TrimRight(data, "\x00")) {
+		t.Error("pixel data fragment must carry the original JPEG stream")
+	}
+
+	again, err := NewSecondaryCapture(patient, examination, img, 1, data)
+	if err != nil {
+		t.Fatalf("NewSecondaryCapture() error = %v", err)
+	}
+	if !bytes.Equal(file, again) {
+		t.Error("re-export of the same image must be byte-identical")
+	}
+}
+
+func TestNewSecondaryCapture_Conversions(t *testing.T) {
+	patient, examination, img := newTestEntities()
+
+	t.Run("png is re-encoded", func(t *testing.T) {
+		file, err := NewSecondaryCapture(patient, examination, img, 2, encodeTestImage(t, "png", color.RGBAModel))
+		if err != nil {
+			t.Fatalf("NewSecondaryCapture() error = %v", err)
+		}
+		elements := parseFile(t, file)
+		pixel := find(elements, TagPixelData)
+		if pixel == nil || !bytes.HasPrefix(pixel.value, []byte{0xFF, 0xD8}) {
+			t.Error("pixel data should be a JPEG stream")
+		}
+		if got := stringValue(t, elements, TagInstanceNumber); got != "2" {
+			t.Errorf("InstanceNumber = %v, want 2", got)
+		}
+	})
+
+	t.Run("grayscale", func(t *testing.T) {
+		file, err := NewSecondaryCapture(patient, examination, img, 1, encodeTestImage(t, "jpeg", color.GrayModel))
+		if err != nil {
+			t.Fatalf("NewSecondaryCapture() error = %v", err)
+		}
+		elements := parseFile(t, file)
+		if got := stringValue(t, elements, TagPhotometricInterpretation); got != "MONOCHROME2" {
+			t.Errorf("PhotometricInterpretation = %v, want MONOCHROME2", got)
+		}
+		if find(elements, TagPlanarConfiguration) != nil {
+			t.Error("PlanarConfiguration must be absent for single-sample images")
+		}
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		if _, err := NewSecondaryCapture(patient, examination, img, 1, []byte("not an image")); err == nil {
+			t.Error("NewSecondaryCapture() should reject non-image data")
+		}
+	})
+}
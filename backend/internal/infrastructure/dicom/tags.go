@@ -0,0 +1,58 @@
+package dicom
+
+var (
+	TagFileMetaInformationGroupLength = Tag{0x0002, 0x0000}
+	TagFileMetaInformationVersion     = Tag{0x0002, 0x0001}
+	TagMediaStorageSOPClassUID        = Tag{0x0002, 0x0002}
+	TagMediaStorageSOPInstanceUID     = Tag{0x0002, 0x0003}
+	TagTransferSyntaxUID              = Tag{0x0002, 0x0010}
+	TagImplementationClassUID         = Tag{0x0002, 0x0012}
+	TagImplementationVersionName      = Tag{0x0002, 0x0013}
+
+	TagSpecificCharacterSet   = Tag{0x0008, 0x0005}
+	TagImageType              = Tag{0x0008, 0x0008}
+	TagInstanceCreationDate   = Tag{0x0008, 0x0012}
+	TagInstanceCreationTime   = Tag{0x0008, 0x0013}
+	TagSOPClassUID            = Tag{0x0008, 0x0016}
+	TagSOPInstanceUID         = Tag{0x0008, 0x0018}
+	TagStudyDate              = Tag{0x0008, 0x0020}
+	TagContentDate            = Tag{0x0008, 0x0023}
+	TagStudyTime              = Tag{0x0008, 0x0030}
+	TagContentTime            = Tag{0x0008, 0x0033}
+	TagAccessionNumber        = Tag{0x0008, 0x0050}
+	TagModality               = Tag{0x0008, 0x0060}
+	TagConversionType         = Tag{0x0008, 0x0064}
+	TagManufacturer           = Tag{0x0008, 0x0070}
+	TagReferringPhysicianName = Tag{0x0008, 0x0090}
+	TagStudyDescription       = Tag{0x0008, 0x1030}
+	TagSeriesDescription      = Tag{0x0008, 0x103E}
+
+	TagPatientName       = Tag{0x0010, 0x0010}
+	TagPatientID         = Tag{0x0010, 0x0020}
+	TagIssuerOfPatientID = Tag{0x0010, 0x0021}
+	TagPatientBirthDate  = Tag{0x0010, 0x0030}
+	TagPatientSex        = Tag{0x0010, 0x0040}
+
+	TagStudyInstanceUID   = Tag{0x0020, 0x000D}
+	TagSeriesInstanceUID  = Tag{0x0020, 0x000E}
+	TagStudyID            = Tag{0x0020, 0x0010}
+	TagSeriesNumber       = Tag{0x0020, 0x0011}
+	TagInstanceNumber     = Tag{0x0020, 0x0013}
+	TagPatientOrientation = Tag{0x0020, 0x0020}
+
+	TagSamplesPerPixel           = Tag{0x0028, 0x0002}
+	TagPhotometricInterpretation = Tag{0x0028, 0x0004}
+	TagPlanarConfiguration       = Tag{0x0028, 0x0006}
+	TagRows                      = Tag{0x0028, 0x0010}
+	TagColumns                   = Tag{0x0028, 0x0011}
+	TagBitsAllocated             = Tag{0x0028, 0x0100}
+	TagBitsStored                = Tag{0x0028, 0x0101}
+	TagHighBit                   = Tag{0x0028, 0x0102}
+	TagPixelRepresentation       = Tag{0x0028, 0x0103}
+	TagLossyImageCompression     = Tag{0x0028, 0x2110}
+
+	TagPixelData = Tag{0x7FE0, 0x0010}
+
+	tagItem                 = Tag{0xFFFE, 0xE000}
+	tagSequenceDelimitation = Tag{0xFFFE, 0xE0DD}
+)
@@ -0,0 +1,41 @@
+package dicom
+
+import (
+	"math/big"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SecondaryCaptureSOPClass = "1.2.840.10008.5.1.4.1.1.7"
+	JPEGBaselineSyntax       = "1.2.840.10008.1.2.4.50"
+	ImplementationVersion    = "CAPILLARY_1"
+)
+
+var ImplementationClassUID = oidFromUUID(uuid.NewSHA1(uuid.NameSpaceURL, []byte("urn:project-capillary:dicom")))
+
+// StudyUID, SeriesUID and InstanceUID derive DICOM UIDs from entity IDs using
+// the 2.25 UUID arc, so repeated exports always carry the same identifiers.
+func StudyUID(examinationID string) string {
+	return oidFromUUID(stableUUID(examinationID, "study"))
+}
+
+func SeriesUID(examinationID string) string {
+	return oidFromUUID(stableUUID(examinationID, "series"))
+}
+
+func InstanceUID(imageID string) string {
+	return oidFromUUID(stableUUID(imageID, "instance"))
+}
+
+func stableUUID(id, kind string) uuid.UUID {
+	namespace, err := uuid.Parse(id)
+	if err != nil {
+		namespace = uuid.NewSHA1(uuid.NameSpaceOID, []byte(id))
+	}
+	return uuid.NewSHA1(namespace, []byte(kind))
+}
+
+func oidFromUUID(u uuid.UUID) string {
+	return "2.25." + new(big.Int).SetBytes(u[:]).String()
+}
@@ -0,0 +1,179 @@
+package dicom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Tag struct {
+	Group   uint16
+	Element uint16
+}
+
+func (t Tag) String() string {
+	return fmt.Sprintf("(%04X,%04X)", t.Group, t.Element)
+}
+
+type element struct {
+	tag   Tag
+	vr    string
+	value []byte
+	// encapsulated marks pixel data written as undefined-length fragments.
+	encapsulated bool
+}
+
+// Dataset collects elements and serialises them in ascending tag order using
+// explicit VR little endian, which is what JPEG transfer syntaxes require.
+type Dataset struct {
+	elements map[Tag]element
+}
+
+func NewDataset() *Dataset {
+	return &Dataset{elements: make(map[Tag]element)}
+}
+
+func (d *Dataset) String(tag Tag, vr, value string) {
+	padding := byte(' ')
+	if vr == "UI" {
+		padding = 0
+	}
+	data := []byte(value)
+	if len(data)%2 == 1 {
+		data = append(data, padding)
+	}
+	d.elements[tag] = element{tag: tag, vr: vr, value: data}
+}
+
+func (d *Dataset) Strings(tag Tag, vr string, values ...string) {
+	d.String(tag, vr, strings.Join(values, `\`))
+}
+
+func (d *Dataset) Uint16(tag Tag, value uint16) {
+	data := make([]byte, 2)
+	binary.LittleEndian.PutUint16(data, value)
+	d.elements[tag] = element{tag: tag, vr: "US", value: data}
+}
+
+func (d *Dataset) Uint32(tag Tag, value uint32) {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, value)
+	d.elements[tag] = element{tag: tag, vr: "UL", value: data}
+}
+
+func (d *Dataset) Bytes(tag Tag, vr string, value []byte) {
+	data := value
+	if len(data)%2 == 1 {
+		data = append(append([]byte{}, data...), 0)
+	}
+	d.elements[tag] = element{tag: tag, vr: vr, value: data}
+}
+
+// EncapsulatedPixelData stores a single compressed frame with an empty basic
+// offset table, as permitted for single-frame images.
+func (d *Dataset) EncapsulatedPixelData(frame []byte) {
+	d.elements[TagPixelData] = element{tag: TagPixelData, vr: "OB", value: frame, encapsulated: true}
+}
+
+func (d *Dataset) Encode() []byte {
+	tags := make([]Tag, 0, len(d.elements))
+	for tag := range d.elements {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Group != tags[j].Group {
+			return tags[i].Group < tags[j].Group
+		}
+		return tags[i].Element < tags[j].Element
+	})
+
+	var buf bytes.Buffer
+	for _, tag := range tags {
+		writeElement(&buf, d.elements[tag])
+	}
+	return buf.Bytes()
+}
+
+func writeElement(buf *bytes.Buffer, el element) {
+	writeTag(buf, el.tag)
+	buf.WriteString(el.vr)
+
+	if el.encapsulated {
+		buf.Write([]byte{0, 0})
+		writeUint32(buf, 0xFFFFFFFF)
+
+		writeTag(buf, tagItem)
+		writeUint32(buf, 0)
+
+		frame := el.value
+		if len(frame)%2 == 1 {
+			frame = append(append([]byte{}, frame...), 0)
+		}
+		writeTag(buf, tagItem)
+		writeUint32(buf, uint32(len(frame)))
+		buf.Write(frame)
+
+		writeTag(buf, tagSequenceDelimitation)
+		writeUint32(buf, 0)
+		return
+	}
+
+	if hasLongLength(el.vr) {
+		buf.Write([]byte{0, 0})
+		writeUint32(buf, uint32(len(el.value)))
+	} else {
+		writeUint16(buf, uint16(len(el.value)))
+	}
+	buf.Write(el.value)
+}
+
+func hasLongLength(vr string) bool {
+	switch vr {
+	case "OB", "OD", "OF", "OL", "OW", "SQ", "UC", "UN", "UR", "UT":
+		return true
+	}
+	return false
+}
+
+func writeTag(buf *bytes.Buffer, tag Tag) {
+	writeUint16(buf, tag.Group)
+	writeUint16(buf, tag.Element)
+}
+
+func writeUint16(buf *bytes.Buffer, value uint16) {
+	var data [2]byte
+	binary.LittleEndian.PutUint16(data[:], value)
+	buf.Write(data[:])
+}
+
+func writeUint32(buf *bytes.Buffer, value uint32) {
+	var data [4]byte
+	binary.LittleEndian.PutUint32(data[:], value)
+	buf.Write(data[:])
+}
+
+// WriteFile produces a DICOM Part 10 file: preamble, DICM prefix, file meta
+// information group and the dataset.
+func WriteFile(dataset *Dataset, sopClassUID, sopInstanceUID, transferSyntaxUID string) []byte {
+	meta := NewDataset()
+	meta.Bytes(TagFileMetaInformationVersion, "OB", []byte{0x00, 0x01})
+	meta.String(TagMediaStorageSOPClassUID, "UI", sopClassUID)
+	meta.String(TagMediaStorageSOPInstanceUID, "UI", sopInstanceUID)
+	meta.String(TagTransferSyntaxUID, "UI", transferSyntaxUID)
+	meta.String(TagImplementationClassUID, "UI", ImplementationClassUID)
+	meta.String(TagImplementationVersionName, "SH", ImplementationVersion)
+	metaBytes := meta.Encode()
+
+	group := NewDataset()
+	group.Uint32(TagFileMetaInformationGroupLength, uint32(len(metaBytes)))
+
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 128))
+	buf.WriteString("DICM")
+	buf.Write(group.Encode())
+	buf.Write(metaBytes)
+	buf.Write(dataset.Encode())
+	return buf.Bytes()
+}
@@ -0,0 +1,128 @@
+package fhir
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type PatientDemographics struct {
+	ID             string
+	ExternalSystem string
+	ExternalID     string
+	FirstName      string
+	LastName       string
+	MiddleName     string
+	DateOfBirth    time.Time
+	Gender         string
+	Phone          string
+	Email          string
+	// HasTelecom is false when the resource has no telecom element, in
+	// which case Phone and Email say nothing about the stored contacts.
+	HasTelecom bool
+}
+
+func ParsePatient(data []byte) (*Patient, error) {
+	if err := ValidateJSON(data); err != nil {
+		return nil, err
+	}
+
+	var resource Patient
+	if err := json.Unmarshal(data, &resource); err != nil {
+		return nil, err
+	}
+	if resource.ResourceType != "Patient" {
+		return nil, fmt.Errorf("expected resourceType Patient, got %q", resource.ResourceType)
+	}
+	return &resource, nil
+}
+
+// PatientDemographicsFrom extracts the fields stored on entities.Patient.
+// An identifier in our own system yields the internal ID; otherwise the first
+// official (or usual, or any) identifier becomes the external identifier used
+// for matching.
+func PatientDemographicsFrom(resource *Patient) (*PatientDemographics, error) {
+	demographics := &PatientDemographics{}
+
+	external := selectIdentifier(resource.Identifier)
+	for _, identifier := range resource.Identifier {
+		if identifier.System == SystemPatientID {
+			demographics.ID = identifier.Value
+		}
+	}
+	if external != nil {
+		demographics.ExternalSystem = external.System
+		demographics.ExternalID = external.Value
+	}
+	if demographics.ID == "" && demographics.ExternalID == "" {
+		return nil, fmt.Errorf("identifier with system and value is required")
+	}
+
+	name := selectName(resource.Name)
+	if name == nil || name.Family == "" || len(name.Given) == 0 {
+		return nil, fmt.Errorf("name with family and given is required")
+	}
+	demographics.LastName = name.Family
+	demographics.FirstName = name.Given[0]
+	demographics.MiddleName = strings.Join(name.Given[1:], " ")
+
+	birthDate, err := time.Parse("2006-01-02", resource.BirthDate)
+	if err != nil {
+		return nil, fmt.Errorf("birthDate must be a full date (YYYY-MM-DD)")
+	}
+	demographics.DateOfBirth = birthDate
+
+	switch resource.Gender {
+	case "male", "female", "other":
+		demographics.Gender = resource.Gender
+	case "unknown":
+		demographics.Gender = "other"
+	default:
+		return nil, fmt.Errorf("gender is required")
+	}
+
+	demographics.HasTelecom = resource.Telecom != nil
+	for _, telecom := range resource.Telecom {
+		switch {
+		case telecom.System == "phone" && demographics.Phone == "":
+			demographics.Phone = telecom.Value
+		case telecom.System == "email" && demographics.Email == "":
+			demographics.Email = telecom.Value
+		}
+	}
+
+	return demographics, nil
+}
+
+func selectIdentifier(identifiers []Identifier) *Identifier {
+	var candidates []Identifier
+	for _, identifier := range identifiers {
+		if identifier.System != "" && identifier.Value != "" && identifier.System != SystemPatientID {
+			candidates = append(candidates, identifier)
+		}
+	}
+	for _, use := range []string{"official", "usual"} {
+		for i := range candidates {
+			if candidates[i].Use == use {
+				return &candidates[i]
+			}
+		}
+	}
+	if len(candidates) > 0 {
+		return &candidates[0]
+	}
+	return nil
+}
+
+func selectName(names []HumanName) *HumanName {
+	for i := range names {
+		if names[i].Use == "official" {
+			return &names[i]
+		}
+	}
+	if len(names) > 0 {
+		return &names[0]
+	}
+	return nil
+}
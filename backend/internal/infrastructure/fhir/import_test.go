@@ -0,0 +1,120 @@
+package fhir
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+func TestPatientDemographicsFrom_RoundTrip(t *testing.T) {
+	dob := time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC)
+	patient := entities.NewPatient("2b8c3a2e-1d11-4c3b-8a6e-5f0d9e7c6b21", "Иван", "Иванов", "Иванович", dob, "male", "+79991234567", "ivan@example.com")
+	patient.SetExternalIdentifier("urn:oid:1.2.643.5.1.13", "MRN-42")
+
+	data, err := json.Marshal(PatientResource(patient))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	resource, err := ParsePatient(data)
+	if err != nil {
+		t.Fatalf("ParsePatient() error = %v", err)
+	}
+	demographics, err := PatientDemographicsFrom(resource)
+	if err != nil {
+		t.Fatalf("PatientDemographicsFrom() error = %v", err)
+	}
+
+	if demographics.ID != patient.ID {
+		t.Errorf("ID = %v, want %v", demographics.ID, patient.ID)
+	}
+	if demographics.ExternalSystem != patient.ExternalSystem || demographics.ExternalID != patient.ExternalID {
+		t.Errorf("external identifier = %v|%v, want %v|%v", demographics.ExternalSystem, demographics.ExternalID, patient.ExternalSystem, patient.ExternalID)
+	}
+	if demographics.MiddleName != "Иванович" || demographics.Phone != patient.Phone || demographics.Email != patient.Email {
+		t.Errorf("demographics = %+v", demographics)
+	}
+	if !demographics.DateOfBirth.Equal(dob) {
+		t.Errorf("DateOfBirth = %v, want %v", demographics.DateOfBirth, dob)
+	}
+}
+
+func TestPatientDemographicsFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		wantErr     bool
+		wantID      string
+		wantGender  string
+		wantTelecom bool
+		wantPhone   string
+	}{
+		{
+			name:       "prefers official identifier",
+			json:       `{"resourceType":"Patient","identifier":[{"use":"secondary","system":"urn:a","value":"1"},{"use":"official","system":"urn:b","value":"2"}],"name":[{"family":"Petrova","given":["Anna"]}],"gender":"unknown","birthDate":"1970-01-02"}`,
+			wantID:     "2",
+			wantGender: "other",
+		},
+		{
+			name:        "telecom without phone or email",
+			json:        `{"resourceType":"Patient","identifier":[{"system":"urn:a","value":"1"}],"name":[{"family":"Petrova","given":["Anna"]}],"gender":"female","birthDate":"1970-01-02","telecom":[{"system":"fax","value":"123"}]}`,
+			wantID:      "1",
+			wantGender:  "female",
+			wantTelecom: true,
+		},
+		{
+			name:        "first phone wins",
+			json:        `{"resourceType":"Patient","identifier":[{"system":"urn:a","value":"1"}],"name":[{"family":"Petrova","given":["Anna"]}],"gender":"female","birthDate":"1970-01-02","telecom":[{"system":"phone","value":"+7 1"},{"system":"phone","value":"+7 2"}]}`,
+			wantID:      "1",
+			wantGender:  "female",
+			wantTelecom: true,
+			wantPhone:   "+7 1",
+		},
+		{
+			name:    "missing identifier",
+			json:    `{"resourceType":"Patient","name":[{"family":"Petrova","given":["Anna"]}],"gender":"female","birthDate":"1970-01-02"}`,
+			wantErr: true,
+		},
+		{
+			name:    "partial birth date",
+			json:    `{"resourceType":"Patient","identifier":[{"system":"urn:a","value":"1"}],"name":[{"family":"Petrova","given":["Anna"]}],"gender":"female","birthDate":"1970"}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing given name",
+			json:    `{"resourceType":"Patient","identifier":[{"system":"urn:a","value":"1"}],"name":[{"family":"Petrova"}],"gender":"female","birthDate":"1970-01-02"}`,
+			wantErr: true,
+		},
+		{
+			name:    "wrong resource type",
+			json:    `{"resourceType":"Observation","status":"final","code":{"text":"x"}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := ParsePatient([]byte(tt.json))
+			var demographics *PatientDemographics
+			if err == nil {
+				demographics, err = PatientDemographicsFrom(resource)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if demographics.ExternalID != tt.wantID {
+				t.Errorf("ExternalID = %v, want %v", demographics.ExternalID, tt.wantID)
+			}
+			if demographics.Gender != tt.wantGender {
+				t.Errorf("Gender = %v, want %v", demographics.Gender, tt.wantGender)
+			}
+			if demographics.HasTelecom != tt.wantTelecom || demographics.Phone != tt.wantPhone {
+				t.Errorf("HasTelecom, Phone = %v, %q, want %v, %q", demographics.HasTelecom, demographics.Phone, tt.wantTelecom, tt.wantPhone)
+			}
+		})
+	}
+}
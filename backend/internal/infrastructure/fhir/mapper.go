@@ -0,0 +1,276 @@
+package fhir
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/dicom"
+)
+
+const (
+	SystemPatientID        = "urn:project-capillary:patient"
+	SystemExaminationID    = "urn:project-capillary:examination"
+	SystemCapillaryMetrics = "urn:project-capillary:capillary-metrics"
+	SystemDICOMUID         = "urn:dicom:uid"
+	SystemDICOMModality    = "http://dicom.nema.org/resources/ontology/DCM"
+	SystemUCUM             = "http://unitsofmeasure.org"
+	SystemObsCategory      = "http://terminology.hl7.org/CodeSystem/observation-category"
+	SystemRFC3986          = "urn:ietf:rfc:3986"
+)
+
+type Metric struct {
+	Key     string
+	Code    string
+	Display string
+	Unit    string
+}
+
+// CapillaryMetrics maps analysis metric keys to the local code system used in
+// Observation.code, in the order they are exported.
+var CapillaryMetrics = []Metric{
+	{Key: "density", Code: "CAP001", Display: "Nailfold capillary density", Unit: "/mm2"},
+	{Key: "diameter", Code: "CAP002", Display: "Nailfold capillary diameter", Unit: "um"},
+	{Key: "tortuosity", Code: "CAP003", Display: "Nailfold capillary tortuosity index", Unit: "1"},
+	{Key: "regularity", Code: "CAP004", Display: "Nailfold capillary regularity index", Unit: "1"},
+	{Key: "visibility", Code: "CAP005", Display: "Nailfold capillary visibility index", Unit: "1"},
+}
+
+var (
+	capillaroscopyProcedure = CodeableConcept{
+		Coding: []Coding{{System: SystemCapillaryMetrics, Code: "CAPPROC", Display: "Nailfold videocapillaroscopy"}},
+		Text:   "Nailfold videocapillaroscopy",
+	}
+	capillaroscopyReport = CodeableConcept{
+		Coding: []Coding{{System: SystemCapillaryMetrics, Code: "CAPREPORT", Display: "Nailfold capillaroscopy report"}},
+		Text:   "Nailfold capillaroscopy report",
+	}
+	imagingCategory = CodeableConcept{
+		Coding: []Coding{{System: SystemObsCategory, Code: "imaging", Display: "Imaging"}},
+	}
+	externalCameraModality = Coding{System: SystemDICOMModality, Code: "XC", Display: "External-camera Photography"}
+)
+
+func PatientResource(patient *entities.Patient) *Patient {
+	given := []string{patient.FirstName}
+	if patient.MiddleName != "" {
+		given = append(given, patient.MiddleName)
+	}
+
+	resource := &Patient{
+		ResourceType: "Patient",
+		ID:           patient.ID,
+		Meta:         &Meta{LastUpdated: formatDateTime(patient.UpdatedAt)},
+		Identifier: []Identifier{
+			{Use: "usual", System: SystemPatientID, Value: patient.ID},
+		},
+		Name: []HumanName{
+			{Use: "official", Text: patient.FullName(), Family: patient.LastName, Given: given},
+		},
+		Gender:    patient.Gender,
+		BirthDate: patient.DateOfBirth.Format("2006-01-02"),
+	}
+
+	if patient.ExternalID != "" {
+		resource.Identifier = append(resource.Identifier, Identifier{Use: "official", System: patient.ExternalSystem, Value: patient.ExternalID})
+	}
+	if patient.Phone != "" {
+		resource.Telecom = append(resource.Telecom, ContactPoint{System: "phone", Value: patient.Phone})
+	}
+	if patient.Email != "" {
+		resource.Telecom = append(resource.Telecom, ContactPoint{System: "email", Value: patient.Email})
+	}
+	return resource
+}
+
+func ImagingStudyResource(examination *entities.Examination, images []*entities.Image) *ImagingStudy {
+	instances := make([]ImagingStudyInstance, 0, len(images))
+	for i, image := range images {
+		instances = append(instances, ImagingStudyInstance{
+			UID:      dicom.InstanceUID(image.ID),
+			SOPClass: Coding{System: SystemRFC3986, Code: "urn:oid:" + dicom.SecondaryCaptureSOPClass},
+			Number:   i + 1,
+			Title:    image.Filename,
+		})
+	}
+
+	study := &ImagingStudy{
+		ResourceType: "ImagingStudy",
+		ID:           examination.ID,
+		Meta:         &Meta{LastUpdated: formatDateTime(examination.UpdatedAt)},
+		Identifier: []Identifier{
+			{Use: "official", System: SystemDICOMUID, Value: "urn:oid:" + dicom.StudyUID(examination.ID)},
+			{Use: "usual", System: SystemExaminationID, Value: examination.ID},
+		},
+		Status:             imagingStudyStatus(examination.Status),
+		Modality:           []Coding{externalCameraModality},
+		Subject:            Reference{Reference: "Patient/" + examination.PatientID},
+		Started:            formatDateTime(examination.CreatedAt),
+		Interpreter:        []Reference{{Reference: "Practitioner/" + examination.DoctorID}},
+		NumberOfInstances:  len(instances),
+		ProcedureReference: &Reference{Reference: "Procedure/" + examination.ID},
+		Description:        examination.Description,
+	}
+
+	if len(instances) > 0 {
+		study.NumberOfSeries = 1
+		study.Series = []ImagingStudySeries{
+			{
+				UID:               dicom.SeriesUID(examination.ID),
+				Number:            1,
+				Modality:          externalCameraModality,
+				Description:       "Nailfold capillaroscopy images",
+				NumberOfInstances: len(instances),
+				Instance:          instances,
+			},
+		}
+	}
+	return study
+}
+
+func ProcedureResource(examination *entities.Examination) *Procedure {
+	return &Procedure{
+		ResourceType:      "Procedure",
+		ID:                examination.ID,
+		Meta:              &Meta{LastUpdated: formatDateTime(examination.UpdatedAt)},
+		Status:            procedureStatus(examination.Status),
+		Code:              capillaroscopyProcedure,
+		Subject:           Reference{Reference: "Patient/" + examination.PatientID},
+		PerformedDateTime: formatDateTime(examination.CreatedAt),
+		Performer: []ProcedurePerformer{
+			{Actor: Reference{Reference: "Practitioner/" + examination.DoctorID}},
+		},
+	}
+}
+
+// ObservationResources emits one Observation per known metric of every
+// completed analysis. Metrics missing from an analysis are skipped.
+func ObservationResources(examination *entities.Examination, analyses []*entities.Analysis) []*Observation {
+	var observations []*Observation
+	for _, analysis := range analyses {
+		if analysis.Status != entities.AnalysisStatusCompleted {
+			continue
+		}
+
+		effective := analysis.UpdatedAt
+		if analysis.CompletedAt != nil {
+			effective = *analysis.CompletedAt
+		}
+
+		for _, metric := range CapillaryMetrics {
+			value, ok := analysis.Metrics[metric.Key].(float64)
+			if !ok {
+				continue
+			}
+
+			observations = append(observations, &Observation{
+				ResourceType: "Observation",
+				ID:           analysis.ID + "-" + metric.Key,
+				Status:       "final",
+				Category:     []CodeableConcept{imagingCategory},
+				Code: CodeableConcept{
+					Coding: []Coding{{System: SystemCapillaryMetrics, Code: metric.Code, Display: metric.Display}},
+					Text:   metric.Display,
+				},
+				Subject:           Reference{Reference: "Patient/" + examination.PatientID},
+				EffectiveDateTime: formatDateTime(effective),
+				Issued:            formatDateTime(effective),
+				ValueQuantity: &Quantity{
+					Value:  value,
+					Unit:   metric.Unit,
+					System: SystemUCUM,
+					Code:   metric.Unit,
+				},
+				DerivedFrom: []Reference{{Reference: "ImagingStudy/" + examination.ID}},
+			})
+		}
+	}
+	return observations
+}
+
+func DiagnosticReportResource(report *entities.Report, examination *entities.Examination, observations []*Observation) *DiagnosticReport {
+	status := "preliminary"
+	issued := report.UpdatedAt
+	if report.IsSigned() {
+		status = "final"
+		if report.SignedAt != nil {
+			issued = *report.SignedAt
+		}
+	}
+
+	resource := &DiagnosticReport{
+		ResourceType:      "DiagnosticReport",
+		ID:                report.ID,
+		Meta:              &Meta{LastUpdated: formatDateTime(report.UpdatedAt)},
+		Status:            status,
+		Code:              capillaroscopyReport,
+		Subject:           Reference{Reference: "Patient/" + examination.PatientID},
+		EffectiveDateTime: formatDateTime(examination.CreatedAt),
+		Issued:            formatDateTime(issued),
+		Performer:         []Reference{{Reference: "Practitioner/" + report.GeneratedBy}},
+		ImagingStudy:      []Reference{{Reference: "ImagingStudy/" + examination.ID}},
+		Conclusion:        report.Diagnosis,
+	}
+
+	if report.SignedBy != "" {
+		resource.ResultsInterpreter = []Reference{{Reference: "Practitioner/" + report.SignedBy}}
+	}
+	for _, observation := range observations {
+		resource.Result = append(resource.Result, Reference{Reference: "Observation/" + observation.ID})
+	}
+	if report.Content != "" {
+		resource.PresentedForm = []Attachment{
+			{
+				ContentType: "text/plain; charset=utf-8",
+				Data:        base64.StdEncoding.EncodeToString([]byte(report.Content)),
+				Title:       report.Title,
+			},
+		}
+	}
+	return resource
+}
+
+func NewBundle(id string, timestamp time.Time) *Bundle {
+	return &Bundle{
+		ResourceType: "Bundle",
+		ID:           id,
+		Type:         "collection",
+		Timestamp:    formatDateTime(timestamp),
+	}
+}
+
+func (b *Bundle) Add(baseURL, resourceType, id string, resource interface{}) {
+	b.Entry = append(b.Entry, BundleEntry{
+		FullURL:  strings.TrimRight(baseURL, "/") + "/" + resourceType + "/" + id,
+		Resource: resource,
+	})
+}
+
+func imagingStudyStatus(status entities.ExaminationStatus) string {
+	switch status {
+	case entities.StatusCompleted:
+		return "available"
+	case entities.StatusFailed:
+		return "cancelled"
+	default:
+		return "registered"
+	}
+}
+
+func procedureStatus(status entities.ExaminationStatus) string {
+	switch status {
+	case entities.StatusInProgress:
+		return "in-progress"
+	case entities.StatusCompleted:
+		return "completed"
+	case entities.StatusFailed:
+		return "stopped"
+	default:
+		return "preparation"
+	}
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
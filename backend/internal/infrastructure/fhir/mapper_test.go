@@ -0,0 +1,176 @@
+package fhir
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/dicom"
+)
+
+func newTestExamination() *entities.Examination {
+	examination := entities.NewExamination(
+		"7d9f1c1e-4a44-4d55-9d0e-8f3b0c1e2a10",
+		"2b8c3a2e-1d11-4c3b-8a6e-5f0d9e7c6b21",
+		"9a1e2f3d-5c6b-4a7e-8d9c-0b1a2c3d4e5f",
+		"Контрольное обследование",
+	)
+	examination.Complete()
+	return examination
+}
+
+func newTestImages() []*entities.Image {
+	return []*entities.Image{
+		entities.NewImage("0c1d2e3f-4a5b-4c6d-8e7f-901a2b3c4d5e", "exam", "photo_1.jpg", "/photos/photo_1.jpg", "image/jpeg", 1024, 640, 480),
+		entities.NewImage("1d2e3f4a-5b6c-4d7e-8f90-1a2b3c4d5e6f", "exam", "photo_2.jpg", "/photos/photo_2.jpg", "image/jpeg", 2048, 640, 480),
+	}
+}
+
+func newTestAnalyses(examinationID string, images []*entities.Image) []*entities.Analysis {
+	completed := entities.NewAnalysis("5e6f7a8b-9c0d-4e1f-8a2b-3c4d5e6f7a8b", examinationID, images[0].ID)
+	completed.Complete(map[string]interface{}{
+		"density":       8.4,
+		"diameter":      12.1,
+		"tortuosity":    1.3,
+		"regularity":    0.9,
+		"visibility":    0.95,
+		"abnormalities": []string{},
+	})
+	pending := entities.NewAnalysis("6f7a8b9c-0d1e-4f2a-8b3c-4d5e6f7a8b9c", examinationID, images[1].ID)
+	return []*entities.Analysis{completed, pending}
+}
+
+func assertValid(t *testing.T, resource interface{}) {
+	t.Helper()
+	if err := Validate(resource); err != nil {
+		data, _ := json.MarshalIndent(resource, "", "  ")
+		t.Fatalf("Validate() error = %v\n%s", err, data)
+	}
+}
+
+func TestPatientResource(t *testing.T) {
+	dob := time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("full demographics", func(t *testing.T) {
+		patient := entities.NewPatient("2b8c3a2e-1d11-4c3b-8a6e-5f0d9e7c6b21", "Иван", "Иванов", "Иванович", dob, "male", "+79991234567", "ivan@example.com")
+		resource := PatientResource(patient)
+		assertValid(t, resource)
+
+		if resource.BirthDate != "1990-05-15" {
+			t.Errorf("BirthDate = %v, want %v", resource.BirthDate, "1990-05-15")
+		}
+		if got := resource.Name[0].Given; len(got) != 2 || got[1] != "Иванович" {
+			t.Errorf("Given = %v, want first and middle name", got)
+		}
+		if len(resource.Telecom) != 2 {
+			t.Errorf("Telecom = %v, want phone and email", resource.Telecom)
+		}
+	})
+
+	t.Run("without optional fields", func(t *testing.T) {
+		patient := entities.NewPatient("2b8c3a2e-1d11-4c3b-8a6e-5f0d9e7c6b21", "Ivan", "Ivanov", "", dob, "other", "", "")
+		resource := PatientResource(patient)
+		assertValid(t, resource)
+
+		if resource.Telecom != nil {
+			t.Errorf("Telecom = %v, want none", resource.Telecom)
+		}
+	})
+}
+
+func TestImagingStudyResource(t *testing.T) {
+	examination := newTestExamination()
+	images := newTestImages()
+
+	study := ImagingStudyResource(examination, images)
+	assertValid(t, study)
+
+	if study.Status != "available" {
+		t.Errorf("Status = %v, want %v", study.Status, "available")
+	}
+	if study.NumberOfInstances != 2 || len(study.Series) != 1 {
+		t.Fatalf("NumberOfInstances = %d, series = %d, want 2 instances in 1 series", study.NumberOfInstances, len(study.Series))
+	}
+
+	again := ImagingStudyResource(examination, images)
+	if again.Series[0].UID != study.Series[0].UID || again.Series[0].Instance[0].UID != study.Series[0].Instance[0].UID {
+		t.Error("UIDs must be stable across exports")
+	}
+	if dicom.StudyUID(examination.ID) == dicom.SeriesUID(examination.ID) {
+		t.Error("study and series UIDs must differ")
+	}
+
+	empty := ImagingStudyResource(entities.NewExamination("e", "p", "d", ""), nil)
+	assertValid(t, empty)
+	if empty.Status != "registered" {
+		t.Errorf("Status = %v, want %v", empty.Status, "registered")
+	}
+}
+
+func TestObservationResources(t *testing.T) {
+	examination := newTestExamination()
+	images := newTestImages()
+
+	observations := ObservationResources(examination, newTestAnalyses(examination.ID, images))
+	if len(observations) != len(CapillaryMetrics) {
+		t.Fatalf("len(observations) = %d, want %d (pending analyses skipped)", len(observations), len(CapillaryMetrics))
+	}
+
+	for _, observation := range observations {
+		assertValid(t, observation)
+		if observation.ValueQuantity == nil || observation.ValueQuantity.System != SystemUCUM {
+			t.Errorf("%s: ValueQuantity = %+v, want UCUM quantity", observation.ID, observation.ValueQuantity)
+		}
+	}
+	if observations[0].Code.Coding[0].Code != "CAP001" || observations[0].ValueQuantity.Value != 8.4 {
+		t.Errorf("first observation = %+v, want density 8.4", observations[0])
+	}
+}
+
+func TestDiagnosticReportResource(t *testing.T) {
+	examination := newTestExamination()
+	observations := ObservationResources(examination, newTestAnalyses(examination.ID, newTestImages()))
+	report := entities.NewReport("8b9c0d1e-2f3a-4b4c-8d5e-6f7a8b9c0d1e", examination.ID, "Отчёт", "Содержимое", "Итог", "Норма", "Наблюдение", "9a1e2f3d-5c6b-4a7e-8d9c-0b1a2c3d4e5f")
+
+	t.Run("draft report", func(t *testing.T) {
+		resource := DiagnosticReportResource(report, examination, observations)
+		assertValid(t, resource)
+
+		if resource.Status != "preliminary" {
+			t.Errorf("Status = %v, want %v", resource.Status, "preliminary")
+		}
+		if len(resource.Result) != len(observations) {
+			t.Errorf("len(Result) = %d, want %d", len(resource.Result), len(observations))
+		}
+	})
+
+	t.Run("signed report", func(t *testing.T) {
+		signed := *report
+		signed.Sign("9a1e2f3d-5c6b-4a7e-8d9c-0b1a2c3d4e5f", "key", time.Now(), []byte("sig"))
+		resource := DiagnosticReportResource(&signed, examination, nil)
+		assertValid(t, resource)
+
+		if resource.Status != "final" || len(resource.ResultsInterpreter) != 1 {
+			t.Errorf("Status = %v, interpreters = %v, want final with interpreter", resource.Status, resource.ResultsInterpreter)
+		}
+	})
+}
+
+func TestBundle(t *testing.T) {
+	examination := newTestExamination()
+	images := newTestImages()
+	patient := entities.NewPatient(examination.PatientID, "Ivan", "Ivanov", "", time.Now(), "male", "", "")
+
+	bundle := NewBundle(examination.ID, time.Now())
+	bundle.Add("http://localhost/fhir/", "Patient", patient.ID, PatientResource(patient))
+	bundle.Add("http://localhost/fhir/", "ImagingStudy", examination.ID, ImagingStudyResource(examination, images))
+	for _, observation := range ObservationResources(examination, newTestAnalyses(examination.ID, images)) {
+		bundle.Add("http://localhost/fhir/", "Observation", observation.ID, observation)
+	}
+	assertValid(t, bundle)
+
+	if bundle.Entry[0].FullURL != "http://localhost/fhir/Patient/"+patient.ID {
+		t.Errorf("FullURL = %v", bundle.Entry[0].FullURL)
+	}
+}
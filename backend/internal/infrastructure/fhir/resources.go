@@ -0,0 +1,179 @@
+package fhir
+
+const ContentType = "application/fhir+json"
+
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+type Identifier struct {
+	Use    string `json:"use,omitempty"`
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+type HumanName struct {
+	Use    string   `json:"use,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Family string   `json:"family,omitempty"`
+	Given  []string `json:"given,omitempty"`
+}
+
+type ContactPoint struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Use    string `json:"use,omitempty"`
+}
+
+type Reference struct {
+	Reference string `json:"reference,omitempty"`
+	Display   string `json:"display,omitempty"`
+}
+
+type Quantity struct {
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit,omitempty"`
+	System string  `json:"system,omitempty"`
+	Code   string  `json:"code,omitempty"`
+}
+
+type Attachment struct {
+	ContentType string `json:"contentType,omitempty"`
+	Data        string `json:"data,omitempty"`
+	Title       string `json:"title,omitempty"`
+}
+
+type Meta struct {
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+type Patient struct {
+	ResourceType string         `json:"resourceType"`
+	ID           string         `json:"id,omitempty"`
+	Meta         *Meta          `json:"meta,omitempty"`
+	Identifier   []Identifier   `json:"identifier,omitempty"`
+	Name         []HumanName    `json:"name,omitempty"`
+	Telecom      []ContactPoint `json:"telecom,omitempty"`
+	Gender       string         `json:"gender,omitempty"`
+	BirthDate    string         `json:"birthDate,omitempty"`
+}
+
+type ImagingStudy struct {
+	ResourceType       string               `json:"resourceType"`
+	ID                 string               `json:"id,omitempty"`
+	Meta               *Meta                `json:"meta,omitempty"`
+	Identifier         []Identifier         `json:"identifier,omitempty"`
+	Status             string               `json:"status"`
+	Modality           []Coding             `json:"modality,omitempty"`
+	Subject            Reference            `json:"subject"`
+	Started            string               `json:"started,omitempty"`
+	Interpreter        []Reference          `json:"interpreter,omitempty"`
+	NumberOfSeries     int                  `json:"numberOfSeries"`
+	NumberOfInstances  int                  `json:"numberOfInstances"`
+	ProcedureReference *Reference           `json:"procedureReference,omitempty"`
+	Description        string               `json:"description,omitempty"`
+	Series             []ImagingStudySeries `json:"series,omitempty"`
+}
+
+type ImagingStudySeries struct {
+	UID               string                 `json:"uid"`
+	Number            int                    `json:"number"`
+	Modality          Coding                 `json:"modality"`
+	Description       string                 `json:"description,omitempty"`
+	NumberOfInstances int                    `json:"numberOfInstances"`
+	Instance          []ImagingStudyInstance `json:"instance,omitempty"`
+}
+
+type ImagingStudyInstance struct {
+	UID      string `json:"uid"`
+	SOPClass Coding `json:"sopClass"`
+	Number   int    `json:"number"`
+	Title    string `json:"title,omitempty"`
+}
+
+type Procedure struct {
+	ResourceType      string               `json:"resourceType"`
+	ID                string               `json:"id,omitempty"`
+	Meta              *Meta                `json:"meta,omitempty"`
+	Status            string               `json:"status"`
+	Code              CodeableConcept      `json:"code"`
+	Subject           Reference            `json:"subject"`
+	PerformedDateTime string               `json:"performedDateTime,omitempty"`
+	Performer         []ProcedurePerformer `json:"performer,omitempty"`
+}
+
+type ProcedurePerformer struct {
+	Actor Reference `json:"actor"`
+}
+
+type Observation struct {
+	ResourceType      string            `json:"resourceType"`
+	ID                string            `json:"id,omitempty"`
+	Meta              *Meta             `json:"meta,omitempty"`
+	Status            string            `json:"status"`
+	Category          []CodeableConcept `json:"category,omitempty"`
+	Code              CodeableConcept   `json:"code"`
+	Subject           Reference         `json:"subject"`
+	EffectiveDateTime string            `json:"effectiveDateTime,omitempty"`
+	Issued            string            `json:"issued,omitempty"`
+	ValueQuantity     *Quantity         `json:"valueQuantity,omitempty"`
+	DerivedFrom       []Reference       `json:"derivedFrom,omitempty"`
+}
+
+type DiagnosticReport struct {
+	ResourceType       string            `json:"resourceType"`
+	ID                 string            `json:"id,omitempty"`
+	Meta               *Meta             `json:"meta,omitempty"`
+	Status             string            `json:"status"`
+	Category           []CodeableConcept `json:"category,omitempty"`
+	Code               CodeableConcept   `json:"code"`
+	Subject            Reference         `json:"subject"`
+	EffectiveDateTime  string            `json:"effectiveDateTime,omitempty"`
+	Issued             string            `json:"issued,omitempty"`
+	Performer          []Reference       `json:"performer,omitempty"`
+	ResultsInterpreter []Reference       `json:"resultsInterpreter,omitempty"`
+	Result             []Reference       `json:"result,omitempty"`
+	ImagingStudy       []Reference       `json:"imagingStudy,omitempty"`
+	Conclusion         string            `json:"conclusion,omitempty"`
+	PresentedForm      []Attachment      `json:"presentedForm,omitempty"`
+}
+
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	ID           string        `json:"id,omitempty"`
+	Type         string        `json:"type"`
+	Timestamp    string        `json:"timestamp,omitempty"`
+	Entry        []BundleEntry `json:"entry,omitempty"`
+}
+
+type BundleEntry struct {
+	FullURL  string      `json:"fullUrl,omitempty"`
+	Resource interface{} `json:"resource"`
+}
+
+type OperationOutcome struct {
+	ResourceType string                  `json:"resourceType"`
+	Issue        []OperationOutcomeIssue `json:"issue"`
+}
+
+type OperationOutcomeIssue struct {
+	Severity    string `json:"severity"`
+	Code        string `json:"code"`
+	Diagnostics string `json:"diagnostics,omitempty"`
+}
+
+func NewOperationOutcome(severity, code, diagnostics string) *OperationOutcome {
+	return &OperationOutcome{
+		ResourceType: "OperationOutcome",
+		Issue: []OperationOutcomeIssue{
+			{Severity: severity, Code: code, Diagnostics: diagnostics},
+		},
+	}
+}
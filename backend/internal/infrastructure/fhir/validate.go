@@ -0,0 +1,196 @@
+package fhir
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	idPattern        = regexp.MustCompile(`^[A-Za-z0-9\-.]{1,64}$`)
+	datePattern      = regexp.MustCompile(`^\d{4}(-(0[1-9]|1[0-2])(-(0[1-9]|[12]\d|3[01]))?)?$`)
+	dateTimePattern  = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T([01]\d|2[0-3]):[0-5]\d:([0-5]\d|60)(\.\d+)?(Z|[+-]((0\d|1[0-3]):[0-5]\d|14:00))$`)
+	referencePattern = regexp.MustCompile(`^([A-Z][A-Za-z]+/[A-Za-z0-9\-.]{1,64}|urn:(uuid|oid):.+|https?://.+)$`)
+	oidPattern       = regexp.MustCompile(`^[0-2](\.(0|[1-9]\d*))+$`)
+)
+
+var requiredCodes = map[string]map[string][]string{
+	"Patient": {
+		"gender": {"male", "female", "other", "unknown"},
+	},
+	"ImagingStudy": {
+		"status": {"registered", "available", "cancelled", "entered-in-error", "unknown"},
+	},
+	"Procedure": {
+		"status": {"preparation", "in-progress", "not-done", "on-hold", "stopped", "completed", "entered-in-error", "unknown"},
+	},
+	"Observation": {
+		"status": {"registered", "preliminary", "final", "amended", "corrected", "cancelled", "entered-in-error", "unknown"},
+	},
+	"DiagnosticReport": {
+		"status": {"registered", "partial", "preliminary", "final", "amended", "corrected", "appended", "cancelled", "entered-in-error", "unknown"},
+	},
+	"Bundle": {
+		"type": {"document", "message", "transaction", "transaction-response", "batch", "batch-response", "history", "searchset", "collection"},
+	},
+	"OperationOutcome": {},
+}
+
+var requiredElements = map[string][]string{
+	"ImagingStudy":     {"status", "subject"},
+	"Procedure":        {"status", "subject"},
+	"Observation":      {"status", "code"},
+	"DiagnosticReport": {"status", "code"},
+	"Bundle":           {"type"},
+	"OperationOutcome": {"issue"},
+}
+
+type ValidationError struct {
+	Issues []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Issues, "; ")
+}
+
+// Validate checks a resource against the structural rules of FHIR R4 JSON
+// for the resource types this package produces: known resourceType, id and
+// primitive formats, required elements, bound status codes and the ban on
+// empty values. It is not a full profile validator.
+func Validate(resource interface{}) error {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	return ValidateJSON(data)
+}
+
+func ValidateJSON(data []byte) error {
+	var value map[string]interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &ValidationError{Issues: []string{"not a JSON object: " + err.Error()}}
+	}
+
+	v := &validator{}
+	v.resource("", value)
+	if len(v.issues) > 0 {
+		return &ValidationError{Issues: v.issues}
+	}
+	return nil
+}
+
+type validator struct {
+	issues []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.issues = append(v.issues, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) resource(path string, value map[string]interface{}) {
+	resourceType, _ := value["resourceType"].(string)
+	codes, known := requiredCodes[resourceType]
+	if !known {
+		v.addf("%sresourceType: unsupported value %q", path, resourceType)
+		return
+	}
+	prefix := path + resourceType
+
+	if id, ok := value["id"]; ok {
+		if s, _ := id.(string); !idPattern.MatchString(s) {
+			v.addf("%s.id: invalid id %v", prefix, id)
+		}
+	}
+
+	for _, element := range requiredElements[resourceType] {
+		if _, ok := value[element]; !ok {
+			v.addf("%s.%s: required element missing", prefix, element)
+		}
+	}
+
+	for element, allowed := range codes {
+		raw, ok := value[element]
+		if !ok {
+			continue
+		}
+		code, _ := raw.(string)
+		if !contains(allowed, code) {
+			v.addf("%s.%s: invalid code %v", prefix, element, raw)
+		}
+	}
+
+	for key, child := range value {
+		if key == "resourceType" {
+			continue
+		}
+		v.element(prefix+"."+key, key, child)
+	}
+}
+
+func (v *validator) element(path, key string, value interface{}) {
+	switch typed := value.(type) {
+	case nil:
+		v.addf("%s: null values are not allowed", path)
+	case string:
+		if typed == "" || strings.TrimSpace(typed) != typed {
+			v.addf("%s: strings must be non-empty and trimmed", path)
+			return
+		}
+		v.primitive(path, key, typed)
+	case []interface{}:
+		if len(typed) == 0 {
+			v.addf("%s: empty arrays are not allowed", path)
+		}
+		for i, item := range typed {
+			v.element(fmt.Sprintf("%s[%d]", path, i), key, item)
+		}
+	case map[string]interface{}:
+		if len(typed) == 0 {
+			v.addf("%s: empty objects are not allowed", path)
+			return
+		}
+		if key == "resource" {
+			v.resource(path+".", typed)
+			return
+		}
+		if key == "entry" {
+			if _, ok := typed["resource"]; !ok {
+				v.addf("%s.resource: required element missing", path)
+			}
+		}
+		for childKey, child := range typed {
+			v.element(path+"."+childKey, childKey, child)
+		}
+	}
+}
+
+func (v *validator) primitive(path, key, value string) {
+	switch {
+	case key == "birthDate":
+		if !datePattern.MatchString(value) {
+			v.addf("%s: invalid date %q", path, value)
+		}
+	case key == "started" || key == "issued" || key == "timestamp" || key == "lastUpdated" || strings.HasSuffix(key, "DateTime"):
+		if !dateTimePattern.MatchString(value) {
+			v.addf("%s: invalid dateTime %q", path, value)
+		}
+	case key == "reference":
+		if !referencePattern.MatchString(value) {
+			v.addf("%s: invalid reference %q", path, value)
+		}
+	case key == "uid":
+		if !oidPattern.MatchString(value) || len(value) > 64 {
+			v.addf("%s: invalid DICOM UID %q", path, value)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package fhir
+
+import "testing"
+
+func TestValidateJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{
+			name:    "minimal patient",
+			json:    `{"resourceType":"Patient","id":"p-1","gender":"female","birthDate":"1985-02"}`,
+			wantErr: false,
+		},
+		{
+			name:    "unknown resource type",
+			json:    `{"resourceType":"Banana","id":"b"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid id",
+			json:    `{"resourceType":"Patient","id":"has spaces"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid gender code",
+			json:    `{"resourceType":"Patient","gender":"M"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid birth date",
+			json:    `{"resourceType":"Patient","birthDate":"15.05.1990"}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty string",
+			json:    `{"resourceType":"Patient","name":[{"family":""}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty array",
+			json:    `{"resourceType":"Patient","telecom":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "observation without code",
+			json:    `{"resourceType":"Observation","status":"final"}`,
+			wantErr: true,
+		},
+		{
+			name:    "dateTime without timezone",
+			json:    `{"resourceType":"Observation","status":"final","code":{"text":"x"},"effectiveDateTime":"2024-01-01T10:00:00"}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed reference",
+			json:    `{"resourceType":"Observation","status":"final","code":{"text":"x"},"subject":{"reference":"patient 1"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "bundle entry without resource",
+			json:    `{"resourceType":"Bundle","type":"collection","entry":[{"fullUrl":"http://x/Patient/1"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "bundle with invalid nested resource",
+			json:    `{"resourceType":"Bundle","type":"collection","entry":[{"resource":{"resourceType":"Observation","status":"done","code":{"text":"x"}}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "not an object",
+			json:    `[1,2]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSON([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package hl7
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	AckAccept       = "AA"
+	AckError        = "AE"
+	AckReject       = "AR"
+	AckCommitAccept = "CA"
+	AckCommitError  = "CE"
+	AckCommitReject = "CR"
+)
+
+type Ack struct {
+	Code      string
+	ControlID string
+	Text      string
+}
+
+func (a *Ack) Accepted() bool {
+	return a.Code == AckAccept || a.Code == AckCommitAccept
+}
+
+// Retryable reports whether the receiver signalled a transient error rather
+// than rejecting the message outright.
+func (a *Ack) Retryable() bool {
+	return a.Code == AckError || a.Code == AckCommitError
+}
+
+func ParseAck(data []byte) (*Ack, error) {
+	message, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	msa := message.Segment("MSA")
+	if msa == nil {
+		return nil, fmt.Errorf("%w: missing MSA segment", ErrMalformedMessage)
+	}
+
+	ack := &Ack{
+		Code:      msa.Field(1),
+		ControlID: msa.Field(2),
+		Text:      msa.Field(3),
+	}
+	if err := message.Segment("ERR"); ack.Text == "" && err != nil {
+		ack.Text = err.Field(8)
+	}
+
+	switch ack.Code {
+	case AckAccept, AckError, AckReject, AckCommitAccept, AckCommitError, AckCommitReject:
+		return ack, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown acknowledgment code %q", ErrMalformedMessage, ack.Code)
+	}
+}
+
+// NewAck builds the acknowledgment a receiver returns for message, swapping
+// the sending and receiving parties of the original header.
+func NewAck(message *Message, code, text, controlID string, createdAt time.Time) *Message {
+	msh := message.Segment("MSH")
+
+	ack := &Message{}
+	ack.Add("MSH",
+		EncodingChars,
+		msh.Field(5),
+		msh.Field(6),
+		msh.Field(3),
+		msh.Field(4),
+		Timestamp(createdAt),
+		"",
+		"ACK^"+triggerEvent(msh.Field(9))+"^ACK",
+		Escape(controlID),
+		msh.Field(11),
+		msh.Field(12),
+	)
+	ack.Add("MSA", code, msh.Field(10), Escape(text))
+	return ack
+}
+
+func triggerEvent(messageType string) string {
+	parts := strings.Split(messageType, componentSep)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
@@ -0,0 +1,126 @@
+package hl7
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	SegmentSeparator = "\r"
+	FieldSeparator   = "|"
+	EncodingChars    = `^~\&`
+	componentSep     = "^"
+	timestampFormat  = "20060102150405-0700"
+	dateFormat       = "20060102"
+	Version          = "2.5.1"
+	CharsetUTF8      = "UNICODE UTF-8"
+	ProcessingID     = "P"
+)
+
+var ErrMalformedMessage = errors.New("malformed HL7 message")
+
+var escaper = strings.NewReplacer(
+	`\`, `\E\`,
+	"|", `\F\`,
+	"^", `\S\`,
+	"&", `\T\`,
+	"~", `\R\`,
+	"\r\n", `\.br\`,
+	"\n", `\.br\`,
+	"\r", `\.br\`,
+)
+
+// Segment holds the segment ID followed by its already encoded fields.
+type Segment []string
+
+type Message struct {
+	Segments []Segment
+}
+
+func (m *Message) Add(id string, fields ...string) {
+	m.Segments = append(m.Segments, append(Segment{id}, fields...))
+}
+
+// Segment returns the first segment with the given ID.
+func (m *Message) Segment(id string) Segment {
+	for _, segment := range m.Segments {
+		if len(segment) > 0 && segment[0] == id {
+			return segment
+		}
+	}
+	return nil
+}
+
+func (m *Message) String() string {
+	lines := make([]string, 0, len(m.Segments))
+	for _, segment := range m.Segments {
+		lines = append(lines, strings.TrimRight(strings.Join(segment, FieldSeparator), FieldSeparator))
+	}
+	return strings.Join(lines, SegmentSeparator) + SegmentSeparator
+}
+
+func (m *Message) Bytes() []byte {
+	return []byte(m.String())
+}
+
+// Field returns the n-th field of the segment using HL7 numbering, where for
+// MSH the field separator itself is MSH-1.
+func (s Segment) Field(n int) string {
+	if len(s) > 0 && s[0] == "MSH" {
+		if n == 1 {
+			return FieldSeparator
+		}
+		n--
+	}
+	if n <= 0 || n >= len(s) {
+		return ""
+	}
+	return s[n]
+}
+
+func Parse(data []byte) (*Message, error) {
+	text := strings.ReplaceAll(string(data), "\n", SegmentSeparator)
+	message := &Message{}
+	for _, line := range strings.Split(text, SegmentSeparator) {
+		if line == "" {
+			continue
+		}
+		message.Segments = append(message.Segments, Segment(strings.Split(line, FieldSeparator)))
+	}
+	if len(message.Segments) == 0 || message.Segments[0][0] != "MSH" {
+		return nil, ErrMalformedMessage
+	}
+	return message, nil
+}
+
+func Escape(value string) string {
+	return escaper.Replace(value)
+}
+
+// Component escapes each part and joins them with the component separator,
+// dropping trailing empty components.
+func Component(parts ...string) string {
+	for len(parts) > 0 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = Escape(part)
+	}
+	return strings.Join(escaped, componentSep)
+}
+
+func Timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timestampFormat)
+}
+
+func Date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateFormat)
+}
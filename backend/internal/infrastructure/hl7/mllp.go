@@ -0,0 +1,109 @@
+package hl7
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	startBlock     = 0x0b
+	endBlock       = 0x1c
+	carriageReturn = 0x0d
+
+	// MaxFrameSize bounds the payload ReadFrame accepts, so a peer that
+	// never sends the end block cannot exhaust memory.
+	MaxFrameSize = 1 << 20
+)
+
+var ErrInvalidFrame = errors.New("invalid MLLP frame")
+
+// Client delivers messages over MLLP, opening one connection per message.
+type Client struct {
+	address string
+	timeout time.Duration
+}
+
+func NewClient(address string, timeout time.Duration) *Client {
+	return &Client{address: address, timeout: timeout}
+}
+
+func (c *Client) Address() string {
+	return c.address
+}
+
+// Send writes the framed message and waits for the acknowledgment.
+func (c *Client) Send(ctx context.Context, message []byte) (*Ack, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := WriteFrame(conn, message); err != nil {
+		return nil, err
+	}
+
+	response, err := ReadFrame(bufio.NewReader(conn))
+	if err != nil {
+		return nil, err
+	}
+	return ParseAck(response)
+}
+
+func WriteFrame(w io.Writer, message []byte) error {
+	frame := make([]byte, 0, len(message)+3)
+	frame = append(frame, startBlock)
+	frame = append(frame, message...)
+	frame = append(frame, endBlock, carriageReturn)
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadFrame reads one MLLP frame of at most MaxFrameSize payload bytes.
+func ReadFrame(r *bufio.Reader) ([]byte, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if b != startBlock {
+		return nil, fmt.Errorf("%w: unexpected start byte 0x%02x", ErrInvalidFrame, b)
+	}
+
+	var payload []byte
+	for {
+		chunk, err := r.ReadSlice(endBlock)
+		if len(payload)+len(chunk) > MaxFrameSize+1 {
+			return nil, fmt.Errorf("%w: frame exceeds %d bytes", ErrInvalidFrame, MaxFrameSize)
+		}
+		payload = append(payload, chunk...)
+		if err == nil {
+			break
+		}
+		if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+
+	b, err = r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if b != carriageReturn {
+		return nil, fmt.Errorf("%w: missing trailing carriage return", ErrInvalidFrame)
+	}
+	return payload[:len(payload)-1], nil
+}
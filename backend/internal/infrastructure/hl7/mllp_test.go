@@ -0,0 +1,128 @@
+package hl7
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listen starts a local MLLP listener that answers each message with the
+// acknowledgment code returned by respond.
+func listen(t *testing.T, respond func(*Message) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				payload, err := ReadFrame(bufio.NewReader(conn))
+				if err != nil {
+					return
+				}
+				message, err := Parse(payload)
+				if err != nil {
+					return
+				}
+				WriteFrame(conn, NewAck(message, respond(message), "", "ACK", time.Now()).Bytes())
+			}(conn)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestClientSend(t *testing.T) {
+	patient, examination, report, analyses := newTestReport(true)
+	message := NewORU(testHeader, "CTRL9", time.Now(), patient, examination, report, analyses)
+
+	t.Run("accepted", func(t *testing.T) {
+		received := make(chan *Message, 1)
+		addr := listen(t, func(m *Message) string {
+			received <- m
+			return AckAccept
+		})
+
+		ack, err := NewClient(addr, time.Second).Send(context.Background(), message.Bytes())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if !ack.Accepted() || ack.ControlID != "CTRL9" {
+			t.Errorf("Send() = %+v", ack)
+		}
+		if got := <-received; len(got.Segments) != len(message.Segments) {
+			t.Error("listener should receive the full message")
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		addr := listen(t, func(*Message) string { return AckReject })
+
+		ack, err := NewClient(addr, time.Second).Send(context.Background(), message.Bytes())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if ack.Accepted() || ack.Code != AckReject {
+			t.Errorf("Send() = %+v, want AR", ack)
+		}
+	})
+
+	t.Run("no listener", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		addr := ln.Addr().String()
+		ln.Close()
+
+		if _, err := NewClient(addr, time.Second).Send(context.Background(), message.Bytes()); err == nil {
+			t.Error("Send() should fail when nothing listens")
+		}
+	})
+}
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr error
+	}{
+		{name: "valid", data: []byte("\x0bMSH|x\r\x1c\r"), want: "MSH|x\r"},
+		{name: "missing start block", data: []byte("MSH|x\r\x1c\r"), wantErr: ErrInvalidFrame},
+		{name: "missing trailing carriage return", data: []byte("\x0bMSH\x1c\n"), wantErr: ErrInvalidFrame},
+		{name: "largest frame", data: frame(bytes.Repeat([]byte("x"), MaxFrameSize)), want: strings.Repeat("x", MaxFrameSize)},
+		{name: "frame too large", data: frame(bytes.Repeat([]byte("x"), MaxFrameSize+1)), wantErr: ErrInvalidFrame},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadFrame(bufio.NewReader(bytes.NewReader(tt.data)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadFrame() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && string(got) != tt.want {
+				t.Errorf("ReadFrame() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func frame(payload []byte) []byte {
+	var buf bytes.Buffer
+	WriteFrame(&buf, payload)
+	return buf.Bytes()
+}
@@ -0,0 +1,163 @@
+package hl7
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/fhir"
+)
+
+const (
+	MessageTypeORU = "ORU^R01^ORU_R01"
+
+	codingSystemLocal = "L"
+	codingSystemUCUM  = "UCUM"
+
+	resultFinal       = "F"
+	resultPreliminary = "P"
+)
+
+type Header struct {
+	SendingApplication   string
+	SendingFacility      string
+	ReceivingApplication string
+	ReceivingFacility    string
+}
+
+// NewORU builds an ORU^R01 result message for the report with one numeric OBX
+// per capillary metric of every completed analysis, followed by the diagnosis
+// and recommendations as text observations.
+func NewORU(
+	header Header,
+	controlID string,
+	createdAt time.Time,
+	patient *entities.Patient,
+	examination *entities.Examination,
+	report *entities.Report,
+	analyses []*entities.Analysis,
+) *Message {
+	status := resultPreliminary
+	reportedAt := report.UpdatedAt
+	interpreter := ""
+	if report.IsSigned() {
+		status = resultFinal
+		reportedAt = *report.SignedAt
+		interpreter = report.SignedBy
+	}
+
+	message := &Message{}
+	message.Add("MSH",
+		EncodingChars,
+		Escape(header.SendingApplication),
+		Escape(header.SendingFacility),
+		Escape(header.ReceivingApplication),
+		Escape(header.ReceivingFacility),
+		Timestamp(createdAt),
+		"",
+		MessageTypeORU,
+		Escape(controlID),
+		ProcessingID,
+		Version,
+		"", "", "", "", "", "",
+		CharsetUTF8,
+	)
+
+	identifiers := Component(patient.ID, "", "", header.SendingApplication, "MR")
+	if patient.ExternalID != "" {
+		identifiers += "~" + Component(patient.ExternalID, "", "", patient.ExternalSystem, "PI")
+	}
+	message.Add("PID",
+		"1",
+		"",
+		identifiers,
+		"",
+		Component(patient.LastName, patient.FirstName, patient.MiddleName),
+		"",
+		Date(patient.DateOfBirth),
+		administrativeSex(patient.Gender),
+		"", "", "", "",
+		Escape(patient.Phone),
+	)
+
+	message.Add("OBR",
+		"1",
+		Escape(examination.ID),
+		Escape(report.ID),
+		Component("CAPREPORT", "Nailfold capillaroscopy report", codingSystemLocal),
+		"", "",
+		Timestamp(examination.CreatedAt),
+		"", "", "", "", "", "", "", "", "", "", "", "", "", "",
+		Timestamp(reportedAt),
+		"", "",
+		status,
+		"", "", "", "", "", "",
+		Escape(interpreter),
+	)
+
+	setID := 0
+	addOBX := func(valueType, code, subID, value, units, observedAt string) {
+		setID++
+		message.Add("OBX",
+			strconv.Itoa(setID),
+			valueType,
+			code,
+			subID,
+			value,
+			units,
+			"", "", "", "",
+			status,
+			"", "",
+			observedAt,
+		)
+	}
+
+	subID := 0
+	for _, analysis := range analyses {
+		if analysis.Status != entities.AnalysisStatusCompleted {
+			continue
+		}
+		subID++
+
+		observedAt := analysis.UpdatedAt
+		if analysis.CompletedAt != nil {
+			observedAt = *analysis.CompletedAt
+		}
+
+		for _, metric := range fhir.CapillaryMetrics {
+			value, ok := analysis.Metrics[metric.Key].(float64)
+			if !ok {
+				continue
+			}
+			addOBX("NM",
+				Component(metric.Code, metric.Display, codingSystemLocal),
+				strconv.Itoa(subID),
+				strconv.FormatFloat(value, 'f', -1, 64),
+				Component(metric.Unit, "", codingSystemUCUM),
+				Timestamp(observedAt),
+			)
+		}
+	}
+
+	if report.Diagnosis != "" {
+		addOBX("TX", Component("CAPDX", "Capillaroscopy diagnosis", codingSystemLocal), "", Escape(report.Diagnosis), "", Timestamp(reportedAt))
+	}
+	if report.Recommendations != "" {
+		addOBX("TX", Component("CAPREC", "Recommendations", codingSystemLocal), "", Escape(report.Recommendations), "", Timestamp(reportedAt))
+	}
+
+	return message
+}
+
+func administrativeSex(gender string) string {
+	switch gender {
+	case "male":
+		return "M"
+	case "female":
+		return "F"
+	case "other":
+		return "O"
+	default:
+		return "U"
+	}
+}
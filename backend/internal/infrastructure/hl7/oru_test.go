@@ -0,0 +1,160 @@
+package hl7
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+var testHeader = Header{
+	SendingApplication:   "CAPILLARY",
+	SendingFacility:      "CLINIC",
+	ReceivingApplication: "LIS",
+	ReceivingFacility:    "LAB",
+}
+
+func newTestReport(signed bool) (*entities.Patient, *entities.Examination, *entities.Report, []*entities.Analysis) {
+	dob := time.Date(1990, 5, 15, 0, 0, 0, 0, time.UTC)
+	patient := entities.NewPatient("patient-1", "Иван", "Иванов", "Иванович", dob, "male", "+7|900", "")
+	patient.SetExternalIdentifier("urn:oid:1.2.3", "MRN-42")
+
+	examination := entities.NewExamination("exam-1", patient.ID, "doctor-1", "")
+
+	report := entities.NewReport("report-1", examination.ID, "Отчёт", "", "", "Норма^\nконтроль", "Наблюдение", "system")
+	if signed {
+		report.Sign("doctor-1", "key-1", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), []byte("sig"))
+	}
+
+	completed := entities.NewAnalysis("analysis-1", examination.ID, "image-1")
+	completed.Complete(map[string]interface{}{"density": 8.5, "diameter": 12.25, "abnormalities": []string{}})
+	pending := entities.NewAnalysis("analysis-2", examination.ID, "image-2")
+
+	return patient, examination, report, []*entities.Analysis{completed, pending}
+}
+
+func segmentsWithID(message *Message, id string) []Segment {
+	var segments []Segment
+	for _, segment := range message.Segments {
+		if segment[0] == id {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func TestNewORU(t *testing.T) {
+	tests := []struct {
+		name   string
+		signed bool
+		status string
+	}{
+		{name: "draft report is preliminary", signed: false, status: "P"},
+		{name: "signed report is final", signed: true, status: "F"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patient, examination, report, analyses := newTestReport(tt.signed)
+			created := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+			message := NewORU(testHeader, "CTRL1", created, patient, examination, report, analyses)
+
+			parsed, err := Parse(message.Bytes())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			msh := parsed.Segment("MSH")
+			if got := msh.Field(9); got != MessageTypeORU {
+				t.Errorf("MSH-9 = %v, want %v", got, MessageTypeORU)
+			}
+			if got := msh.Field(10); got != "CTRL1" {
+				t.Errorf("MSH-10 = %v, want CTRL1", got)
+			}
+			if got := msh.Field(7); got != "20240301123000+0000" {
+				t.Errorf("MSH-7 = %v", got)
+			}
+
+			pid := parsed.Segment("PID")
+			if got := pid.Field(3); got != `patient-1^^^CAPILLARY^MR~MRN-42^^^urn:oid:1.2.3^PI` {
+				t.Errorf("PID-3 = %v", got)
+			}
+			if got := pid.Field(5); got != "Иванов^Иван^Иванович" {
+				t.Errorf("PID-5 = %v", got)
+			}
+			if got := pid.Field(13); got != `+7\F\900` {
+				t.Errorf("PID-13 = %v, want escaped field separator", got)
+			}
+
+			if got := parsed.Segment("OBR").Field(25); got != tt.status {
+				t.Errorf("OBR-25 = %v, want %v", got, tt.status)
+			}
+
+			obx := segmentsWithID(parsed, "OBX")
+			if len(obx) != 4 {
+				t.Fatalf("OBX count = %d, want 4 (2 metrics + diagnosis + recommendations)", len(obx))
+			}
+			if got := obx[0].Field(3); got != "CAP001^Nailfold capillary density^L" {
+				t.Errorf("OBX-3 = %v", got)
+			}
+			if got := obx[1].Field(5); got != "12.25" {
+				t.Errorf("OBX-5 = %v, want 12.25", got)
+			}
+			if got := obx[2].Field(5); got != `Норма\S\\.br\контроль` {
+				t.Errorf("diagnosis OBX-5 = %v", got)
+			}
+			for i, segment := range obx {
+				if segment.Field(1) != string(rune('1'+i)) {
+					t.Errorf("OBX-1 = %v, want %d", segment.Field(1), i+1)
+				}
+				if segment.Field(11) != tt.status {
+					t.Errorf("OBX-11 = %v, want %v", segment.Field(11), tt.status)
+				}
+			}
+
+			if strings.Contains(message.String(), "\n") {
+				t.Error("message must use carriage return segment separators only")
+			}
+		})
+	}
+}
+
+func TestParseAck(t *testing.T) {
+	patient, examination, report, analyses := newTestReport(true)
+	message := NewORU(testHeader, "CTRL1", time.Now(), patient, examination, report, analyses)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		code      string
+		accepted  bool
+		retryable bool
+		wantErr   bool
+	}{
+		{name: "accept", data: NewAck(message, AckAccept, "", "ACK1", time.Now()).Bytes(), code: AckAccept, accepted: true},
+		{name: "error", data: NewAck(message, AckError, "db down", "ACK2", time.Now()).Bytes(), code: AckError, retryable: true},
+		{name: "reject", data: NewAck(message, AckReject, "bad", "ACK3", time.Now()).Bytes(), code: AckReject},
+		{name: "missing MSA", data: []byte("MSH|^~\\&|LIS\r"), wantErr: true},
+		{name: "not HL7", data: []byte("hello"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ack, err := ParseAck(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if ack.Code != tt.code || ack.Accepted() != tt.accepted || ack.Retryable() != tt.retryable {
+				t.Errorf("ParseAck() = %+v", ack)
+			}
+			if ack.ControlID != "CTRL1" {
+				t.Errorf("MSA-2 = %v, want CTRL1", ack.ControlID)
+			}
+		})
+	}
+}
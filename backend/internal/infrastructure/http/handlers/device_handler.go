@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/application/usecases"
+)
+
+type DeviceHandler struct {
+	deviceUseCase *usecases.DeviceUseCase
+}
+
+func NewDeviceHandler(deviceUseCase *usecases.DeviceUseCase) *DeviceHandler {
+	return &DeviceHandler{deviceUseCase: deviceUseCase}
+}
+
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	devices, err := h.deviceUseCase.ListDevices(c.Request.Context())
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    devices,
+	})
+}
+
+// ListOnlineDevices lists the devices with an open connection.
+func (h *DeviceHandler) ListOnlineDevices(c *gin.Context) {
+	devices, err := h.deviceUseCase.ListOnlineDevices(c.Request.Context())
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    devices,
+	})
+}
+
+func (h *DeviceHandler) GetDevice(c *gin.Context) {
+	device, err := h.deviceUseCase.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    device,
+	})
+}
+
+func (h *DeviceHandler) CreateDevice(c *gin.Context) {
+	var req dto.CreateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	device, err := h.deviceUseCase.CreateDevice(c.Request.Context(), req)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Success: true,
+		Data:    device,
+	})
+}
+
+func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
+	var req dto.UpdateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	device, err := h.deviceUseCase.UpdateDevice(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    device,
+	})
+}
+
+func (h *DeviceHandler) DeleteDevice(c *gin.Context) {
+	if err := h.deviceUseCase.DeleteDevice(c.Request.Context(), c.Param("id")); err != nil {
+		respondDeviceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Device deleted successfully",
+	})
+}
+
+func respondDeviceError(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, usecases.ErrDeviceNotFound), errors.Is(err, usecases.ErrOrganizationNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, usecases.ErrDeviceExists):
+		status, code = http.StatusConflict, "conflict"
+	case errors.Is(err, usecases.ErrInvalidDeviceSetting):
+		status, code = http.StatusBadRequest, "validation_error"
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
+	})
+}
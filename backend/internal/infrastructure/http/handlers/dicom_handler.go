@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/application/usecases"
+)
+
+type DICOMHandler struct {
+	dicomUseCase *usecases.DICOMUseCase
+}
+
+func NewDICOMHandler(dicomUseCase *usecases.DICOMUseCase) *DICOMHandler {
+	return &DICOMHandler{dicomUseCase: dicomUseCase}
+}
+
+func (h *DICOMHandler) ExportExamination(c *gin.Context) {
+	id := c.Param("id")
+	archive, err := h.dicomUseCase.ExportExamination(c.Request.Context(), id)
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_error"
+		if errors.Is(err, usecases.ErrExaminationNotFound) || errors.Is(err, usecases.ErrPatientNotFound) {
+			status, code = http.StatusNotFound, "not_found"
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="examination_%s_dicom.zip"`, id))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+)
+
+// setETag tags the response with the version of the resource it carries.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ifMatchVersion reads the If-Match precondition every update must send.
+// It responds 428 when the header is missing and 412 when it cannot match a
+// version; "*" yields 0, which matches whatever version is current.
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, dto.ErrorResponse{
+			Error:   "precondition_required",
+			Message: "If-Match header with the ETag from GET is required",
+			Code:    http.StatusPreconditionRequired,
+		})
+		return 0, false
+	}
+
+	version, ok := parseIfMatch(header)
+	if !ok {
+		respondPreconditionFailed(c)
+		return 0, false
+	}
+	return version, true
+}
+
+// parseIfMatch accepts "*" or a single strong entity tag written by setETag.
+// Weak tags never match under If-Match.
+func parseIfMatch(header string) (int, bool) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return 0, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(header[1 : len(header)-1])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+func respondPreconditionFailed(c *gin.Context) {
+	c.JSON(http.StatusPreconditionFailed, dto.ErrorResponse{
+		Error:   "precondition_failed",
+		Message: "The record has been modified since it was read; reload it and try again",
+		Code:    http.StatusPreconditionFailed,
+	})
+}
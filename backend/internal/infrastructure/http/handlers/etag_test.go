@@ -0,0 +1,27 @@
+package handlers
+
+import "testing"
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+		wantOK bool
+	}{
+		{`"3"`, 3, true},
+		{` "12" `, 12, true},
+		{`*`, 0, true},
+		{`W/"3"`, 0, false},
+		{`3`, 0, false},
+		{`"0"`, 0, false},
+		{`"abc"`, 0, false},
+		{`"3", "4"`, 0, false},
+		{`"`, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseIfMatch(tt.header)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseIfMatch(%q) = %d, %v, want %d, %v", tt.header, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
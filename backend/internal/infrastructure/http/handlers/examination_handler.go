@@ -1,20 +1,28 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/project-capillary/backend/internal/application/dto"
 	"github.com/project-capillary/backend/internal/application/usecases"
 )
 
+// maxUploadFiles bounds the files in one image upload; each is read into
+// memory to be validated.
+const maxUploadFiles = 20
+
 type ExaminationHandler struct {
 	examinationUseCase *usecases.ExaminationUseCase
+	maxUploadBytes     int64
 }
 
-func NewExaminationHandler(examinationUseCase *usecases.ExaminationUseCase) *ExaminationHandler {
-	return &ExaminationHandler{examinationUseCase: examinationUseCase}
+func NewExaminationHandler(examinationUseCase *usecases.ExaminationUseCase, maxUploadBytes int64) *ExaminationHandler {
+	return &ExaminationHandler{examinationUseCase: examinationUseCase, maxUploadBytes: maxUploadBytes}
 }
 
 func (h *ExaminationHandler) CreateExamination(c *gin.Context) {
@@ -29,6 +37,22 @@ func (h *ExaminationHandler) CreateExamination(c *gin.Context) {
 	}
 
 	examination, err := h.examinationUseCase.CreateExamination(c.Request.Context(), req)
+	if errors.Is(err, usecases.ErrUnknownExamType) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if errors.Is(err, usecases.ErrPatientNotFound) || errors.Is(err, usecases.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -45,10 +69,17 @@ func (h *ExaminationHandler) CreateExamination(c *gin.Context) {
 }
 
 func (h *ExaminationHandler) ListExaminations(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	var query dto.PageRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
 
-	examinations, err := h.examinationUseCase.ListExaminations(c.Request.Context(), limit, offset)
+	result, err := h.examinationUseCase.ListExaminations(c.Request.Context(), query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -58,10 +89,7 @@ func (h *ExaminationHandler) ListExaminations(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Success: true,
-		Data:    examinations,
-	})
+	c.JSON(http.StatusOK, result)
 }
 
 func (h *ExaminationHandler) GetExamination(c *gin.Context) {
@@ -85,6 +113,14 @@ func (h *ExaminationHandler) GetExamination(c *gin.Context) {
 func (h *ExaminationHandler) StartAnalysis(c *gin.Context) {
 	id := c.Param("id")
 	err := h.examinationUseCase.StartAnalysis(c.Request.Context(), id)
+	if errors.Is(err, usecases.ErrCaptureIncomplete) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error:   "capture_incomplete",
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -100,6 +136,33 @@ func (h *ExaminationHandler) StartAnalysis(c *gin.Context) {
 	})
 }
 
+// GetCaptureProgress shows which finger positions required by the
+// examination's capture protocol have enough images.
+func (h *ExaminationHandler) GetCaptureProgress(c *gin.Context) {
+	progress, err := h.examinationUseCase.CaptureProgress(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, usecases.ErrExaminationNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "not_found",
+			Message: "Examination not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "internal_error",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    progress,
+	})
+}
+
 func (h *ExaminationHandler) GetPatientExaminations(c *gin.Context) {
 	patientID := c.Param("patientId")
 	examinations, err := h.examinationUseCase.GetExaminationsByPatient(c.Request.Context(), patientID)
@@ -130,7 +193,39 @@ func (h *ExaminationHandler) AttachPhotos(c *gin.Context) {
 		return
 	}
 
-	err := h.examinationUseCase.AttachPhotos(c.Request.Context(), id, req.Photos)
+	err := h.examinationUseCase.AttachPhotos(c.Request.Context(), id, req.Photos, req.Position)
+	if errors.Is(err, usecases.ErrPhotoNotFound) || errors.Is(err, usecases.ErrInvalidPosition) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if errors.Is(err, usecases.ErrInvalidImage) {
+		c.JSON(http.StatusUnsupportedMediaType, dto.ErrorResponse{
+			Error:   "unsupported_media_type",
+			Message: err.Error(),
+			Code:    http.StatusUnsupportedMediaType,
+		})
+		return
+	}
+	if errors.Is(err, usecases.ErrExaminationNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "not_found",
+			Message: "Examination not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if errors.Is(err, usecases.ErrExaminationClosed) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error:   "examination_closed",
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -145,3 +240,121 @@ func (h *ExaminationHandler) AttachPhotos(c *gin.Context) {
 		Message: "Photos attached successfully",
 	})
 }
+
+// UploadImages accepts JPEG, PNG and TIFF files in the multipart field
+// "files" and attaches them to the examination. The optional field
+// "position" names the finger all files were taken of.
+func (h *ExaminationHandler) UploadImages(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadFiles*h.maxUploadBytes+1<<20)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondUploadTooLarge(c, fmt.Sprintf("upload exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	defer form.RemoveAll()
+
+	files := form.File["files"]
+	if len(files) == 0 || len(files) > maxUploadFiles {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: fmt.Sprintf("upload between 1 and %d images in the files field", maxUploadFiles),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var position string
+	if values := form.Value["position"]; len(values) > 0 {
+		position = values[0]
+	}
+	uploads := make([]dto.ImageUpload, 0, len(files))
+	for _, file := range files {
+		if file.Size > h.maxUploadBytes {
+			respondUploadTooLarge(c, fmt.Sprintf("%s exceeds %d bytes", file.Filename, h.maxUploadBytes))
+			return
+		}
+		data, err := readUpload(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "validation_error",
+				Message: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		uploads = append(uploads, dto.ImageUpload{Filename: file.Filename, Position: position, Data: data})
+	}
+
+	images, err := h.examinationUseCase.UploadImages(c.Request.Context(), c.Param("id"), uploads)
+	if errors.Is(err, usecases.ErrInvalidPosition) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if errors.Is(err, usecases.ErrInvalidImage) {
+		c.JSON(http.StatusUnsupportedMediaType, dto.ErrorResponse{
+			Error:   "unsupported_media_type",
+			Message: err.Error(),
+			Code:    http.StatusUnsupportedMediaType,
+		})
+		return
+	}
+	if errors.Is(err, usecases.ErrExaminationNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "not_found",
+			Message: "Examination not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if errors.Is(err, usecases.ErrExaminationClosed) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error:   "examination_closed",
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "internal_error",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Success: true,
+		Data:    images,
+	})
+}
+
+func readUpload(file *multipart.FileHeader) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func respondUploadTooLarge(c *gin.Context, message string) {
+	c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{
+		Error:   "file_too_large",
+		Message: message,
+		Code:    http.StatusRequestEntityTooLarge,
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/application/usecases"
+	"github.com/project-capillary/backend/internal/infrastructure/fhir"
+)
+
+type FHIRHandler struct {
+	fhirUseCase *usecases.FHIRUseCase
+}
+
+func NewFHIRHandler(fhirUseCase *usecases.FHIRUseCase) *FHIRHandler {
+	return &FHIRHandler{fhirUseCase: fhirUseCase}
+}
+
+func (h *FHIRHandler) GetPatient(c *gin.Context) {
+	patient, err := h.fhirUseCase.GetPatient(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeFHIRError(c, err)
+		return
+	}
+	writeFHIR(c, http.StatusOK, patient)
+}
+
+func (h *FHIRHandler) UpsertPatient(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		writeFHIRError(c, err)
+		return
+	}
+
+	patient, created, err := h.fhirUseCase.UpsertPatient(c.Request.Context(), body)
+	if err != nil {
+		writeFHIRError(c, err)
+		return
+	}
+
+	c.Header("Location", fhirBaseURL(c)+"/Patient/"+patient.ID)
+	if created {
+		writeFHIR(c, http.StatusCreated, patient)
+		return
+	}
+	writeFHIR(c, http.StatusOK, patient)
+}
+
+func (h *FHIRHandler) ImportPatients(c *gin.Context) {
+	result, err := h.fhirUseCase.ImportPatients(c.Request.Context(), c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+func (h *FHIRHandler) GetDiagnosticReport(c *gin.Context) {
+	report, err := h.fhirUseCase.GetDiagnosticReport(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeFHIRError(c, err)
+		return
+	}
+	writeFHIR(c, http.StatusOK, report)
+}
+
+func (h *FHIRHandler) ExportExamination(c *gin.Context) {
+	bundle, err := h.fhirUseCase.ExportExamination(c.Request.Context(), c.Param("id"), fhirBaseURL(c))
+	if err != nil {
+		writeFHIRError(c, err)
+		return
+	}
+	writeFHIR(c, http.StatusOK, bundle)
+}
+
+func fhirBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	return scheme + "://" + c.Request.Host + "/fhir"
+}
+
+func writeFHIR(c *gin.Context, status int, resource interface{}) {
+	body, err := json.Marshal(resource)
+	if err != nil {
+		writeFHIRError(c, err)
+		return
+	}
+	c.Data(status, fhir.ContentType, body)
+}
+
+func writeFHIRError(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "exception"
+	switch {
+	case errors.Is(err, usecases.ErrPatientNotFound),
+		errors.Is(err, usecases.ErrExaminationNotFound),
+		errors.Is(err, usecases.ErrReportNotFound):
+		status, code = http.StatusNotFound, "not-found"
+	case errors.Is(err, usecases.ErrInvalidFHIRResource):
+		status, code = http.StatusBadRequest, "invalid"
+	}
+
+	body, _ := json.Marshal(fhir.NewOperationOutcome("error", code, err.Error()))
+	c.Data(status, fhir.ContentType, body)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/application/usecases"
+)
+
+type HL7Handler struct {
+	hl7UseCase *usecases.HL7UseCase
+}
+
+func NewHL7Handler(hl7UseCase *usecases.HL7UseCase) *HL7Handler {
+	return &HL7Handler{hl7UseCase: hl7UseCase}
+}
+
+// SendReport queues the report's result message for delivery and answers
+// with the pending message; its outcome is listed by ListReportMessages.
+func (h *HL7Handler) SendReport(c *gin.Context) {
+	message, err := h.hl7UseCase.QueueReport(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeHL7Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Success: true,
+		Data:    message,
+	})
+}
+
+func (h *HL7Handler) ListReportMessages(c *gin.Context) {
+	messages, err := h.hl7UseCase.ListReportMessages(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeHL7Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    messages,
+	})
+}
+
+func writeHL7Error(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, usecases.ErrReportNotFound),
+		errors.Is(err, usecases.ErrExaminationNotFound),
+		errors.Is(err, usecases.ErrPatientNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, usecases.ErrHL7Disabled):
+		status, code = http.StatusServiceUnavailable, "hl7_disabled"
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
+	})
+}
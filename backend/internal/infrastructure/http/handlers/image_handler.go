@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/application/usecases"
+	"github.com/project-capillary/backend/internal/infrastructure/imaging"
+)
+
+// Images never change once stored, but renditions may be re-rendered with
+// different settings, so caches revalidate daily against the ETag.
+const imageCacheControl = "private, max-age=86400"
+
+type ImageHandler struct {
+	imageUseCase *usecases.ImageUseCase
+}
+
+func NewImageHandler(imageUseCase *usecases.ImageUseCase) *ImageHandler {
+	return &ImageHandler{imageUseCase: imageUseCase}
+}
+
+// GetFile serves the original file of an image.
+func (h *ImageHandler) GetFile(c *gin.Context) {
+	content, err := h.imageUseCase.GetImage(c.Request.Context(), c.Param("id"))
+	serveImage(c, content, err)
+}
+
+// GetThumbnail serves a downscaled JPEG of an image; size is "thumbnail"
+// (the default) or "preview".
+func (h *ImageHandler) GetThumbnail(c *gin.Context) {
+	size := c.DefaultQuery("size", imaging.Thumbnail.Name)
+	content, err := h.imageUseCase.GetRendition(c.Request.Context(), c.Param("id"), size)
+	serveImage(c, content, err)
+}
+
+func serveImage(c *gin.Context, content *dto.ImageContent, err error) {
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_error"
+		switch {
+		case errors.Is(err, usecases.ErrUnknownRendition):
+			status, code = http.StatusBadRequest, "validation_error"
+		case errors.Is(err, usecases.ErrImageNotFound), errors.Is(err, usecases.ErrPhotoNotFound):
+			status, code = http.StatusNotFound, "not_found"
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.Header("Content-Type", content.ContentType)
+	c.Header("Cache-Control", imageCacheControl)
+	c.Header("ETag", `"`+content.ETag+`"`)
+	http.ServeContent(c.Writer, c.Request, content.Name, time.Time{}, bytes.NewReader(content.Data))
+}
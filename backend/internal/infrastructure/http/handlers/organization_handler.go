@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/application/usecases"
+)
+
+type OrganizationHandler struct {
+	organizationUseCase *usecases.OrganizationUseCase
+}
+
+func NewOrganizationHandler(organizationUseCase *usecases.OrganizationUseCase) *OrganizationHandler {
+	return &OrganizationHandler{organizationUseCase: organizationUseCase}
+}
+
+func (h *OrganizationHandler) GetCurrent(c *gin.Context) {
+	organization, err := h.organizationUseCase.GetCurrent(c.Request.Context())
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    organization,
+	})
+}
+
+func (h *OrganizationHandler) UpdateCurrent(c *gin.Context) {
+	var req dto.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	organization, err := h.organizationUseCase.UpdateCurrent(c.Request.Context(), req)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    organization,
+	})
+}
+
+func respondOrganizationError(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	if errors.Is(err, usecases.ErrOrganizationNotFound) {
+		status, code = http.StatusNotFound, "not_found"
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
+	})
+}
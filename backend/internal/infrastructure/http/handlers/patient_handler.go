@@ -1,8 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/project-capillary/backend/internal/application/dto"
@@ -11,10 +12,14 @@ import (
 
 type PatientHandler struct {
 	patientUseCase *usecases.PatientUseCase
+	erasureUseCase *usecases.PatientErasureUseCase
 }
 
-func NewPatientHandler(patientUseCase *usecases.PatientUseCase) *PatientHandler {
-	return &PatientHandler{patientUseCase: patientUseCase}
+func NewPatientHandler(patientUseCase *usecases.PatientUseCase, erasureUseCase *usecases.PatientErasureUseCase) *PatientHandler {
+	return &PatientHandler{
+		patientUseCase: patientUseCase,
+		erasureUseCase: erasureUseCase,
+	}
 }
 
 func (h *PatientHandler) CreatePatient(c *gin.Context) {
@@ -38,10 +43,14 @@ func (h *PatientHandler) CreatePatient(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.SuccessResponse{
+	response := dto.SuccessResponse{
 		Success: true,
 		Data:    patient,
-	})
+	}
+	if len(patient.PossibleDuplicates) > 0 {
+		response.Message = "Possible duplicate patients found"
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 func (h *PatientHandler) GetPatient(c *gin.Context) {
@@ -56,6 +65,9 @@ func (h *PatientHandler) GetPatient(c *gin.Context) {
 		return
 	}
 
+	if patient != nil {
+		setETag(c, patient.Version)
+	}
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
 		Data:    patient,
@@ -64,6 +76,11 @@ func (h *PatientHandler) GetPatient(c *gin.Context) {
 
 func (h *PatientHandler) UpdatePatient(c *gin.Context) {
 	id := c.Param("id")
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var req dto.UpdatePatientRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
@@ -74,7 +91,78 @@ func (h *PatientHandler) UpdatePatient(c *gin.Context) {
 		return
 	}
 
-	err := h.patientUseCase.UpdatePatient(c.Request.Context(), id, req)
+	patient, err := h.patientUseCase.UpdatePatient(c.Request.Context(), id, version, req)
+	if err != nil {
+		respondPatientError(c, err)
+		return
+	}
+
+	setETag(c, patient.Version)
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    patient,
+		Message: "Patient updated successfully",
+	})
+}
+
+func (h *PatientHandler) DeletePatient(c *gin.Context) {
+	id := c.Param("id")
+	err := h.patientUseCase.DeletePatient(c.Request.Context(), id)
+	if err != nil {
+		respondPatientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Patient deleted successfully",
+	})
+}
+
+func (h *PatientHandler) ListPatients(c *gin.Context) {
+	var query dto.PatientListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if query.MinAge != nil && query.MaxAge != nil && *query.MinAge > *query.MaxAge {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: "min_age must not exceed max_age",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	result, err := h.patientUseCase.ListPatients(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "internal_error",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *PatientHandler) ListDuplicates(c *gin.Context) {
+	var query dto.PageRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	duplicates, err := h.patientUseCase.ListDuplicates(c.Request.Context(), query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -86,13 +174,49 @@ func (h *PatientHandler) UpdatePatient(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
-		Message: "Patient updated successfully",
+		Data:    duplicates,
 	})
 }
 
-func (h *PatientHandler) DeletePatient(c *gin.Context) {
+func (h *PatientHandler) MergePatients(c *gin.Context) {
 	id := c.Param("id")
-	err := h.patientUseCase.DeletePatient(c.Request.Context(), id)
+	var req dto.MergePatientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	merge, err := h.patientUseCase.MergePatients(c.Request.Context(), id, req)
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_error"
+		switch {
+		case errors.Is(err, usecases.ErrUnauthenticated):
+			status, code = http.StatusUnauthorized, "unauthorized"
+		case errors.Is(err, usecases.ErrPatientNotFound):
+			status, code = http.StatusNotFound, "not_found"
+		case errors.Is(err, usecases.ErrMergeSamePatient), errors.Is(err, usecases.ErrUserNotFound):
+			status, code = http.StatusBadRequest, "validation_error"
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    merge,
+	})
+}
+
+func (h *PatientHandler) ListMerges(c *gin.Context) {
+	merges, err := h.patientUseCase.ListMerges(c.Request.Context(), c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -104,15 +228,94 @@ func (h *PatientHandler) DeletePatient(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
-		Message: "Patient deleted successfully",
+		Data:    merges,
 	})
 }
 
-func (h *PatientHandler) ListPatients(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+func (h *PatientHandler) RestorePatient(c *gin.Context) {
+	patient, err := h.patientUseCase.RestorePatient(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondPatientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    patient,
+		Message: "Patient restored successfully",
+	})
+}
+
+func (h *PatientHandler) PlaceLegalHold(c *gin.Context) {
+	var req dto.LegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if !req.Until.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: "until must be in the future",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
 
-	patients, err := h.patientUseCase.ListPatients(c.Request.Context(), limit, offset)
+	patient, err := h.patientUseCase.PlaceLegalHold(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondPatientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    patient,
+	})
+}
+
+func (h *PatientHandler) ReleaseLegalHold(c *gin.Context) {
+	patient, err := h.patientUseCase.ReleaseLegalHold(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondPatientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    patient,
+	})
+}
+
+func (h *PatientHandler) ErasePatient(c *gin.Context) {
+	var req dto.ErasePatientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	erasure, err := h.erasureUseCase.ErasePatient(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondPatientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    erasure,
+		Message: "Patient data erased",
+	})
+}
+
+func (h *PatientHandler) ListErasures(c *gin.Context) {
+	erasures, err := h.erasureUseCase.ListErasures(c.Request.Context(), c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -124,6 +327,28 @@ func (h *PatientHandler) ListPatients(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
-		Data:    patients,
+		Data:    erasures,
+	})
+}
+
+func respondPatientError(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, usecases.ErrVersionMismatch):
+		respondPreconditionFailed(c)
+		return
+	case errors.Is(err, usecases.ErrUnauthenticated):
+		status, code = http.StatusUnauthorized, "unauthorized"
+	case errors.Is(err, usecases.ErrPatientNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, usecases.ErrUserNotFound):
+		status, code = http.StatusBadRequest, "validation_error"
+	case errors.Is(err, usecases.ErrPatientErased), errors.Is(err, usecases.ErrErasureNotAllowed):
+		status, code = http.StatusConflict, "conflict"
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+		Code:    status,
 	})
 }
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/application/usecases"
+)
+
+// PhotoHandler manages the inbox of captured photos not yet attached to an
+// examination.
+type PhotoHandler struct {
+	inboxUseCase *usecases.PhotoInboxUseCase
+}
+
+func NewPhotoHandler(inboxUseCase *usecases.PhotoInboxUseCase) *PhotoHandler {
+	return &PhotoHandler{inboxUseCase: inboxUseCase}
+}
+
+func (h *PhotoHandler) ListInbox(c *gin.Context) {
+	var pageRequest dto.PageRequest
+	if err := c.ShouldBindQuery(&pageRequest); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	result, err := h.inboxUseCase.ListInbox(c.Request.Context(), pageRequest)
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_error"
+		if errors.Is(err, usecases.ErrUnauthenticated) {
+			status, code = http.StatusUnauthorized, "unauthorized"
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetPhoto serves a stored photo by its filename.
+func (h *PhotoHandler) GetPhoto(c *gin.Context) {
+	content, err := h.inboxUseCase.GetPhoto(c.Request.Context(), c.Param("filename"))
+	serveImage(c, content, err)
+}
+
+func (h *PhotoHandler) DeletePhoto(c *gin.Context) {
+	err := h.inboxUseCase.DeletePhoto(c.Request.Context(), c.Param("filename"))
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_error"
+		switch {
+		case errors.Is(err, usecases.ErrPhotoNotFound):
+			status, code = http.StatusNotFound, "not_found"
+		case errors.Is(err, usecases.ErrPhotoInUse):
+			status, code = http.StatusConflict, "photo_in_use"
+		case errors.Is(err, usecases.ErrUnauthenticated):
+			status, code = http.StatusUnauthorized, "unauthorized"
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Photo deleted",
+	})
+}
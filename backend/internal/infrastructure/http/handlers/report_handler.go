@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/project-capillary/backend/internal/application/dto"
@@ -29,6 +29,14 @@ func (h *ReportHandler) CreateReport(c *gin.Context) {
 	}
 
 	report, err := h.reportUseCase.CreateReport(c.Request.Context(), req)
+	if errors.Is(err, usecases.ErrExaminationNotFound) || errors.Is(err, usecases.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -56,6 +64,9 @@ func (h *ReportHandler) GetReport(c *gin.Context) {
 		return
 	}
 
+	if report != nil {
+		setETag(c, report.Version)
+	}
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
 		Data:    report,
@@ -74,6 +85,9 @@ func (h *ReportHandler) GetExaminationReport(c *gin.Context) {
 		return
 	}
 
+	if report != nil {
+		setETag(c, report.Version)
+	}
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
 		Data:    report,
@@ -81,10 +95,17 @@ func (h *ReportHandler) GetExaminationReport(c *gin.Context) {
 }
 
 func (h *ReportHandler) ListReports(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	var query dto.PageRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
 
-	reports, err := h.reportUseCase.ListReports(c.Request.Context(), limit, offset)
+	result, err := h.reportUseCase.ListReports(c.Request.Context(), query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -94,14 +115,16 @@ func (h *ReportHandler) ListReports(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Success: true,
-		Data:    reports,
-	})
+	c.JSON(http.StatusOK, result)
 }
 
 func (h *ReportHandler) UpdateReport(c *gin.Context) {
 	id := c.Param("id")
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var req dto.UpdateReportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
@@ -112,7 +135,27 @@ func (h *ReportHandler) UpdateReport(c *gin.Context) {
 		return
 	}
 
-	report, err := h.reportUseCase.UpdateReport(c.Request.Context(), id, req)
+	report, err := h.reportUseCase.UpdateReport(c.Request.Context(), id, version, req)
+	if errors.Is(err, usecases.ErrVersionMismatch) {
+		respondPreconditionFailed(c)
+		return
+	}
+	if errors.Is(err, usecases.ErrReportNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "not_found",
+			Message: "Report not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if errors.Is(err, usecases.ErrReportSigned) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "internal_error",
@@ -122,8 +165,61 @@ func (h *ReportHandler) UpdateReport(c *gin.Context) {
 		return
 	}
 
+	setETag(c, report.Version)
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
 		Data:    report,
 	})
 }
+
+func (h *ReportHandler) SignReport(c *gin.Context) {
+	report, err := h.reportUseCase.SignReport(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status, code := http.StatusInternalServerError, "internal_error"
+		switch {
+		case errors.Is(err, usecases.ErrReportNotFound):
+			status, code = http.StatusNotFound, "not_found"
+		case errors.Is(err, usecases.ErrReportSigned), errors.Is(err, usecases.ErrVersionMismatch):
+			status, code = http.StatusConflict, "conflict"
+		case errors.Is(err, usecases.ErrSignerNotAllowed):
+			status, code = http.StatusForbidden, "forbidden"
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   code,
+			Message: err.Error(),
+			Code:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+func (h *ReportHandler) VerifyReport(c *gin.Context) {
+	id := c.Param("id")
+	result, err := h.reportUseCase.VerifyReport(c.Request.Context(), id)
+	if errors.Is(err, usecases.ErrReportNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "not_found",
+			Message: "Report not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "internal_error",
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    result,
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -56,10 +57,19 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 
 	user, err := h.userUseCase.CreateUser(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "internal_error",
+		status, code := http.StatusInternalServerError, "internal_error"
+		switch {
+		case errors.Is(err, usecases.ErrOrganizationRequired), errors.Is(err, usecases.ErrRoleRequired):
+			status, code = http.StatusBadRequest, "validation_error"
+		case errors.Is(err, usecases.ErrAdminRequired):
+			status, code = http.StatusForbidden, "forbidden"
+		case errors.Is(err, usecases.ErrUserExists), errors.Is(err, usecases.ErrUsernameReserved):
+			status, code = http.StatusConflict, "conflict"
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   code,
 			Message: err.Error(),
-			Code:    http.StatusInternalServerError,
+			Code:    status,
 		})
 		return
 	}
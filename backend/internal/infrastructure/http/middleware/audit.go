@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/usecases"
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+var auditResourceTypes = map[string]string{
+	"patients":         "patient",
+	"examinations":     "examination",
+	"reports":          "report",
+	"users":            "user",
+	"photos":           "photo",
+	"images":           "image",
+	"devices":          "device",
+	"Patient":          "patient",
+	"DiagnosticReport": "report",
+}
+
+// auditParamResourceTypes maps route parameters that name a resource other
+// than the route's own, e.g. /examinations/patient/:patientId.
+var auditParamResourceTypes = map[string]string{
+	"patientId":     "patient",
+	"examinationId": "examination",
+}
+
+var auditMethodActions = map[string]string{
+	http.MethodGet:    entities.AuditActionRead,
+	http.MethodPost:   entities.AuditActionCreate,
+	http.MethodPut:    entities.AuditActionUpdate,
+	http.MethodPatch:  entities.AuditActionUpdate,
+	http.MethodDelete: entities.AuditActionDelete,
+}
+
+// Audit records every routed request after it has been handled. The
+// WebSocket endpoint is skipped here; the device manager audits individual
+// photo operations instead.
+func Audit(auditUseCase *usecases.AuditUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID, _ := c.Get("request_id")
+		requestIDValue, _ := requestID.(string)
+		ctx := usecases.WithRequestInfo(c.Request.Context(), c.ClientIP(), requestIDValue)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" || route == "/health" || route == "/ws" {
+			return
+		}
+
+		event := auditEvent(c.Request.Method, route, c.Params)
+		event.Status = c.Writer.Status()
+		if err := auditUseCase.Record(c.Request.Context(), event); err != nil {
+			log.Printf("Failed to record audit entry for %s %s: %v", c.Request.Method, route, err)
+		}
+	}
+}
+
+// auditEvent derives the action and resource from the matched route. POSTs
+// to a named sub-route (/reports/:id/sign) use its name as the action.
+func auditEvent(method, route string, params gin.Params) usecases.AuditEvent {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	if len(segments) > 0 && (segments[0] == "api" || segments[0] == "fhir") {
+		segments = segments[1:]
+	}
+
+	event := usecases.AuditEvent{Action: auditMethodActions[method], Route: route}
+	if len(segments) > 0 {
+		event.ResourceType = segments[0]
+		if resourceType, ok := auditResourceTypes[segments[0]]; ok {
+			event.ResourceType = resourceType
+		}
+	}
+	if last := segments[len(segments)-1]; method == http.MethodPost && len(segments) > 1 && !strings.HasPrefix(last, ":") {
+		event.Action = last
+	}
+
+	for _, param := range params {
+		if resourceType, ok := auditParamResourceTypes[param.Key]; ok {
+			event.ResourceType, event.ResourceID = resourceType, param.Value
+			break
+		}
+		if event.ResourceID == "" {
+			event.ResourceID = param.Value
+		}
+	}
+	return event
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuditEvent(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		route            string
+		params           gin.Params
+		wantAction       string
+		wantResourceType string
+		wantResourceID   string
+	}{
+		{name: "read patient", method: http.MethodGet, route: "/api/patients/:id", params: gin.Params{{Key: "id", Value: "p1"}}, wantAction: "read", wantResourceType: "patient", wantResourceID: "p1"},
+		{name: "list patients", method: http.MethodGet, route: "/api/patients", wantAction: "read", wantResourceType: "patient"},
+		{name: "create patient", method: http.MethodPost, route: "/api/patients", wantAction: "create", wantResourceType: "patient"},
+		{name: "sign report", method: http.MethodPost, route: "/api/reports/:id/sign", params: gin.Params{{Key: "id", Value: "r1"}}, wantAction: "sign", wantResourceType: "report", wantResourceID: "r1"},
+		{name: "release legal hold", method: http.MethodDelete, route: "/api/patients/:id/legal-hold", params: gin.Params{{Key: "id", Value: "p1"}}, wantAction: "delete", wantResourceType: "patient", wantResourceID: "p1"},
+		{name: "patient examinations", method: http.MethodGet, route: "/api/examinations/patient/:patientId", params: gin.Params{{Key: "patientId", Value: "p1"}}, wantAction: "read", wantResourceType: "patient", wantResourceID: "p1"},
+		{name: "photo", method: http.MethodGet, route: "/api/photos/:filename", params: gin.Params{{Key: "filename", Value: "photo.jpg"}}, wantAction: "read", wantResourceType: "photo", wantResourceID: "photo.jpg"},
+		{name: "online devices", method: http.MethodGet, route: "/api/devices/online", wantAction: "read", wantResourceType: "device"},
+		{name: "image thumbnail", method: http.MethodGet, route: "/api/images/:id/thumbnail", params: gin.Params{{Key: "id", Value: "i1"}}, wantAction: "read", wantResourceType: "image", wantResourceID: "i1"},
+		{name: "fhir patient", method: http.MethodGet, route: "/fhir/Patient/:id", params: gin.Params{{Key: "id", Value: "p1"}}, wantAction: "read", wantResourceType: "patient", wantResourceID: "p1"},
+		{name: "login", method: http.MethodPost, route: "/api/auth/login", wantAction: "login", wantResourceType: "auth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := auditEvent(tt.method, tt.route, tt.params)
+			if event.Action != tt.wantAction || event.ResourceType != tt.wantResourceType || event.ResourceID != tt.wantResourceID {
+				t.Errorf("auditEvent() = %s %s/%s, want %s %s/%s",
+					event.Action, event.ResourceType, event.ResourceID,
+					tt.wantAction, tt.wantResourceType, tt.wantResourceID)
+			}
+			if event.Route != tt.route {
+				t.Errorf("Route = %q, want %q", event.Route, tt.route)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
+)
+
+// TenantScoper binds ctx to an organization for the database; release
+// undoes it.
+type TenantScoper func(ctx context.Context, organizationID string) (scoped context.Context, release func(), err error)
+
+// TenantScope binds every authenticated request to the caller's
+// organization for its duration. WebSocket connections live too long to
+// hold a scoped database connection; the device manager scopes each of
+// their messages instead.
+func TenantScope(scope TenantScoper) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := auth.ClaimsFromContext(c.Request.Context())
+		if claims == nil || c.IsWebsocket() {
+			c.Next()
+			return
+		}
+
+		parent := c.Request.Context()
+		ctx, release, err := scope(parent, claims.OrganizationID)
+		if err != nil {
+			log.Printf("Failed to scope request to organization %s: %v", claims.OrganizationID, err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+				Error:   "service_unavailable",
+				Message: "Database unavailable",
+				Code:    http.StatusServiceUnavailable,
+			})
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		defer func() {
+			release()
+			// Audit and other outer middleware must not use the released
+			// connection.
+			c.Request = c.Request.WithContext(parent)
+		}()
+
+		c.Next()
+	}
+}
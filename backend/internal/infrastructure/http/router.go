@@ -2,40 +2,83 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/project-capillary/backend/internal/application/usecases"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
 	"github.com/project-capillary/backend/internal/infrastructure/http/handlers"
 	"github.com/project-capillary/backend/internal/infrastructure/http/middleware"
 	"github.com/project-capillary/backend/internal/infrastructure/ws"
 )
 
 type Router struct {
-	patientHandler     *handlers.PatientHandler
-	examinationHandler *handlers.ExaminationHandler
-	userHandler        *handlers.UserHandler
-	reportHandler      *handlers.ReportHandler
-	deviceManager      *ws.DeviceManager
+	patientHandler      *handlers.PatientHandler
+	examinationHandler  *handlers.ExaminationHandler
+	imageHandler        *handlers.ImageHandler
+	photoHandler        *handlers.PhotoHandler
+	userHandler         *handlers.UserHandler
+	reportHandler       *handlers.ReportHandler
+	fhirHandler         *handlers.FHIRHandler
+	dicomHandler        *handlers.DICOMHandler
+	hl7Handler          *handlers.HL7Handler
+	auditHandler        *handlers.AuditHandler
+	organizationHandler *handlers.OrganizationHandler
+	deviceHandler       *handlers.DeviceHandler
+	deviceManager       *ws.DeviceManager
+	auditUseCase        *usecases.AuditUseCase
+	tokens              *auth.TokenIssuer
+	urls                *auth.URLSigner
+	tenantScope         middleware.TenantScoper
 }
 
 func NewRouter(
 	patientHandler *handlers.PatientHandler,
 	examinationHandler *handlers.ExaminationHandler,
+	imageHandler *handlers.ImageHandler,
+	photoHandler *handlers.PhotoHandler,
 	userHandler *handlers.UserHandler,
 	reportHandler *handlers.ReportHandler,
+	fhirHandler *handlers.FHIRHandler,
+	dicomHandler *handlers.DICOMHandler,
+	hl7Handler *handlers.HL7Handler,
+	auditHandler *handlers.AuditHandler,
+	organizationHandler *handlers.OrganizationHandler,
+	deviceHandler *handlers.DeviceHandler,
 	deviceManager *ws.DeviceManager,
+	auditUseCase *usecases.AuditUseCase,
+	tokens *auth.TokenIssuer,
+	urls *auth.URLSigner,
+	tenantScope middleware.TenantScoper,
 ) *Router {
 	return &Router{
-		patientHandler:     patientHandler,
-		examinationHandler: examinationHandler,
-		userHandler:        userHandler,
-		reportHandler:      reportHandler,
-		deviceManager:      deviceManager,
+		patientHandler:      patientHandler,
+		examinationHandler:  examinationHandler,
+		imageHandler:        imageHandler,
+		photoHandler:        photoHandler,
+		userHandler:         userHandler,
+		reportHandler:       reportHandler,
+		fhirHandler:         fhirHandler,
+		dicomHandler:        dicomHandler,
+		hl7Handler:          hl7Handler,
+		auditHandler:        auditHandler,
+		organizationHandler: organizationHandler,
+		deviceHandler:       deviceHandler,
+		deviceManager:       deviceManager,
+		auditUseCase:        auditUseCase,
+		tokens:              tokens,
+		urls:                urls,
+		tenantScope:         tenantScope,
 	}
 }
 
 func (r *Router) Setup() *gin.Engine {
 	router := gin.Default()
 
+	router.Use(middleware.RequestID())
 	router.Use(middleware.CORS())
 	router.Use(middleware.Logger())
+	router.Use(middleware.Audit(r.auditUseCase))
+	router.Use(middleware.Authenticate(r.tokens))
+	router.Use(middleware.TenantScope(r.tenantScope))
 
 	api := router.Group("/api")
 	{
@@ -45,45 +88,106 @@ func (r *Router) Setup() *gin.Engine {
 			auth.POST("/register", r.userHandler.CreateUser)
 		}
 
-		users := api.Group("/users")
+		secured := api.Group("", middleware.RequireAuth())
+
+		organization := secured.Group("/organization")
+		{
+			organization.GET("", r.organizationHandler.GetCurrent)
+			organization.PUT("", middleware.RequireRole(entities.RoleAdmin), r.organizationHandler.UpdateCurrent)
+		}
+
+		users := secured.Group("/users")
 		{
 			users.GET("/:id", r.userHandler.GetUser)
 		}
 
-		patients := api.Group("/patients")
+		patients := secured.Group("/patients")
 		{
 			patients.POST("", r.patientHandler.CreatePatient)
 			patients.GET("/:id", r.patientHandler.GetPatient)
 			patients.PUT("/:id", r.patientHandler.UpdatePatient)
 			patients.DELETE("/:id", r.patientHandler.DeletePatient)
 			patients.GET("", r.patientHandler.ListPatients)
+			patients.GET("/duplicates", r.patientHandler.ListDuplicates)
+			patients.POST("/:id/merge", r.patientHandler.MergePatients)
+			patients.GET("/:id/merges", r.patientHandler.ListMerges)
+			patients.POST("/:id/restore", r.patientHandler.RestorePatient)
+			patients.PUT("/:id/legal-hold", r.patientHandler.PlaceLegalHold)
+			patients.DELETE("/:id/legal-hold", r.patientHandler.ReleaseLegalHold)
+			patients.POST("/:id/erase", middleware.RequireRole(entities.RoleAdmin), r.patientHandler.ErasePatient)
+			patients.GET("/:id/erasures", r.patientHandler.ListErasures)
+			patients.POST("/import", r.fhirHandler.ImportPatients)
 		}
 
-		examinations := api.Group("/examinations")
+		examinations := secured.Group("/examinations")
 		{
 			examinations.POST("", r.examinationHandler.CreateExamination)
 			examinations.GET("", r.examinationHandler.ListExaminations)
 			examinations.GET("/:id", r.examinationHandler.GetExamination)
 			examinations.POST("/:id/photos", r.examinationHandler.AttachPhotos)
+			examinations.POST("/:id/images", r.examinationHandler.UploadImages)
 			examinations.POST("/:id/analyze", r.examinationHandler.StartAnalysis)
+			examinations.GET("/:id/protocol", r.examinationHandler.GetCaptureProgress)
 			examinations.GET("/patient/:patientId", r.examinationHandler.GetPatientExaminations)
+			examinations.GET("/:id/fhir", r.fhirHandler.ExportExamination)
+			examinations.GET("/:id/dicom", r.dicomHandler.ExportExamination)
+		}
+
+		photos := secured.Group("/photos")
+		{
+			photos.GET("", r.photoHandler.ListInbox)
+			photos.DELETE("/:filename", r.photoHandler.DeletePhoto)
+		}
+
+		// Images are also loaded by <img> tags, which send no bearer token
+		// but may use a signed URL instead.
+		images := api.Group("/images", middleware.RequireAuthOrSignature(r.urls))
+		{
+			images.GET("/:id/file", r.imageHandler.GetFile)
+			images.GET("/:id/thumbnail", r.imageHandler.GetThumbnail)
 		}
 
-		reports := api.Group("/reports")
+		reports := secured.Group("/reports")
 		{
 			reports.GET("", r.reportHandler.ListReports)
 			reports.POST("", r.reportHandler.CreateReport)
 			reports.GET("/:id", r.reportHandler.GetReport)
 			reports.PUT("/:id", r.reportHandler.UpdateReport)
+			reports.POST("/:id/sign", middleware.RequireRole(entities.RoleDoctor), r.reportHandler.SignReport)
+			reports.GET("/:id/verify", r.reportHandler.VerifyReport)
+			reports.POST("/:id/hl7", r.hl7Handler.SendReport)
+			reports.GET("/:id/hl7", r.hl7Handler.ListReportMessages)
 			reports.GET("/examination/:examinationId", r.reportHandler.GetExaminationReport)
 		}
+
+		devices := secured.Group("/devices")
+		{
+			devices.GET("", r.deviceHandler.ListDevices)
+			devices.GET("/online", r.deviceHandler.ListOnlineDevices)
+			devices.GET("/:id", r.deviceHandler.GetDevice)
+			devices.POST("", middleware.RequireRole(entities.RoleAdmin), r.deviceHandler.CreateDevice)
+			devices.PUT("/:id", middleware.RequireRole(entities.RoleAdmin), r.deviceHandler.UpdateDevice)
+			devices.DELETE("/:id", middleware.RequireRole(entities.RoleAdmin), r.deviceHandler.DeleteDevice)
+		}
+
+		audit := secured.Group("/audit", middleware.RequireRole(entities.RoleAdmin))
+		{
+			audit.GET("", r.auditHandler.ListEntries)
+			audit.GET("/verify", r.auditHandler.Verify)
+		}
 	}
 
-	api.GET("/photos/:filename", func(c *gin.Context) {
-		r.deviceManager.ServePhoto(c.Writer, c.Request, c.Param("filename"))
-	})
+	fhirGroup := router.Group("/fhir", middleware.RequireAuth())
+	{
+		fhirGroup.POST("/Patient", r.fhirHandler.UpsertPatient)
+		fhirGroup.GET("/Patient/:id", r.fhirHandler.GetPatient)
+		fhirGroup.GET("/DiagnosticReport/:id", r.fhirHandler.GetDiagnosticReport)
+	}
+
+	api.GET("/photos/:filename", middleware.RequireAuthOrSignature(r.urls), r.photoHandler.GetPhoto)
 
-	router.GET("/ws", func(c *gin.Context) {
+	// Browsers pass the token of the upgrade request as a query parameter.
+	router.GET("/ws", middleware.RequireAuth(), func(c *gin.Context) {
 		r.deviceManager.HandleWebSocket(c.Writer, c.Request)
 	})
 
@@ -0,0 +1,245 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// EXIF holds the tags we keep from a capture. Zero values mean the camera
+// did not record the tag.
+type EXIF struct {
+	Make         string
+	Model        string
+	TakenAt      time.Time
+	ExposureTime float64 // seconds
+	ISO          int
+	XResolution  float64
+	// ResolutionUnit is 2 for inches and 3 for centimetres.
+	ResolutionUnit int
+}
+
+const (
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagXResolution      = 0x011a
+	tagResolutionUnit   = 0x0128
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagExposureTime     = 0x829a
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+)
+
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// readEXIF finds the EXIF block of a JPEG, PNG or TIFF file. It returns nil
+// when there is none or it cannot be parsed; metadata is best effort.
+func readEXIF(format string, data []byte) *EXIF {
+	var block []byte
+	switch format {
+	case "jpeg":
+		block = jpegSegment(data, 0xe1, []byte("Exif\x00\x00"))
+	case "png":
+		block = pngChunk(data, "eXIf")
+	case "tiff":
+		block = data
+	}
+	if block == nil {
+		return nil
+	}
+	return parseTIFF(block)
+}
+
+func parseTIFF(b []byte) *EXIF {
+	if len(b) < 8 {
+		return nil
+	}
+	var order binary.ByteOrder
+	switch string(b[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil
+	}
+	if order.Uint16(b[2:]) != 42 {
+		return nil
+	}
+
+	ifd0 := readIFD(b, order, order.Uint32(b[4:]))
+	if ifd0 == nil {
+		return nil
+	}
+	exif := &EXIF{
+		Make:           ifd0.ascii(tagMake),
+		Model:          ifd0.ascii(tagModel),
+		XResolution:    ifd0.rational(tagXResolution),
+		ResolutionUnit: int(ifd0.uint(tagResolutionUnit)),
+	}
+	takenAt, offset := ifd0.ascii(tagDateTime), ""
+	if pointer := ifd0.uint(tagExifIFD); pointer != 0 {
+		if sub := readIFD(b, order, pointer); sub != nil {
+			exif.ExposureTime = sub.rational(tagExposureTime)
+			exif.ISO = int(sub.uint(tagISO))
+			if original := sub.ascii(tagDateTimeOriginal); original != "" {
+				takenAt = original
+			}
+			offset = sub.ascii(tagOffsetOriginal)
+		}
+	}
+	exif.TakenAt = parseEXIFTime(takenAt, offset)
+	return exif
+}
+
+// EXIF times are local to the camera; without an offset tag the server's
+// zone is the best guess.
+func parseEXIFTime(value, offset string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if offset != "" {
+		if t, err := time.Parse(exifTimeLayout+"-07:00", value+offset); err == nil {
+			return t
+		}
+	}
+	t, err := time.ParseInLocation(exifTimeLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+type ifd struct {
+	order   binary.ByteOrder
+	entries map[uint16]ifdEntry
+}
+
+var typeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+func readIFD(b []byte, order binary.ByteOrder, offset uint32) *ifd {
+	if uint64(offset)+2 > uint64(len(b)) {
+		return nil
+	}
+	count := uint32(order.Uint16(b[offset:]))
+	start := uint64(offset) + 2
+	if start+uint64(count)*12 > uint64(len(b)) {
+		return nil
+	}
+
+	dir := &ifd{order: order, entries: make(map[uint16]ifdEntry, count)}
+	for i := uint32(0); i < count; i++ {
+		entry := b[start+uint64(i)*12:]
+		tag, typ, n := order.Uint16(entry), order.Uint16(entry[2:]), order.Uint32(entry[4:])
+		size, ok := typeSizes[typ]
+		if !ok {
+			continue
+		}
+		length := uint64(size) * uint64(n)
+		value := entry[8:12]
+		if length > 4 {
+			at := uint64(order.Uint32(entry[8:]))
+			if at+length > uint64(len(b)) {
+				continue
+			}
+			value = b[at : at+length]
+		} else {
+			value = value[:length]
+		}
+		dir.entries[tag] = ifdEntry{typ: typ, count: n, value: value}
+	}
+	return dir
+}
+
+func (d *ifd) ascii(tag uint16) string {
+	entry, ok := d.entries[tag]
+	if !ok || entry.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(entry.value), "\x00"))
+}
+
+func (d *ifd) uint(tag uint16) uint32 {
+	entry, ok := d.entries[tag]
+	if !ok || entry.count == 0 {
+		return 0
+	}
+	switch entry.typ {
+	case 3:
+		return uint32(d.order.Uint16(entry.value))
+	case 4:
+		return d.order.Uint32(entry.value)
+	}
+	return 0
+}
+
+func (d *ifd) rational(tag uint16) float64 {
+	entry, ok := d.entries[tag]
+	if !ok || entry.count == 0 || entry.typ != 5 {
+		return 0
+	}
+	numerator, denominator := d.order.Uint32(entry.value), d.order.Uint32(entry.value[4:])
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// jpegSegment returns the payload after prefix of the first marker segment
+// of the given type, looking only at the headers before the image data.
+func jpegSegment(data []byte, marker byte, prefix []byte) []byte {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return nil
+		}
+		typ := data[i+1]
+		if typ == 0xda || typ == 0xd9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil
+		}
+		payload := data[i+4 : i+2+length]
+		if typ == marker && bytes.HasPrefix(payload, prefix) {
+			return payload[len(prefix):]
+		}
+		i += 2 + length
+	}
+	return nil
+}
+
+// pngChunk returns the data of the first chunk of the given type.
+func pngChunk(data []byte, name string) []byte {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil
+	}
+	for i := len(signature); i+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		typ := string(data[i+4 : i+8])
+		if length < 0 || i+12+length > len(data) {
+			return nil
+		}
+		if typ == name {
+			return data[i+8 : i+8+length]
+		}
+		if typ == "IDAT" || typ == "IEND" {
+			return nil
+		}
+		i += 12 + length
+	}
+	return nil
+}
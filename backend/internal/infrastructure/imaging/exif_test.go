@@ -0,0 +1,168 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+// tiffBlock builds a big-endian EXIF block with camera, resolution,
+// exposure and capture time tags.
+func tiffBlock() []byte {
+	type entry struct {
+		tag, typ uint16
+		count    uint32
+		value    []byte
+	}
+	be := binary.BigEndian
+	u16 := func(v uint16) []byte { b := make([]byte, 2); be.PutUint16(b, v); return b }
+	u32 := func(v uint32) []byte { b := make([]byte, 4); be.PutUint32(b, v); return b }
+	rational := func(n, d uint32) []byte { return append(u32(n), u32(d)...) }
+	ascii := func(s string) []byte { return append([]byte(s), 0) }
+
+	var buf bytes.Buffer
+	buf.WriteString("MM")
+	buf.Write(u16(42))
+	buf.Write(u32(8))
+
+	// writeIFD lays out entries at offset with their out-of-line values
+	// following the directory.
+	writeIFD := func(offset uint32, entries []entry) []byte {
+		var dir, data bytes.Buffer
+		dataOffset := offset + 2 + uint32(len(entries))*12 + 4
+		dir.Write(u16(uint16(len(entries))))
+		for _, e := range entries {
+			dir.Write(u16(e.tag))
+			dir.Write(u16(e.typ))
+			dir.Write(u32(e.count))
+			if len(e.value) <= 4 {
+				dir.Write(append(e.value, make([]byte, 4-len(e.value))...))
+				continue
+			}
+			dir.Write(u32(dataOffset + uint32(data.Len())))
+			data.Write(e.value)
+		}
+		dir.Write(u32(0))
+		return append(dir.Bytes(), data.Bytes()...)
+	}
+
+	sub := []entry{
+		{tagExposureTime, 5, 1, rational(1, 125)},
+		{tagISO, 3, 1, u16(200)},
+		{tagDateTimeOriginal, 2, 20, ascii("2024:03:05 10:20:30")},
+		{tagOffsetOriginal, 2, 7, ascii("+02:00")},
+	}
+	ifd0Entries := []entry{
+		{tagMake, 2, 8, ascii("Dino-Li")},
+		{tagModel, 2, 8, ascii("AM4115T")},
+		{tagXResolution, 5, 1, rational(300, 1)},
+		{tagResolutionUnit, 3, 1, u16(2)},
+		{tagDateTime, 2, 20, ascii("2024:03:06 00:00:00")},
+		{tagExifIFD, 4, 1, nil},
+	}
+	ifd0Size := uint32(2 + len(ifd0Entries)*12 + 4)
+	for _, e := range ifd0Entries[:5] {
+		if len(e.value) > 4 {
+			ifd0Size += uint32(len(e.value))
+		}
+	}
+	ifd0Entries[5].value = u32(8 + ifd0Size)
+
+	buf.Write(writeIFD(8, ifd0Entries))
+	buf.Write(writeIFD(8+ifd0Size, sub))
+	return buf.Bytes()
+}
+
+func withSegment(jpegData []byte, marker byte, payload []byte) []byte {
+	segment := []byte{0xff, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, segment...)
+	out = append(out, payload...)
+	return append(out, jpegData[2:]...)
+}
+
+func withPNGChunk(pngData []byte, name string, payload []byte) []byte {
+	// The chunk goes after the signature and the 25-byte IHDR chunk.
+	const at = 8 + 25
+	chunk := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(chunk, uint32(len(payload)))
+	copy(chunk[4:], name)
+	chunk = append(chunk, payload...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(chunk[4:]))
+	chunk = append(chunk, crc...)
+
+	out := append([]byte{}, pngData[:at]...)
+	out = append(out, chunk...)
+	return append(out, pngData[at:]...)
+}
+
+func TestInspectEXIF(t *testing.T) {
+	data := withSegment(encode(t, "jpeg"), 0xe1, append([]byte("Exif\x00\x00"), tiffBlock()...))
+	info, err := Inspect(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.EXIF == nil {
+		t.Fatal("EXIF = nil")
+	}
+	if info.EXIF.Make != "Dino-Li" || info.EXIF.Model != "AM4115T" || info.EXIF.ISO != 200 || info.EXIF.ExposureTime != 1.0/125 {
+		t.Errorf("EXIF = %+v", info.EXIF)
+	}
+	wantTaken := time.Date(2024, 3, 5, 8, 20, 30, 0, time.UTC)
+	if !info.EXIF.TakenAt.Equal(wantTaken) {
+		t.Errorf("TakenAt = %v, want %v", info.EXIF.TakenAt, wantTaken)
+	}
+	if info.DPI != 300 || info.ColorSpace != "YCbCr" {
+		t.Errorf("DPI, ColorSpace = %d, %s", info.DPI, info.ColorSpace)
+	}
+
+	metadata := info.Metadata(int64(len(data)))
+	if metadata.Format != "jpeg" || metadata.Width != 64 || metadata.CameraModel != "AM4115T" ||
+		metadata.TakenAt == nil || !metadata.TakenAt.Equal(wantTaken) || metadata.SizeBytes != int64(len(data)) {
+		t.Errorf("Metadata = %+v", metadata)
+	}
+}
+
+func TestInspectDPI(t *testing.T) {
+	jfif := []byte("JFIF\x00\x01\x02\x01\x00\x60\x00\x60\x00\x00")
+	jfifCM := []byte("JFIF\x00\x01\x02\x02\x00\x76\x00\x76\x00\x00")
+	phys := []byte{0, 0, 0x0b, 0x13, 0, 0, 0x0b, 0x13, 1}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"jpeg without density", encode(t, "jpeg"), 0},
+		{"jfif dpi", withSegment(encode(t, "jpeg"), 0xe0, jfif), 96},
+		{"jfif dots per cm", withSegment(encode(t, "jpeg"), 0xe0, jfifCM), 300},
+		{"png phys", withPNGChunk(encode(t, "png"), "pHYs", phys), 72},
+		{"png without phys", encode(t, "png"), 0},
+	}
+	for _, tt := range tests {
+		info, err := Inspect(tt.data)
+		if err != nil {
+			t.Errorf("Inspect(%s) error = %v", tt.name, err)
+			continue
+		}
+		if info.DPI != tt.want {
+			t.Errorf("Inspect(%s).DPI = %d, want %d", tt.name, info.DPI, tt.want)
+		}
+	}
+}
+
+func TestParseTIFFMalformed(t *testing.T) {
+	block := tiffBlock()
+	for i := 0; i < len(block); i++ {
+		parseTIFF(block[:i])
+	}
+	corrupt := append([]byte{}, block...)
+	binary.BigEndian.PutUint32(corrupt[4:], 0xffffff00)
+	if exif := parseTIFF(corrupt); exif != nil {
+		t.Errorf("parseTIFF(bad offset) = %+v, want nil", exif)
+	}
+}
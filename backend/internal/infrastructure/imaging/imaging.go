@@ -0,0 +1,95 @@
+// Package imaging identifies and decodes the photo formats capillaroscopes
+// produce.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/project-capillary/backend/internal/domain/valueobjects"
+	_ "golang.org/x/image/tiff"
+)
+
+// MaxPixels bounds the decoded size of an image, so a small file claiming
+// huge dimensions cannot exhaust memory.
+const MaxPixels = 100_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrInvalidImage      = errors.New("invalid image")
+)
+
+type Format struct {
+	Name     string
+	MimeType string
+	Ext      string
+}
+
+var formats = map[string]Format{
+	"jpeg": {Name: "jpeg", MimeType: "image/jpeg", Ext: ".jpg"},
+	"png":  {Name: "png", MimeType: "image/png", Ext: ".png"},
+	"tiff": {Name: "tiff", MimeType: "image/tiff", Ext: ".tif"},
+}
+
+type Info struct {
+	Format
+	Width      int
+	Height     int
+	ColorSpace string
+	DPI        int
+	EXIF       *EXIF
+}
+
+// Inspect identifies data as JPEG, PNG or TIFF and decodes it completely,
+// so truncated or corrupt files are rejected rather than stored.
+func Inspect(data []byte) (*Info, error) {
+	config, name, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	format, ok := formats[name]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrInvalidImage, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	info := &Info{
+		Format:     format,
+		Width:      config.Width,
+		Height:     config.Height,
+		ColorSpace: colorSpace(img.ColorModel()),
+		EXIF:       readEXIF(name, data),
+	}
+	info.DPI = dpi(name, data, info.EXIF)
+	return info, nil
+}
+
+// Metadata describes the image as the domain stores it.
+func (i *Info) Metadata(sizeBytes int64) *valueobjects.ImageMetadata {
+	metadata := valueobjects.NewImageMetadata(i.Width, i.Height, i.Name, sizeBytes, i.ColorSpace, i.DPI)
+	if i.EXIF != nil {
+		metadata.CameraMake = i.EXIF.Make
+		metadata.CameraModel = i.EXIF.Model
+		metadata.ExposureTime = i.EXIF.ExposureTime
+		metadata.ISO = i.EXIF.ISO
+		if !i.EXIF.TakenAt.IsZero() {
+			takenAt := i.EXIF.TakenAt
+			metadata.TakenAt = &takenAt
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,126 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/tiff"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 5), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encode(t *testing.T, format string) []byte {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, testImage(), nil)
+	case "png":
+		err = png.Encode(&buf, testImage())
+	case "tiff":
+		err = tiff.Encode(&buf, testImage(), nil)
+	case "gif":
+		err = gif.Encode(&buf, testImage(), nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		format   string
+		mimeType string
+		ext      string
+	}{
+		{"jpeg", "image/jpeg", ".jpg"},
+		{"png", "image/png", ".png"},
+		{"tiff", "image/tiff", ".tif"},
+	}
+	for _, tt := range tests {
+		info, err := Inspect(encode(t, tt.format))
+		if err != nil {
+			t.Errorf("Inspect(%s) error = %v", tt.format, err)
+			continue
+		}
+		if info.MimeType != tt.mimeType || info.Ext != tt.ext || info.Width != 64 || info.Height != 48 {
+			t.Errorf("Inspect(%s) = %+v", tt.format, info)
+		}
+	}
+}
+
+func TestInspectRejects(t *testing.T) {
+	png := encode(t, "png")
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("not an image"), ErrUnsupportedFormat},
+		{"gif", encode(t, "gif"), ErrUnsupportedFormat},
+		{"truncated png", png[:len(png)/2], ErrInvalidImage},
+		{"truncated header", png[:20], ErrInvalidImage},
+	}
+	for _, tt := range tests {
+		if _, err := Inspect(tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("Inspect(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		width, height, limit int
+		wantW, wantH         int
+	}{
+		{64, 48, 256, 64, 48},
+		{2592, 1944, 256, 256, 192},
+		{1944, 2592, 256, 192, 256},
+		{1024, 1024, 256, 256, 256},
+		{10000, 10, 256, 256, 1},
+	}
+	for _, tt := range tests {
+		w, h := fit(tt.width, tt.height, tt.limit)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("fit(%d, %d, %d) = %d, %d, want %d, %d", tt.width, tt.height, tt.limit, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	small := Rendition{Name: "small", MaxSize: 32}
+	for _, format := range []string{"jpeg", "png", "tiff"} {
+		data, err := Render(encode(t, format), small)
+		if err != nil {
+			t.Errorf("Render(%s) error = %v", format, err)
+			continue
+		}
+		info, err := Inspect(data)
+		if err != nil {
+			t.Errorf("Render(%s) produced an invalid image: %v", format, err)
+			continue
+		}
+		if info.Name != "jpeg" || info.Width != 32 || info.Height != 24 {
+			t.Errorf("Render(%s) = %s %dx%d, want jpeg 32x24", format, info.Name, info.Width, info.Height)
+		}
+	}
+
+	if _, err := Render([]byte("not an image"), Thumbnail); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("Render(text) error = %v, want %v", err, ErrInvalidImage)
+	}
+}
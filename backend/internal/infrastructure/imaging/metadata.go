@@ -0,0 +1,57 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image/color"
+	"math"
+)
+
+func colorSpace(model color.Model) string {
+	switch model {
+	case color.GrayModel, color.Gray16Model:
+		return "Gray"
+	case color.CMYKModel:
+		return "CMYK"
+	case color.YCbCrModel, color.NYCbCrAModel:
+		return "YCbCr"
+	}
+	if _, ok := model.(color.Palette); ok {
+		return "Indexed"
+	}
+	return "RGB"
+}
+
+// dpi prefers the EXIF resolution and falls back to the JFIF header of a
+// JPEG or the pHYs chunk of a PNG. It returns 0 when none is recorded.
+func dpi(format string, data []byte, exif *EXIF) int {
+	if exif != nil && exif.XResolution > 0 {
+		switch exif.ResolutionUnit {
+		case 0, 2:
+			return int(math.Round(exif.XResolution))
+		case 3:
+			return int(math.Round(exif.XResolution * 2.54))
+		}
+	}
+
+	switch format {
+	case "jpeg":
+		jfif := jpegSegment(data, 0xe0, []byte("JFIF\x00"))
+		if len(jfif) < 7 {
+			return 0
+		}
+		density := float64(binary.BigEndian.Uint16(jfif[3:]))
+		switch jfif[2] {
+		case 1:
+			return int(density)
+		case 2:
+			return int(math.Round(density * 2.54))
+		}
+	case "png":
+		phys := pngChunk(data, "pHYs")
+		if len(phys) < 9 || phys[8] != 1 {
+			return 0
+		}
+		return int(math.Round(float64(binary.BigEndian.Uint32(phys)) * 0.0254))
+	}
+	return 0
+}
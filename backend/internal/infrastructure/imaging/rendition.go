@@ -0,0 +1,70 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	"golang.org/x/image/draw"
+)
+
+// Rendition is a downscaled JPEG copy of a photo for display in lists and
+// viewers, bounded by MaxSize pixels on its longer side.
+type Rendition struct {
+	Name    string
+	MaxSize int
+}
+
+var (
+	Thumbnail = Rendition{Name: "thumbnail", MaxSize: 256}
+	Preview   = Rendition{Name: "preview", MaxSize: 1024}
+)
+
+// Renditions are the sizes generated for every stored photo.
+var Renditions = []Rendition{Thumbnail, Preview}
+
+const renditionQuality = 85
+
+func LookupRendition(name string) (Rendition, bool) {
+	for _, r := range Renditions {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Rendition{}, false
+}
+
+// Render decodes data and encodes it as a JPEG rendition. Smaller images are
+// not enlarged, only converted; transparency is flattened onto white.
+func Render(data []byte, r Rendition) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	bounds := src.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), r.MaxSize)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: renditionQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit scales width and height to at most limit on the longer side, keeping
+// the aspect ratio and at least one pixel on each side.
+func fit(width, height, limit int) (int, int) {
+	if width <= limit && height <= limit {
+		return width, height
+	}
+	if width >= height {
+		return limit, max(1, height*limit/width)
+	}
+	return max(1, width*limit/height), limit
+}
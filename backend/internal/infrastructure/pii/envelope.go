@@ -0,0 +1,128 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const envelopeVersion = 1
+
+var ErrInvalidEnvelope = errors.New("invalid encrypted value")
+
+// Seal encrypts plaintext under a fresh data key and wraps that key with the
+// active KEK. aad binds the ciphertext to its record (e.g. the row ID) so it
+// cannot be moved to another one. The layout is:
+//
+//	version | len(kekID) | kekID | nonce | wrapped data key | nonce | ciphertext
+func (k *Keyring) Seal(plaintext, aad []byte) ([]byte, error) {
+	dataKey, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(k.Keys[k.Active], dataKey, []byte(k.Active))
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(dataKey, plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 2+len(k.Active)+len(wrapped)+len(ciphertext))
+	out = append(out, envelopeVersion, byte(len(k.Active)))
+	out = append(out, k.Active...)
+	out = append(out, wrapped...)
+	return append(out, ciphertext...), nil
+}
+
+func (k *Keyring) Open(sealed, aad []byte) ([]byte, error) {
+	keyID, rest, err := splitEnvelope(sealed)
+	if err != nil {
+		return nil, err
+	}
+	kek, ok := k.Keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+
+	wrappedSize := nonceSize + keySize + tagSize
+	if len(rest) < wrappedSize {
+		return nil, ErrInvalidEnvelope
+	}
+	dataKey, err := open(kek, rest[:wrappedSize], []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, rest[wrappedSize:], aad)
+}
+
+// KeyID returns the ID of the KEK a value was sealed under.
+func KeyID(sealed []byte) (string, error) {
+	keyID, _, err := splitEnvelope(sealed)
+	return keyID, err
+}
+
+// BlindIndex returns a keyed hash of value for equality lookups on
+// encrypted data. kind separates domains so equal values in different
+// fields do not share an index.
+func (k *Keyring) BlindIndex(kind, value string) string {
+	h := hmac.New(sha256.New, k.IndexKey)
+	h.Write([]byte(kind))
+	h.Write([]byte{0})
+	h.Write([]byte(value))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+const (
+	nonceSize = 12
+	tagSize   = 16
+)
+
+func splitEnvelope(sealed []byte) (string, []byte, error) {
+	if len(sealed) < 2 || sealed[0] != envelopeVersion || len(sealed) < 2+int(sealed[1]) {
+		return "", nil, ErrInvalidEnvelope
+	}
+	end := 2 + int(sealed[1])
+	return string(sealed[2:end]), sealed[end:], nil
+}
+
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < nonceSize+tagSize {
+		return nil, ErrInvalidEnvelope
+	}
+	plaintext, err := gcm.Open(nil, sealed[:nonceSize], sealed[nonceSize:], aad)
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,91 @@
+package pii
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	keyring, err := NewKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte(`{"last_name":"Иванов"}`)
+	sealed, err := keyring.Seal(plaintext, []byte("patient-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		sealed  []byte
+		aad     string
+		wantErr bool
+	}{
+		{name: "round trip", sealed: sealed, aad: "patient-1"},
+		{name: "other record", sealed: sealed, aad: "patient-2", wantErr: true},
+		{name: "tampered", sealed: tampered, aad: "patient-1", wantErr: true},
+		{name: "truncated", sealed: sealed[:20], aad: "patient-1", wantErr: true},
+		{name: "empty", sealed: nil, aad: "patient-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keyring.Open(tt.sealed, []byte(tt.aad))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, plaintext) {
+				t.Errorf("Open() = %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "pii.json")
+	keyring, created, err := LoadOrCreateKeyring(path)
+	if err != nil || !created {
+		t.Fatalf("LoadOrCreateKeyring() created = %v, error = %v", created, err)
+	}
+	oldKey := keyring.Active
+	sealed, _ := keyring.Seal([]byte("secret"), nil)
+	index := keyring.BlindIndex("email", "a@example.com")
+
+	if _, err := keyring.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, created, err := LoadOrCreateKeyring(path)
+	if err != nil || created {
+		t.Fatalf("reload created = %v, error = %v", created, err)
+	}
+
+	if reloaded.Active == oldKey {
+		t.Fatal("Rotate() must activate a new key")
+	}
+	if got, err := reloaded.Open(sealed, nil); err != nil || string(got) != "secret" {
+		t.Errorf("values sealed under the retired key must stay readable: %q, %v", got, err)
+	}
+	resealed, _ := reloaded.Seal([]byte("secret"), nil)
+	if keyID, _ := KeyID(resealed); keyID != reloaded.Active {
+		t.Errorf("KeyID() = %q, want active %q", keyID, reloaded.Active)
+	}
+	if reloaded.BlindIndex("email", "a@example.com") != index {
+		t.Error("blind indexes must survive key rotation")
+	}
+	if reloaded.BlindIndex("phone", "a@example.com") == index {
+		t.Error("blind indexes of different kinds must differ")
+	}
+
+	reloaded.Remove(oldKey)
+	if _, err := reloaded.Open(sealed, nil); err == nil || errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("Open() after Remove() error = %v, want unknown key", err)
+	}
+}
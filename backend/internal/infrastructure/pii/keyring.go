@@ -0,0 +1,153 @@
+package pii
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const keySize = 32
+
+// Keyring is the local stand-in for a KMS. It holds the key-encryption keys
+// (KEKs) that wrap per-record data keys, one of which is active for new
+// records, and the separate key used for blind indexes. Retired KEKs are
+// kept so records sealed under them stay readable until re-encrypted.
+type Keyring struct {
+	Active   string            `json:"active"`
+	Keys     map[string][]byte `json:"keys"`
+	IndexKey []byte            `json:"index_key"`
+}
+
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyring Keyring
+	if err := json.Unmarshal(data, &keyring); err != nil {
+		return nil, fmt.Errorf("parse key file %s: %w", path, err)
+	}
+	if len(keyring.Keys[keyring.Active]) != keySize || len(keyring.IndexKey) != keySize {
+		return nil, fmt.Errorf("key file %s: active key and index key must be %d bytes", path, keySize)
+	}
+	return &keyring, nil
+}
+
+// LoadOrCreateKeyring loads the key file, generating a new one when it does
+// not exist yet. The second result reports whether the file was created.
+// Creation is exclusive: when the API and the worker start together, one
+// creates the file and the other loads it.
+func LoadOrCreateKeyring(path string) (*Keyring, bool, error) {
+	keyring, err := LoadKeyring(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		return keyring, false, err
+	}
+
+	keyring, err = NewKeyring()
+	if err != nil {
+		return nil, false, err
+	}
+	tmp, err := keyring.writeTemp(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.Remove(tmp)
+
+	if err := os.Link(tmp, path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			keyring, err = LoadKeyring(path)
+			return keyring, false, err
+		}
+		return nil, false, err
+	}
+	return keyring, true, nil
+}
+
+func NewKeyring() (*Keyring, error) {
+	indexKey, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	keyring := &Keyring{Keys: map[string][]byte{}, IndexKey: indexKey}
+	if _, err := keyring.Rotate(); err != nil {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// Rotate adds a new KEK and makes it active. The index key is unchanged, so
+// blind indexes stay valid.
+func (k *Keyring) Rotate() (string, error) {
+	key, err := randomKey()
+	if err != nil {
+		return "", err
+	}
+	id := time.Now().UTC().Format("20060102T150405.000000000")
+	k.Keys[id] = key
+	k.Active = id
+	return id, nil
+}
+
+// Retired returns the IDs of the inactive KEKs in creation order.
+func (k *Keyring) Retired() []string {
+	var ids []string
+	for id := range k.Keys {
+		if id != k.Active {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (k *Keyring) Remove(id string) {
+	if id != k.Active {
+		delete(k.Keys, id)
+	}
+}
+
+// Save replaces the key file atomically.
+func (k *Keyring) Save(path string) error {
+	tmp, err := k.writeTemp(path)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeTemp writes the keyring next to path with owner-only permissions.
+func (k *Keyring) writeTemp(path string) (string, error) {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if _, err := tmp.Write(data); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+func randomKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,61 @@
+package signing
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+// Bundle carries everything needed to verify a signed report without access
+// to the backend: the canonical payload, the detached signature and the
+// signer's public key.
+type Bundle struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+func NewBundle(key *entities.SigningKey, payload, signature []byte) *Bundle {
+	return &Bundle{
+		Algorithm: key.Algorithm,
+		KeyID:     key.ID,
+		PublicKey: base64.StdEncoding.EncodeToString(key.PublicKey),
+		Payload:   base64.StdEncoding.EncodeToString(payload),
+		Signature: base64.StdEncoding.EncodeToString(signature),
+	}
+}
+
+func (b *Bundle) Verify() (*Payload, error) {
+	if b.Algorithm != entities.SigningAlgorithmEd25519 {
+		return nil, fmt.Errorf("unsupported algorithm %q", b.Algorithm)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(b.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(b.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload encoding: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(b.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	if err := Verify(publicKey, payload, signature); err != nil {
+		return nil, err
+	}
+
+	parsed, err := ParsePayload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	if parsed.KeyID != b.KeyID {
+		return nil, fmt.Errorf("payload key %s does not match bundle key %s", parsed.KeyID, b.KeyID)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,93 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+const PayloadVersion = 1
+
+type ImageDigest struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	SHA256   string `json:"sha256"`
+}
+
+// Payload is the signed representation of a report. Its JSON encoding is the
+// canonical form: fields in declaration order, no insignificant whitespace,
+// timestamps in UTC RFC 3339 and images ordered by ID.
+type Payload struct {
+	Version         int           `json:"version"`
+	ReportID        string        `json:"report_id"`
+	ExaminationID   string        `json:"examination_id"`
+	Title           string        `json:"title"`
+	Content         string        `json:"content"`
+	Summary         string        `json:"summary"`
+	Diagnosis       string        `json:"diagnosis"`
+	Recommendations string        `json:"recommendations"`
+	GeneratedBy     string        `json:"generated_by"`
+	SignedBy        string        `json:"signed_by"`
+	SignedAt        string        `json:"signed_at"`
+	KeyID           string        `json:"key_id"`
+	Images          []ImageDigest `json:"images"`
+}
+
+func NewPayload(report *entities.Report, signedBy, keyID string, signedAt time.Time, images []ImageDigest) *Payload {
+	sorted := make([]ImageDigest, len(images))
+	copy(sorted, images)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	return &Payload{
+		Version:         PayloadVersion,
+		ReportID:        report.ID,
+		ExaminationID:   report.ExaminationID,
+		Title:           report.Title,
+		Content:         report.Content,
+		Summary:         report.Summary,
+		Diagnosis:       report.Diagnosis,
+		Recommendations: report.Recommendations,
+		GeneratedBy:     report.GeneratedBy,
+		SignedBy:        signedBy,
+		SignedAt:        signedAt.UTC().Format(time.RFC3339),
+		KeyID:           keyID,
+		Images:          sorted,
+	}
+}
+
+func (p *Payload) Canonical() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+func ParsePayload(data []byte) (*Payload, error) {
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return ReaderSHA256(file)
+}
+
+func ReaderSHA256(r io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,66 @@
+package signing
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/project-capillary/backend/internal/domain/entities"
+	"github.com/project-capillary/backend/internal/domain/repositories"
+)
+
+var ErrInvalidSignature = errors.New("signature does not match payload")
+
+type Signer struct {
+	keyRepo repositories.SigningKeyRepository
+}
+
+func NewSigner(keyRepo repositories.SigningKeyRepository) *Signer {
+	return &Signer{keyRepo: keyRepo}
+}
+
+// KeyForUser returns the user's active signing key, generating one on first use.
+func (s *Signer) KeyForUser(ctx context.Context, userID string) (*entities.SigningKey, error) {
+	key, err := s.keyRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		return key, nil
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	key = entities.NewSigningKey(uuid.New().String(), userID, publicKey, privateKey.Seed())
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *Signer) GetKey(ctx context.Context, keyID string) (*entities.SigningKey, error) {
+	return s.keyRepo.GetByID(ctx, keyID)
+}
+
+func Sign(key *entities.SigningKey, message []byte) ([]byte, error) {
+	if len(key.PrivateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key %s has invalid private key", key.ID)
+	}
+	return ed25519.Sign(ed25519.NewKeyFromSeed(key.PrivateKey), message), nil
+}
+
+func Verify(publicKey, message, signature []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key size %d", len(publicKey))
+	}
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/project-capillary/backend/internal/domain/entities"
+)
+
+func newTestKey(t *testing.T) *entities.SigningKey {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return entities.NewSigningKey("key-1", "doctor-1", publicKey, privateKey.Seed())
+}
+
+func newTestReport() *entities.Report {
+	return entities.NewReport("report-1", "exam-1", "Title", "Content", "Summary", "Diagnosis", "Recommendations", "user-1")
+}
+
+func TestPayload_CanonicalIsOrderIndependent(t *testing.T) {
+	report := newTestReport()
+	signedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	images := []ImageDigest{
+		{ID: "b", Filename: "b.jpg", SHA256: "bb"},
+		{ID: "a", Filename: "a.jpg", SHA256: "aa"},
+	}
+	reversed := []ImageDigest{images[1], images[0]}
+
+	first, err := NewPayload(report, "doctor-1", "key-1", signedAt, images).Canonical()
+	if err != nil {
+		t.Fatalf("Canonical() error = %v", err)
+	}
+	second, err := NewPayload(report, "doctor-1", "key-1", signedAt.UTC(), reversed).Canonical()
+	if err != nil {
+		t.Fatalf("Canonical() error = %v", err)
+	}
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("Canonical() differs:\n%s\n%s", first, second)
+	}
+	if images[0].ID != "b" {
+		t.Error("NewPayload() must not reorder the caller's slice")
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	key := newTestKey(t)
+	message := []byte("payload")
+
+	signature, err := Sign(key, message)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if err := Verify(key.PublicKey, message, signature); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := Verify(key.PublicKey, []byte("tampered"), signature); err != ErrInvalidSignature {
+		t.Errorf("Verify() with tampered message error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestBundle_Verify(t *testing.T) {
+	key := newTestKey(t)
+	payload, err := NewPayload(newTestReport(), "doctor-1", key.ID, time.Now(), nil).Canonical()
+	if err != nil {
+		t.Fatalf("Canonical() error = %v", err)
+	}
+	signature, err := Sign(key, payload)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	t.Run("valid bundle", func(t *testing.T) {
+		parsed, err := NewBundle(key, payload, signature).Verify()
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if parsed.ReportID != "report-1" {
+			t.Errorf("ReportID = %v, want %v", parsed.ReportID, "report-1")
+		}
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		bundle := NewBundle(key, payload, signature)
+		tampered := bytes.Replace(payload, []byte("Diagnosis"), []byte("Diagnosys"), 1)
+		bundle.Payload = base64.StdEncoding.EncodeToString(tampered)
+		if _, err := bundle.Verify(); err != ErrInvalidSignature {
+			t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+		}
+	})
+
+	t.Run("mismatched key id", func(t *testing.T) {
+		bundle := NewBundle(key, payload, signature)
+		bundle.KeyID = "other-key"
+		if _, err := bundle.Verify(); err == nil {
+			t.Error("Verify() should fail when the bundle key differs from the payload key")
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// Package storage keeps photos and other binary objects in a BlobStore,
+// backed by a local directory or an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/project-capillary/backend/internal/infrastructure/config"
+)
+
+var (
+	ErrBlobNotFound = errors.New("blob not found")
+	ErrInvalidKey   = errors.New("invalid blob key")
+)
+
+type BlobInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// BlobStore stores objects under slash-separated relative keys. Delete of a
+// missing key succeeds; the other single-key methods return
+// ErrBlobNotFound.
+type BlobStore interface {
+	// Put stores r under key, replacing any existing object. size is the
+	// length of r, or -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Stream opens key for reading; the caller closes the reader. For the
+	// local store it also implements io.Seeker.
+	Stream(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	Stat(ctx context.Context, key string) (*BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose keys start with prefix, ordered by key.
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+}
+
+// NewBlobStore opens the backend selected by STORAGE_BACKEND.
+func NewBlobStore(cfg config.StorageConfig) (BlobStore, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocalStore(cfg.PhotoPath), nil
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// PhotoKey is the key a photo is stored under: its file name without any
+// directory, which also covers file paths recorded by older versions.
+func PhotoKey(filename string) string {
+	return path.Base(strings.ReplaceAll(filename, "\\", "/"))
+}
+
+func validateKey(key string) error {
+	if key == "" || key == "." || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") ||
+		path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+func contentTypeByKey(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
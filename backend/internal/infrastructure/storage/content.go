@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+var ErrChecksumMismatch = errors.New("blob checksum mismatch")
+
+// ContentKey is the key content with the given SHA-256 is stored under, so
+// identical uploads share one object and the key doubles as a checksum.
+func ContentKey(sum, ext string) string {
+	return sum + ext
+}
+
+// HashFromKey returns the SHA-256 a content-addressed key names. Photos
+// stored before content addressing have timestamp keys and no hash.
+func HashFromKey(key string) (string, bool) {
+	base := path.Base(key)
+	sum := strings.TrimSuffix(base, path.Ext(base))
+	if len(sum) != sha256.Size*2 || strings.ToLower(sum) != sum {
+		return "", false
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return "", false
+	}
+	return sum, true
+}
+
+// RenditionPrefix holds downscaled copies of photos. They are derived data:
+// fsck ignores them and they are regenerated when missing.
+const RenditionPrefix = "renditions/"
+
+// RenditionKey is the key the named rendition of the photo under photoKey
+// is cached under. Content-addressed photos share their renditions too.
+func RenditionKey(name, photoKey string) string {
+	base := path.Base(photoKey)
+	return RenditionPrefix + name + "/" + strings.TrimSuffix(base, path.Ext(base)) + ".jpg"
+}
+
+// Checksum is the hex SHA-256 content keys and image records use.
+func Checksum(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+// PutContent stores data under its content key. created is false when an
+// identical object was already stored.
+func PutContent(ctx context.Context, store BlobStore, data []byte, ext, contentType string) (key, sum string, created bool, err error) {
+	sum = Checksum(data)
+	key = ContentKey(sum, ext)
+
+	_, err = store.Stat(ctx, key)
+	if err == nil {
+		return key, sum, false, nil
+	}
+	if !errors.Is(err, ErrBlobNotFound) {
+		return "", "", false, err
+	}
+	if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return "", "", false, err
+	}
+	return key, sum, true, nil
+}
+
+// ReadVerified reads key and checks it against want, or against the hash in
+// the key when want is empty. Blobs with neither are returned unchecked.
+func ReadVerified(ctx context.Context, store BlobStore, key, want string) ([]byte, *BlobInfo, error) {
+	body, info, err := store.Stream(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verify(key, data, want); err != nil {
+		return nil, nil, err
+	}
+	return data, info, nil
+}
+
+func verify(key string, data []byte, want string) error {
+	if want == "" {
+		want, _ = HashFromKey(key)
+	}
+	if want == "" {
+		return nil
+	}
+	if got := Checksum(data); got != want {
+		return fmt.Errorf("%w: %s has sha256 %s, expected %s", ErrChecksumMismatch, key, got, want)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashFromKey(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{sum + ".jpg", sum, true},
+		{sum, sum, true},
+		{"/app/storage/photos/" + sum + ".png", sum, true},
+		{strings.ToUpper(sum) + ".jpg", "", false},
+		{strings.Repeat("zz", 32) + ".jpg", "", false},
+		{sum[:62] + ".jpg", "", false},
+		{"photo_20240101_120000.jpg", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := HashFromKey(tt.key)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("HashFromKey(%q) = %q, %v, want %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRenditionKey(t *testing.T) {
+	sum := strings.Repeat("a", 64)
+	if got, want := RenditionKey("thumbnail", sum+".tif"), "renditions/thumbnail/"+sum+".jpg"; got != want {
+		t.Errorf("RenditionKey = %q, want %q", got, want)
+	}
+	if got, want := RenditionKey("preview", "photo_20240101_120000.png"), "renditions/preview/photo_20240101_120000.jpg"; got != want {
+		t.Errorf("RenditionKey = %q, want %q", got, want)
+	}
+}
+
+func TestPutContentDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir())
+
+	key, sum, created, err := PutContent(ctx, store, []byte("capture"), ".jpg", "image/jpeg")
+	if err != nil || !created {
+		t.Fatalf("PutContent = %q, %v, %v", key, created, err)
+	}
+	if key != sum+".jpg" {
+		t.Errorf("key = %q, want %q", key, sum+".jpg")
+	}
+
+	again, _, created, err := PutContent(ctx, store, []byte("capture"), ".jpg", "image/jpeg")
+	if err != nil || created || again != key {
+		t.Errorf("second PutContent = %q, %v, %v, want %q, false", again, created, err, key)
+	}
+	other, _, created, err := PutContent(ctx, store, []byte("another capture"), ".jpg", "image/jpeg")
+	if err != nil || !created || other == key {
+		t.Errorf("PutContent(other) = %q, %v, %v", other, created, err)
+	}
+}
+
+func TestReadVerified(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewLocalStore(root)
+
+	key, sum, _, err := PutContent(ctx, store, []byte("capture"), ".jpg", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _, err := ReadVerified(ctx, store, key, ""); err != nil || string(data) != "capture" {
+		t.Fatalf("ReadVerified = %q, %v", data, err)
+	}
+	if _, _, err := ReadVerified(ctx, store, key, strings.Repeat("0", 64)); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("ReadVerified(wrong want) error = %v, want ErrChecksumMismatch", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, key), []byte("bit rot"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ReadVerified(ctx, store, key, sum); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("ReadVerified(corrupt) error = %v, want ErrChecksumMismatch", err)
+	}
+
+	if err := store.Put(ctx, "photo_20240101_120000.jpg", strings.NewReader("legacy"), -1, ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, _, err := ReadVerified(ctx, store, "photo_20240101_120000.jpg", ""); err != nil || string(data) != "legacy" {
+		t.Errorf("ReadVerified(legacy) = %q, %v", data, err)
+	}
+}
+
+func TestFsck(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewLocalStore(root)
+
+	good, goodSum, _, _ := PutContent(ctx, store, []byte("good"), ".jpg", "")
+	corrupt, corruptSum, _, _ := PutContent(ctx, store, []byte("corrupt"), ".jpg", "")
+	orphan, _, _, _ := PutContent(ctx, store, []byte("orphan"), ".jpg", "")
+	if err := os.WriteFile(filepath.Join(root, corrupt), []byte("flipped"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, RenditionKey("thumbnail", good), strings.NewReader("small"), 5, "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	refs := map[string]string{
+		good:                             goodSum,
+		corrupt:                          corruptSum,
+		"photo_missing.jpg":              "",
+		strings.Repeat("c", 64) + ".jpg": "",
+	}
+
+	report, err := Fsck(ctx, store, refs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 3 || len(report.Corrupt) != 0 {
+		t.Errorf("Fsck without verify = %+v", report)
+	}
+	if len(report.Orphaned) != 1 || report.Orphaned[0].Key != orphan {
+		t.Errorf("Orphaned = %+v, want %s", report.Orphaned, orphan)
+	}
+	wantMissing := []string{strings.Repeat("c", 64) + ".jpg", "photo_missing.jpg"}
+	if strings.Join(report.Missing, ",") != strings.Join(wantMissing, ",") {
+		t.Errorf("Missing = %v, want %v", report.Missing, wantMissing)
+	}
+
+	report, err = Fsck(ctx, store, refs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Corrupt) != 1 || report.Corrupt[0] != corrupt {
+		t.Errorf("Corrupt = %v, want %s", report.Corrupt, corrupt)
+	}
+	if report.Clean() {
+		t.Error("Clean() = true for a report with problems")
+	}
+}
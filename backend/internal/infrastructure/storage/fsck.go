@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// FsckReport lists the differences between a store and the keys the
+// database references.
+type FsckReport struct {
+	Checked int
+	// Missing are referenced keys with no blob.
+	Missing []string
+	// Orphaned are blobs nothing references.
+	Orphaned []BlobInfo
+	// Corrupt are blobs whose content does not match their checksum; only
+	// filled when verifying.
+	Corrupt []string
+}
+
+func (r *FsckReport) Clean() bool {
+	return len(r.Missing) == 0 && len(r.Orphaned) == 0 && len(r.Corrupt) == 0
+}
+
+// Fsck compares the blobs in store with refs, a map from referenced key to
+// its expected SHA-256 (empty when unknown). With verify set every blob is
+// read and checked against its expected or key-derived hash.
+func Fsck(ctx context.Context, store BlobStore, refs map[string]string, verify bool) (*FsckReport, error) {
+	blobs, err := store.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FsckReport{}
+	stored := make(map[string]bool, len(blobs))
+	for _, blob := range blobs {
+		if strings.HasPrefix(blob.Key, RenditionPrefix) {
+			continue
+		}
+		report.Checked++
+		stored[blob.Key] = true
+		want, referenced := refs[blob.Key]
+		if !referenced {
+			report.Orphaned = append(report.Orphaned, blob)
+		}
+		if !verify {
+			continue
+		}
+		_, _, err := ReadVerified(ctx, store, blob.Key, want)
+		if errors.Is(err, ErrChecksumMismatch) {
+			report.Corrupt = append(report.Corrupt, blob.Key)
+		} else if err != nil && !errors.Is(err, ErrBlobNotFound) {
+			return nil, err
+		}
+	}
+	for key := range refs {
+		if !stored[key] {
+			report.Missing = append(report.Missing, key)
+		}
+	}
+	sort.Strings(report.Missing)
+	return report, nil
+}
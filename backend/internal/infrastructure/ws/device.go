@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// DeviceHello is what a device sends after connecting to say which device
+// it is. Name and Label are optional.
+type DeviceHello struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name,omitempty"`
+	Label    string `json:"label,omitempty"`
+}
+
+// connectedDevice identifies the device behind a connection; device IDs are
+// unique per organization.
+type connectedDevice struct {
+	organizationID string
+	deviceID       string
+}
+
+// SetDeviceConnectCallback registers the hook run when a connection
+// identifies its device, e.g. to mark it online, with the connection's
+// organization. An error is reported to the client and the connection stays
+// unidentified.
+func (dm *DeviceManager) SetDeviceConnectCallback(callback func(ctx context.Context, organizationID string, hello DeviceHello) error) {
+	dm.onDeviceConnect = callback
+}
+
+// SetDeviceDisconnectCallback registers the hook run when the last
+// connection of a device closes, with the organization and device ID the
+// connection registered.
+func (dm *DeviceManager) SetDeviceDisconnectCallback(callback func(ctx context.Context, organizationID, deviceID string) error) {
+	dm.onDeviceDisconnect = callback
+}
+
+// DeviceID returns the device a connection identified itself as, if any.
+func (dm *DeviceManager) DeviceID(conn *websocket.Conn) string {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+	return dm.devices[conn].deviceID
+}
+
+func (dm *DeviceManager) hello(ctx context.Context, conn *websocket.Conn, data map[string]interface{}) {
+	deviceID, _ := data["device_id"].(string)
+	hello := DeviceHello{DeviceID: strings.TrimSpace(deviceID)}
+	if hello.DeviceID == "" {
+		dm.SendError(conn, "Missing device_id")
+		return
+	}
+	hello.Name, _ = data["name"].(string)
+	hello.Label, _ = data["label"].(string)
+
+	dm.mutex.Lock()
+	device := connectedDevice{organizationID: dm.clients[conn], deviceID: hello.DeviceID}
+	dm.mutex.Unlock()
+	if dm.onDeviceConnect != nil {
+		if err := dm.onDeviceConnect(ctx, device.organizationID, hello); err != nil {
+			dm.SendError(conn, fmt.Sprintf("Failed to register device: %v", err))
+			return
+		}
+	}
+
+	dm.mutex.Lock()
+	previous, identified := dm.devices[conn]
+	dm.devices[conn] = device
+	dm.mutex.Unlock()
+	if identified && previous != device {
+		dm.deviceGone(ctx, previous)
+	}
+	dm.SendResponse(conn, "device_registered", hello)
+}
+
+// deviceGone runs the disconnect hook unless another connection still
+// belongs to the device.
+func (dm *DeviceManager) deviceGone(ctx context.Context, device connectedDevice) {
+	dm.mutex.Lock()
+	for _, other := range dm.devices {
+		if other == device {
+			dm.mutex.Unlock()
+			return
+		}
+	}
+	dm.mutex.Unlock()
+
+	if dm.onDeviceDisconnect != nil {
+		if err := dm.onDeviceDisconnect(ctx, device.organizationID, device.deviceID); err != nil {
+			log.Printf("Failed to mark device %s offline: %v", device.deviceID, err)
+		}
+	}
+}
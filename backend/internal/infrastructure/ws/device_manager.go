@@ -1,22 +1,20 @@
 package ws
 
 import (
-	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"image"
-	_ "image/jpeg"
-	_ "image/png"
-	"io"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/project-capillary/backend/internal/application/dto"
+	"github.com/project-capillary/backend/internal/application/usecases"
+	"github.com/project-capillary/backend/internal/infrastructure/auth"
 )
 
 type Message struct {
@@ -24,11 +22,16 @@ type Message struct {
 	Data interface{} `json:"data"`
 }
 
+// PhotoInfo is a photo in the inbox of captures not yet attached to an
+// examination.
 type PhotoInfo struct {
-	Filename  string    `json:"filename"`
-	Timestamp time.Time `json:"timestamp"`
-	Path      string    `json:"path"`
-	Size      int64     `json:"size"`
+	ID         string    `json:"id"`
+	Filename   string    `json:"filename"`
+	Timestamp  time.Time `json:"timestamp"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	CapturedBy string    `json:"captured_by,omitempty"`
+	DeviceID   string    `json:"device_id,omitempty"`
 }
 
 type DeviceControlData struct {
@@ -37,23 +40,42 @@ type DeviceControlData struct {
 	Delta float64 `json:"delta"`
 }
 
+// TenantScoper binds ctx to an organization for the database; release
+// undoes it.
+type TenantScoper func(ctx context.Context, organizationID string) (scoped context.Context, release func(), err error)
+
 type DeviceManager struct {
-	clients          map[*websocket.Conn]bool
-	broadcast        chan []byte
-	mutex            sync.Mutex
-	streaming        bool
-	photoStoragePath string
-	upgrader         websocket.Upgrader
-	onPhotoSaved     func(filename string)
-	onControlChange  func(controlData DeviceControlData)
+	// clients maps every connection to the organization it authenticated
+	// as; messages are only broadcast within one organization.
+	clients            map[*websocket.Conn]string
+	broadcast          chan []byte
+	mutex              sync.Mutex
+	streaming          bool
+	inbox              *usecases.PhotoInboxUseCase
+	urls               *auth.URLSigner
+	scope              TenantScoper
+	upgrader           websocket.Upgrader
+	sessions           map[*websocket.Conn]*CaptureSession
+	devices            map[*websocket.Conn]connectedDevice
+	onPhotoSaved       func(ctx context.Context, filename string, session *CaptureSession) error
+	onSessionStart     func(ctx context.Context, session *CaptureSession) error
+	onSessionEnd       func(ctx context.Context, session *CaptureSession, analyze bool) error
+	onDeviceConnect    func(ctx context.Context, organizationID string, hello DeviceHello) error
+	onDeviceDisconnect func(ctx context.Context, organizationID, deviceID string) error
+	onControlChange    func(controlData DeviceControlData)
+	onAudit            func(ctx context.Context, action, filename string)
 }
 
-func NewDeviceManager(photoStoragePath string) *DeviceManager {
+func NewDeviceManager(inbox *usecases.PhotoInboxUseCase, urls *auth.URLSigner, scope TenantScoper) *DeviceManager {
 	return &DeviceManager{
-		clients:          make(map[*websocket.Conn]bool),
-		broadcast:        make(chan []byte, 256),
-		streaming:        false,
-		photoStoragePath: photoStoragePath,
+		clients:   make(map[*websocket.Conn]string),
+		sessions:  make(map[*websocket.Conn]*CaptureSession),
+		devices:   make(map[*websocket.Conn]connectedDevice),
+		broadcast: make(chan []byte, 256),
+		streaming: false,
+		inbox:     inbox,
+		urls:      urls,
+		scope:     scope,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
@@ -64,24 +86,42 @@ func NewDeviceManager(photoStoragePath string) *DeviceManager {
 	}
 }
 
-func (dm *DeviceManager) SetPhotoSavedCallback(callback func(filename string)) {
+// SetPhotoSavedCallback registers the hook run for every saved photo with
+// the connection's capture session, or nil outside one. An error is
+// reported to the client; the photo stays in the inbox.
+func (dm *DeviceManager) SetPhotoSavedCallback(callback func(ctx context.Context, filename string, session *CaptureSession) error) {
 	dm.onPhotoSaved = callback
 }
 
+// SetAuditCallback registers a hook called for every photo operation with
+// the context of the connection's upgrade request.
+func (dm *DeviceManager) SetAuditCallback(callback func(ctx context.Context, action, filename string)) {
+	dm.onAudit = callback
+}
+
+func (dm *DeviceManager) audit(ctx context.Context, action, filename string) {
+	if dm.onAudit != nil {
+		dm.onAudit(ctx, action, filename)
+	}
+}
+
 func (dm *DeviceManager) SetControlChangeCallback(callback func(controlData DeviceControlData)) {
 	dm.onControlChange = callback
 }
 
-func (dm *DeviceManager) AddClient(conn *websocket.Conn) {
+func (dm *DeviceManager) AddClient(conn *websocket.Conn, organizationID string) {
 	dm.mutex.Lock()
 	defer dm.mutex.Unlock()
-	dm.clients[conn] = true
+	dm.clients[conn] = organizationID
 	log.Printf("Client connected. Total clients: %d", len(dm.clients))
 }
 
 func (dm *DeviceManager) RemoveClient(conn *websocket.Conn) {
 	dm.mutex.Lock()
 	defer dm.mutex.Unlock()
+	// A failed broadcast may already have dropped the client.
+	delete(dm.sessions, conn)
+	delete(dm.devices, conn)
 	if _, ok := dm.clients[conn]; ok {
 		delete(dm.clients, conn)
 		conn.Close()
@@ -89,11 +129,15 @@ func (dm *DeviceManager) RemoveClient(conn *websocket.Conn) {
 	}
 }
 
-func (dm *DeviceManager) BroadcastMessage(message []byte) {
+// BroadcastMessage sends message to every client of an organization.
+func (dm *DeviceManager) BroadcastMessage(organizationID string, message []byte) {
 	dm.mutex.Lock()
 	defer dm.mutex.Unlock()
 
-	for client := range dm.clients {
+	for client, clientOrganizationID := range dm.clients {
+		if clientOrganizationID != organizationID {
+			continue
+		}
 		err := client.WriteMessage(websocket.TextMessage, message)
 		if err != nil {
 			log.Printf("Error sending to client: %v", err)
@@ -103,19 +147,47 @@ func (dm *DeviceManager) BroadcastMessage(message []byte) {
 	}
 }
 
+// HandleWebSocket serves a connection authenticated by the upgrade
+// request. Everything it does is confined to the caller's organization.
 func (dm *DeviceManager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	organizationID := auth.OrganizationID(r.Context())
+	if organizationID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
 	conn, err := dm.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
 	}
 
-	dm.AddClient(conn)
-	defer dm.RemoveClient(conn)
+	dm.AddClient(conn, organizationID)
+	defer func() {
+		dm.mutex.Lock()
+		device, identified := dm.devices[conn]
+		dm.mutex.Unlock()
+		dm.RemoveClient(conn)
+		if identified {
+			// The peer is gone; keep the claims but not the cancellation.
+			err := dm.withTenant(context.WithoutCancel(r.Context()), organizationID, func(ctx context.Context) {
+				dm.deviceGone(ctx, device)
+			})
+			if err != nil {
+				log.Printf("Failed to mark device %s offline: %v", device.deviceID, err)
+			}
+		}
+	}()
 
-	photos, err := dm.GetPhotoList()
-	if err == nil {
-		dm.SendResponse(conn, "photo_list", photos)
+	err = dm.withTenant(r.Context(), organizationID, func(ctx context.Context) {
+		photos, err := dm.GetPhotoList(ctx)
+		if err == nil {
+			dm.SendResponse(conn, "photo_list", photos)
+			dm.audit(ctx, "list", "")
+		}
+	})
+	if err != nil {
+		dm.SendError(conn, "Database unavailable")
 	}
 
 	for {
@@ -133,12 +205,39 @@ func (dm *DeviceManager) HandleWebSocket(w http.ResponseWriter, r *http.Request)
 			continue
 		}
 
-		dm.HandleMessage(conn, msg)
+		err = dm.withTenant(r.Context(), organizationID, func(ctx context.Context) {
+			dm.HandleMessage(ctx, conn, msg)
+		})
+		if err != nil {
+			dm.SendError(conn, "Database unavailable")
+		}
 	}
 }
 
-func (dm *DeviceManager) HandleMessage(conn *websocket.Conn, msg Message) {
+// withTenant runs fn with ctx bound to the organization for the database.
+// Connections live too long to hold a scoped database connection, so every
+// message is scoped on its own.
+func (dm *DeviceManager) withTenant(ctx context.Context, organizationID string, fn func(ctx context.Context)) error {
+	scoped, release, err := dm.scope(ctx, organizationID)
+	if err != nil {
+		log.Printf("Failed to scope WebSocket message to organization %s: %v", organizationID, err)
+		return err
+	}
+	defer release()
+	fn(scoped)
+	return nil
+}
+
+func (dm *DeviceManager) HandleMessage(ctx context.Context, conn *websocket.Conn, msg Message) {
 	switch msg.Type {
+	case "hello":
+		dataMap, ok := msg.Data.(map[string]interface{})
+		if !ok {
+			dm.SendError(conn, "Invalid hello data")
+			return
+		}
+		dm.hello(ctx, conn, dataMap)
+
 	case "start_stream":
 		dm.SendResponse(conn, "stream_started", "Use browser camera access")
 
@@ -159,27 +258,54 @@ func (dm *DeviceManager) HandleMessage(conn *websocket.Conn, msg Message) {
 			return
 		}
 
-		filename, err := dm.SavePhotoFromBase64(imageData)
+		// Only the device the connection registered with hello is
+		// trusted as the source of a photo.
+		photo, err := dm.SavePhotoFromBase64(ctx, imageData, dm.DeviceID(conn))
 		if err != nil {
 			dm.SendError(conn, fmt.Sprintf("Failed to save photo: %v", err))
-		} else {
-			dm.SendResponse(conn, "photo_saved", map[string]string{
-				"filename": filename,
-				"url":      fmt.Sprintf("/api/photos/%s", filename),
-			})
-			dm.BroadcastNewPhoto(filename)
+			return
+		}
+		filename := photo.Filename
+		dm.audit(ctx, "create", filename)
 
-			if dm.onPhotoSaved != nil {
-				dm.onPhotoSaved(filename)
+		response := map[string]string{
+			"filename": filename,
+			"url":      photo.URL,
+		}
+		session := dm.session(conn)
+		if dm.onPhotoSaved != nil {
+			if err := dm.onPhotoSaved(ctx, filename, session); err != nil {
+				dm.SendError(conn, fmt.Sprintf("Photo %s saved to the inbox but not attached: %v", filename, err))
+				session = nil
 			}
 		}
+		if session != nil {
+			dm.countSessionPhoto(session)
+			response["examination_id"] = session.ExaminationID
+			response["position"] = session.Position
+		}
+		dm.SendResponse(conn, "photo_saved", response)
+		dm.BroadcastNewPhoto(auth.OrganizationID(ctx), filename)
+
+	case "start_session":
+		dataMap, ok := msg.Data.(map[string]interface{})
+		if !ok {
+			dm.SendError(conn, "Invalid session data")
+			return
+		}
+		dm.startSession(ctx, conn, dataMap)
+
+	case "end_session":
+		dataMap, _ := msg.Data.(map[string]interface{})
+		dm.endSession(ctx, conn, dataMap)
 
 	case "get_photos":
-		photos, err := dm.GetPhotoList()
+		photos, err := dm.GetPhotoList(ctx)
 		if err != nil {
 			dm.SendError(conn, fmt.Sprintf("Failed to get photos: %v", err))
 		} else {
 			dm.SendResponse(conn, "photo_list", photos)
+			dm.audit(ctx, "list", "")
 		}
 
 	case "delete_photo":
@@ -195,11 +321,12 @@ func (dm *DeviceManager) HandleMessage(conn *websocket.Conn, msg Message) {
 			return
 		}
 
-		err := dm.DeletePhoto(filename)
+		err := dm.DeletePhoto(ctx, filename)
 		if err != nil {
 			dm.SendError(conn, fmt.Sprintf("Failed to delete photo: %v", err))
 		} else {
 			dm.SendResponse(conn, "photo_deleted", map[string]string{"filename": filename})
+			dm.audit(ctx, "delete", filename)
 		}
 
 	case "control_change":
@@ -213,7 +340,7 @@ func (dm *DeviceManager) HandleMessage(conn *websocket.Conn, msg Message) {
 				controlData.Delta = delta.(float64)
 			}
 
-			dm.BroadcastControlChange(controlData)
+			dm.BroadcastControlChange(auth.OrganizationID(ctx), controlData)
 
 			if dm.onControlChange != nil {
 				dm.onControlChange(controlData)
@@ -243,11 +370,9 @@ func (dm *DeviceManager) StopStreaming() {
 	log.Println("Streaming stopped")
 }
 
-func (dm *DeviceManager) SavePhotoFromBase64(base64Data string) (string, error) {
-	if err := os.MkdirAll(dm.photoStoragePath, 0755); err != nil {
-		return "", err
-	}
-
+// SavePhotoFromBase64 stores a photo sent by a device and adds it to the
+// inbox of the user the connection belongs to.
+func (dm *DeviceManager) SavePhotoFromBase64(ctx context.Context, base64Data, deviceID string) (*dto.PhotoCaptureResponse, error) {
 	if len(base64Data) > 23 && base64Data[:23] == "data:image/jpeg;base64," {
 		base64Data = base64Data[23:]
 	} else if len(base64Data) > 22 && base64Data[:22] == "data:image/png;base64," {
@@ -256,111 +381,73 @@ func (dm *DeviceManager) SavePhotoFromBase64(base64Data string) (string, error)
 
 	imageBytes, err := base64.StdEncoding.DecodeString(base64Data)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode base64: %w", err)
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
 	}
 
-	_, _, err = image.Decode(io.NopCloser(bytes.NewReader(imageBytes)))
+	photo, err := dm.inbox.Capture(ctx, imageBytes, deviceID)
 	if err != nil {
-		return "", fmt.Errorf("invalid image data: %w", err)
-	}
-
-	filename := fmt.Sprintf("photo_%s.jpg", time.Now().Format("20060102_150405"))
-	filePath := filepath.Join(dm.photoStoragePath, filename)
-
-	err = os.WriteFile(filePath, imageBytes, 0644)
-	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	log.Printf("Photo saved: %s (size: %d bytes)", filename, len(imageBytes))
-	return filename, nil
+	log.Printf("Photo captured: %s (size: %d bytes)", photo.Filename, len(imageBytes))
+	return photo, nil
 }
 
-func (dm *DeviceManager) DeletePhoto(filename string) error {
-	filename = filepath.Base(filename)
-	filePath := filepath.Join(dm.photoStoragePath, filename)
-
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("file not found")
-	}
-
-	err := os.Remove(filePath)
-	if err != nil {
+// DeletePhoto removes a photo from the inbox; photos attached to an
+// examination are kept.
+func (dm *DeviceManager) DeletePhoto(ctx context.Context, filename string) error {
+	if err := dm.inbox.DeletePhoto(ctx, filename); err != nil {
 		return err
 	}
-
 	log.Printf("Photo deleted: %s", filename)
 	return nil
 }
 
-func (dm *DeviceManager) BroadcastNewPhoto(filename string) {
+func (dm *DeviceManager) BroadcastNewPhoto(organizationID, filename string) {
 	msg := Message{
 		Type: "new_photo",
 		Data: map[string]string{
 			"filename": filename,
-			"url":      fmt.Sprintf("/api/photos/%s", filename),
+			"url":      dm.photoURL(filename),
 		},
 	}
 	jsonData, _ := json.Marshal(msg)
-	dm.BroadcastMessage(jsonData)
+	dm.BroadcastMessage(organizationID, jsonData)
 }
 
-func (dm *DeviceManager) BroadcastControlChange(data DeviceControlData) {
+func (dm *DeviceManager) BroadcastControlChange(organizationID string, data DeviceControlData) {
 	msg := Message{
 		Type: "control_change",
 		Data: data,
 	}
 	jsonData, _ := json.Marshal(msg)
-	dm.BroadcastMessage(jsonData)
+	dm.BroadcastMessage(organizationID, jsonData)
 	log.Printf("Broadcasting control change: %v", data)
 }
 
-func (dm *DeviceManager) GetPhotoList() ([]PhotoInfo, error) {
-	var photos []PhotoInfo
-
-	if _, err := os.Stat(dm.photoStoragePath); os.IsNotExist(err) {
-		return photos, nil
-	}
-
-	files, err := os.ReadDir(dm.photoStoragePath)
+// GetPhotoList lists the inbox: captures not yet attached to an
+// examination.
+func (dm *DeviceManager) GetPhotoList(ctx context.Context) ([]PhotoInfo, error) {
+	captures, err := dm.inbox.Inbox(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, file := range files {
-		if !file.IsDir() {
-			info, err := file.Info()
-			if err != nil {
-				continue
-			}
-
-			ext := filepath.Ext(file.Name())
-			if ext == ".jpg" || ext == ".jpeg" || ext == ".png" {
-				photos = append(photos, PhotoInfo{
-					Filename:  file.Name(),
-					Timestamp: info.ModTime(),
-					Path:      fmt.Sprintf("/api/photos/%s", file.Name()),
-					Size:      info.Size(),
-				})
-			}
-		}
+	photos := make([]PhotoInfo, 0, len(captures))
+	for _, capture := range captures {
+		photos = append(photos, PhotoInfo{
+			ID:         capture.ID,
+			Filename:   capture.Filename,
+			Timestamp:  capture.CapturedAt,
+			Path:       capture.URL,
+			Size:       capture.FileSize,
+			CapturedBy: capture.CapturedBy,
+			DeviceID:   capture.DeviceID,
+		})
 	}
-
 	return photos, nil
 }
 
-func (dm *DeviceManager) ServePhoto(w http.ResponseWriter, r *http.Request, filename string) {
-	if filename == "" {
-		http.Error(w, "Filename required", http.StatusBadRequest)
-		return
-	}
-
-	filepath := filepath.Join(dm.photoStoragePath, filepath.Base(filename))
-
-	if _, err := os.Stat(filepath); os.IsNotExist(err) {
-		http.Error(w, "File not found", http.StatusNotFound)
-		return
-	}
-
-	http.ServeFile(w, r, filepath)
+// photoURL is a signed link to a stored photo, usable from <img> tags.
+func (dm *DeviceManager) photoURL(filename string) string {
+	return dm.urls.Sign("/api/photos/" + url.PathEscape(filename))
 }
@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// CaptureSession binds a connection's captures to an examination: while it
+// is open every saved photo is attached to the examination at Position
+// instead of waiting in the inbox.
+type CaptureSession struct {
+	ExaminationID string    `json:"examination_id"`
+	Position      string    `json:"position,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	Photos        int       `json:"photos"`
+}
+
+// SetSessionStartCallback registers the check run before a session opens,
+// e.g. that the examination exists and still accepts images.
+func (dm *DeviceManager) SetSessionStartCallback(callback func(ctx context.Context, session *CaptureSession) error) {
+	dm.onSessionStart = callback
+}
+
+// SetSessionEndCallback registers the hook run when a session ends; analyze
+// is set when the client asked to start the analysis.
+func (dm *DeviceManager) SetSessionEndCallback(callback func(ctx context.Context, session *CaptureSession, analyze bool) error) {
+	dm.onSessionEnd = callback
+}
+
+func (dm *DeviceManager) session(conn *websocket.Conn) *CaptureSession {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+	return dm.sessions[conn]
+}
+
+func (dm *DeviceManager) countSessionPhoto(session *CaptureSession) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+	session.Photos++
+}
+
+// startSession opens a session, replacing any open one, so a technician can
+// move on to the next position with another start_session.
+func (dm *DeviceManager) startSession(ctx context.Context, conn *websocket.Conn, data map[string]interface{}) {
+	examinationID, _ := data["examination_id"].(string)
+	if examinationID == "" {
+		dm.SendError(conn, "Missing examination_id")
+		return
+	}
+	position, _ := data["position"].(string)
+
+	session := &CaptureSession{ExaminationID: examinationID, Position: position, StartedAt: time.Now()}
+	if dm.onSessionStart != nil {
+		if err := dm.onSessionStart(ctx, session); err != nil {
+			dm.SendError(conn, fmt.Sprintf("Failed to start session: %v", err))
+			return
+		}
+	}
+
+	dm.mutex.Lock()
+	dm.sessions[conn] = session
+	dm.mutex.Unlock()
+	dm.SendResponse(conn, "session_started", session)
+}
+
+func (dm *DeviceManager) endSession(ctx context.Context, conn *websocket.Conn, data map[string]interface{}) {
+	dm.mutex.Lock()
+	session := dm.sessions[conn]
+	delete(dm.sessions, conn)
+	dm.mutex.Unlock()
+	if session == nil {
+		dm.SendError(conn, "No capture session in progress")
+		return
+	}
+
+	analyze, _ := data["analyze"].(bool)
+	if dm.onSessionEnd != nil {
+		if err := dm.onSessionEnd(ctx, session, analyze); err != nil {
+			dm.SendError(conn, fmt.Sprintf("Session ended, but analysis could not start: %v", err))
+			return
+		}
+	}
+	dm.SendResponse(conn, "session_ended", map[string]interface{}{
+		"examination_id":   session.ExaminationID,
+		"photos":           session.Photos,
+		"analysis_started": analyze && dm.onSessionEnd != nil,
+	})
+}
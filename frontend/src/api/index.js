@@ -15,6 +15,19 @@ api.interceptors.request.use((config) => {
   return config
 })
 
+api.interceptors.response.use(
+  (response) => response,
+  (error) => {
+    if (error.response?.status === 401 && localStorage.getItem('token')) {
+      localStorage.removeItem('token')
+      localStorage.removeItem('user')
+      localStorage.removeItem('organization')
+      window.location.href = '/login'
+    }
+    return Promise.reject(error)
+  },
+)
+
 export const authAPI = {
   login: (credentials) => api.post('/auth/login', credentials),
   register: (userData) => api.post('/auth/register', userData),
@@ -24,25 +37,53 @@ export const patientsAPI = {
   list: (params) => api.get('/patients', { params }),
   get: (id) => api.get(`/patients/${id}`),
   create: (data) => api.post('/patients', data),
-  update: (id, data) => api.put(`/patients/${id}`, data),
+  update: (id, data, version) => api.put(`/patients/${id}`, data, { headers: { 'If-Match': `"${version}"` } }),
   delete: (id) => api.delete(`/patients/${id}`),
+  restore: (id) => api.post(`/patients/${id}/restore`),
 }
 
 export const examinationsAPI = {
   list: (params) => api.get('/examinations', { params }),
   get: (id) => api.get(`/examinations/${id}`),
   create: (data) => api.post('/examinations', data),
-  attachPhotos: (id, photos) => api.post(`/examinations/${id}/photos`, { photos }),
+  // position is the finger the photos were taken of, e.g. 'R4'.
+  attachPhotos: (id, photos, position) => api.post(`/examinations/${id}/photos`, { photos, position }),
+  uploadImages: (id, files, position) => {
+    const form = new FormData()
+    files.forEach((file) => form.append('files', file))
+    if (position) {
+      form.append('position', position)
+    }
+    return api.post(`/examinations/${id}/images`, form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    })
+  },
   startAnalysis: (id) => api.post(`/examinations/${id}/analyze`),
+  getProtocol: (id) => api.get(`/examinations/${id}/protocol`),
   getByPatient: (patientId) => api.get(`/examinations/patient/${patientId}`),
 }
 
+export const devicesAPI = {
+  list: () => api.get('/devices'),
+  listOnline: () => api.get('/devices/online'),
+  get: (id) => api.get(`/devices/${id}`),
+  create: (data) => api.post('/devices', data),
+  update: (id, data) => api.put(`/devices/${id}`, data),
+  delete: (id) => api.delete(`/devices/${id}`),
+}
+
+export const imagesAPI = {
+  // size is 'thumbnail' or 'preview'; the response is a JPEG blob.
+  getThumbnail: (id, size = 'thumbnail') =>
+    api.get(`/images/${id}/thumbnail`, { params: { size }, responseType: 'blob' }),
+}
+
 export const reportsAPI = {
   list: (params) => api.get('/reports', { params }),
   get: (id) => api.get(`/reports/${id}`),
   getByExamination: (examinationId) => api.get(`/reports/examination/${examinationId}`),
   create: (data) => api.post('/reports', data),
-  update: (id, data) => api.put(`/reports/${id}`, data),
+  update: (id, data, version) => api.put(`/reports/${id}`, data, { headers: { 'If-Match': `"${version}"` } }),
 }
 
 export default api
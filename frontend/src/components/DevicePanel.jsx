@@ -3,13 +3,33 @@ import { Card, Button, List, message, Select, Tag, Checkbox } from 'antd'
 import { CameraOutlined, PlayCircleOutlined, StopOutlined, ReloadOutlined } from '@ant-design/icons'
 import { examinationsAPI } from '../api'
 
-export default function DevicePanel({ title = 'Управление камерой', examinationId }) {
+// Позиции: рука (L — левая, R — правая) и номер пальца
+const positions = ['R', 'L'].flatMap((hand) =>
+  [1, 2, 3, 4, 5].map((finger) => ({
+    value: `${hand}${finger}`,
+    label: `${hand === 'R' ? 'Правая' : 'Левая'}, ${['I', 'II', 'III', 'IV', 'V'][finger - 1]} палец`,
+  }))
+)
+
+// Постоянный идентификатор этого рабочего места для реестра устройств
+const getDeviceId = () => {
+  let deviceId = localStorage.getItem('device_id')
+  if (!deviceId) {
+    deviceId = crypto.randomUUID()
+    localStorage.setItem('device_id', deviceId)
+  }
+  return deviceId
+}
+
+export default function DevicePanel({ title = 'Управление камерой', examinationId, onPhotosAttached }) {
   const [wsStatus, setWsStatus] = useState('disconnected')
   const [photos, setPhotos] = useState([])
   const [selectedPhotos, setSelectedPhotos] = useState([])
   const [cameras, setCameras] = useState([])
   const [selectedCamera, setSelectedCamera] = useState('')
   const [streaming, setStreaming] = useState(false)
+  const [session, setSession] = useState(null)
+  const [position, setPosition] = useState()
   const videoRef = useRef(null)
   const streamRef = useRef(null)
   const socketRef = useRef(null)
@@ -17,11 +37,14 @@ export default function DevicePanel({ title = 'Управление камеро
   useEffect(() => {
     requestCameraPermission()
     const protocol = window.location.protocol === 'https:' ? 'wss' : 'ws'
-    const socket = new WebSocket(`${protocol}://${window.location.host}/ws`)
+    const token = localStorage.getItem('token')
+    const query = token ? `?token=${encodeURIComponent(token)}` : ''
+    const socket = new WebSocket(`${protocol}://${window.location.host}/ws${query}`)
     socketRef.current = socket
 
     socket.onopen = () => {
       setWsStatus('connected')
+      socket.send(JSON.stringify({ type: 'hello', data: { device_id: getDeviceId() } }))
       socket.send(JSON.stringify({ type: 'get_photos' }))
     }
 
@@ -135,6 +158,19 @@ export default function DevicePanel({ title = 'Управление камеро
       case 'photo_saved':
         sendDeviceCommand('get_photos')
         message.success('Фото сохранено')
+        if (payload.data?.examination_id) {
+          onPhotosAttached?.()
+        }
+        break
+      case 'session_started':
+        setSession(payload.data)
+        message.success('Сессия съёмки начата: фото прикрепляются к исследованию')
+        break
+      case 'session_ended':
+        setSession(null)
+        message.success(payload.data?.analysis_started
+          ? 'Сессия завершена, анализ запущен'
+          : `Сессия завершена, снято фото: ${payload.data?.photos ?? 0}`)
         break
       case 'error':
         message.error(payload?.data?.message || 'Ошибка устройства')
@@ -150,17 +186,45 @@ export default function DevicePanel({ title = 'Управление камеро
     }
   }
 
+  // Повторный start_session переводит открытую сессию на другой палец
+  const startSession = (nextPosition = position) => {
+    sendDeviceCommand('start_session', { examination_id: examinationId, position: nextPosition })
+  }
+
+  const changePosition = (value) => {
+    setPosition(value)
+    if (session) {
+      startSession(value)
+    }
+  }
+
+  const endSession = (analyze) => {
+    sendDeviceCommand('end_session', { analyze })
+  }
+
   const attachPhotosToExamination = async () => {
     try {
-      await examinationsAPI.attachPhotos(examinationId, selectedPhotos)
+      await examinationsAPI.attachPhotos(examinationId, selectedPhotos, position)
       message.success(`Прикреплено ${selectedPhotos.length} фото к исследованию`)
-      
-      // Запускаем анализ
-      await examinationsAPI.startAnalysis(examinationId)
-      message.success('Анализ запущен! Результаты появятся в отчётах через несколько секунд')
+      // Прикреплённые фото уходят из списка входящих
+      sendDeviceCommand('get_photos')
       setSelectedPhotos([])
+      onPhotosAttached?.()
     } catch (error) {
       message.error('Ошибка прикрепления фото: ' + (error.response?.data?.message || error.message))
+      return
+    }
+
+    // Анализ запускается, только когда сняты все пальцы протокола
+    try {
+      await examinationsAPI.startAnalysis(examinationId)
+      message.success('Анализ запущен! Результаты появятся в отчётах через несколько секунд')
+    } catch (error) {
+      if (error.response?.status === 409) {
+        message.info('Снимков пока недостаточно для анализа: ' + error.response.data?.message)
+      } else {
+        message.error('Ошибка запуска анализа: ' + (error.response?.data?.message || error.message))
+      }
     }
   }
 
@@ -224,6 +288,31 @@ export default function DevicePanel({ title = 'Управление камеро
             <Button icon={<ReloadOutlined />} onClick={() => sendDeviceCommand('get_photos')}>
               Обновить
             </Button>
+            {examinationId && (
+              <Select
+                value={position}
+                onChange={changePosition}
+                options={positions}
+                placeholder="Палец"
+                allowClear
+                style={{ minWidth: 180 }}
+              />
+            )}
+            {examinationId && !session && (
+              <Button onClick={() => startSession()}>
+                Начать сессию
+              </Button>
+            )}
+            {session && (
+              <>
+                <Button onClick={() => endSession(false)}>
+                  Завершить сессию
+                </Button>
+                <Button type="primary" onClick={() => endSession(true)}>
+                  Завершить и анализировать
+                </Button>
+              </>
+            )}
           </div>
         </div>
         <div>
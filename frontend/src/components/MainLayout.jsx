@@ -8,9 +8,12 @@ export default function MainLayout() {
   const navigate = useNavigate()
   const location = useLocation()
 
+  const organization = JSON.parse(localStorage.getItem('organization') || '{}')
+
   const handleLogout = () => {
     localStorage.removeItem('token')
     localStorage.removeItem('user')
+    localStorage.removeItem('organization')
     navigate('/login')
   }
 
@@ -42,6 +45,7 @@ export default function MainLayout() {
         <div style={{
           height: 64,
           display: 'flex',
+          flexDirection: 'column',
           alignItems: 'center',
           justifyContent: 'center',
           color: 'white',
@@ -50,6 +54,9 @@ export default function MainLayout() {
           borderBottom: '1px solid rgba(255,255,255,0.1)',
         }}>
           Капилляроскопия
+          {organization.name && (
+            <span style={{ fontSize: 12, fontWeight: 400, opacity: 0.8 }}>{organization.name}</span>
+          )}
         </div>
         <Menu
           theme="dark"
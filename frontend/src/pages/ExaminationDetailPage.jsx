@@ -1,17 +1,37 @@
 import { useState, useEffect } from 'react'
 import { useParams, useNavigate } from 'react-router-dom'
-import { Card, Button, Spin, message, Descriptions, Tag, Divider, Space } from 'antd'
+import { Card, Button, Spin, message, Descriptions, Tag, Divider, Space, Table } from 'antd'
 import { ArrowLeftOutlined } from '@ant-design/icons'
 import { examinationsAPI } from '../api'
 import DevicePanel from '../components/DevicePanel'
+import { examTypes } from './ExaminationsPage'
+
+const progressColumns = [
+  {
+    title: 'Палец',
+    dataIndex: 'label',
+    key: 'label',
+  },
+  {
+    title: 'Снято',
+    key: 'captured',
+    render: (_, record) => (
+      <Tag color={record.captured >= record.required ? 'success' : 'warning'}>
+        {record.captured} из {record.required}
+      </Tag>
+    ),
+  },
+]
 
 export default function ExaminationDetailPage() {
   const { id } = useParams()
   const navigate = useNavigate()
   const [examination, setExamination] = useState(null)
+  const [protocol, setProtocol] = useState(null)
   const [loading, setLoading] = useState(false)
   useEffect(() => {
     loadExamination()
+    loadProtocol()
   }, [id])
 
   const loadExamination = async () => {
@@ -26,6 +46,15 @@ export default function ExaminationDetailPage() {
     }
   }
 
+  const loadProtocol = async () => {
+    try {
+      const response = await examinationsAPI.getProtocol(id)
+      setProtocol(response.data.data)
+    } catch (error) {
+      setProtocol(null)
+    }
+  }
+
   const startAnalysis = async () => {
     try {
       await examinationsAPI.startAnalysis(id)
@@ -34,7 +63,7 @@ export default function ExaminationDetailPage() {
         navigate('/reports')
       }, 1500)
     } catch (error) {
-      message.error('Ошибка запуска анализа')
+      message.error('Ошибка запуска анализа: ' + (error.response?.data?.message || error.message))
     }
   }
 
@@ -49,8 +78,8 @@ export default function ExaminationDetailPage() {
           Назад
         </Button>
         <Space>
-          <Button onClick={loadExamination}>Обновить</Button>
-          <Button type="primary" danger onClick={startAnalysis}>
+          <Button onClick={() => { loadExamination(); loadProtocol() }}>Обновить</Button>
+          <Button type="primary" danger onClick={startAnalysis} disabled={protocol && !protocol.complete}>
             Запустить анализ
           </Button>
         </Space>
@@ -64,6 +93,9 @@ export default function ExaminationDetailPage() {
           <Descriptions.Item label="Статус">
             <Tag>{examination.status}</Tag>
           </Descriptions.Item>
+          <Descriptions.Item label="Протокол">
+            {examTypes.find((type) => type.value === examination.exam_type)?.label || examination.exam_type}
+          </Descriptions.Item>
           <Descriptions.Item label="Создано">{new Date(examination.created_at).toLocaleString('ru-RU')}</Descriptions.Item>
           <Descriptions.Item label="Обновлено">{new Date(examination.updated_at).toLocaleString('ru-RU')}</Descriptions.Item>
           <Descriptions.Item label="Описание" span={2}>
@@ -71,8 +103,19 @@ export default function ExaminationDetailPage() {
           </Descriptions.Item>
         </Descriptions>
       </Card>
+      {protocol?.positions?.length > 0 && (
+        <Card title="Съёмка по протоколу" className="mb-4">
+          <Table
+            dataSource={protocol.positions}
+            columns={progressColumns}
+            rowKey="position"
+            pagination={false}
+            size="small"
+          />
+        </Card>
+      )}
       <Divider />
-      <DevicePanel title="Управление камерой для исследования" examinationId={id} />
+      <DevicePanel title="Управление камерой для исследования" examinationId={id} onPhotosAttached={loadProtocol} />
     </div>
   )
 }
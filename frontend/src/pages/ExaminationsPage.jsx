@@ -5,6 +5,12 @@ import { useNavigate } from 'react-router-dom'
 import { examinationsAPI, patientsAPI } from '../api'
 import DevicePanel from '../components/DevicePanel'
 
+export const examTypes = [
+  { value: 'general', label: 'Общее исследование' },
+  { value: 'nailfold_standard', label: 'Капилляроскопия: II–V пальцы обеих рук, 4 поля' },
+  { value: 'nailfold_screening', label: 'Скрининг: IV пальцы обеих рук, 2 поля' },
+]
+
 const statusColors = {
   pending: 'default',
   in_progress: 'processing',
@@ -80,6 +86,7 @@ export default function ExaminationsPage() {
       await examinationsAPI.create({
         patient_id: values.patient_id,
         doctor_id: doctorId,
+        exam_type: values.exam_type,
         description: values.description || '',
       })
       message.success('Исследование создано')
@@ -106,6 +113,12 @@ export default function ExaminationsPage() {
       key: 'status',
       render: (status) => <Tag color={statusColors[status] || 'default'}>{status}</Tag>,
     },
+    {
+      title: 'Тип',
+      dataIndex: 'exam_type',
+      key: 'exam_type',
+      render: (value) => examTypes.find((type) => type.value === value)?.label || value,
+    },
     {
       title: 'Описание',
       dataIndex: 'description',
@@ -170,6 +183,9 @@ export default function ExaminationsPage() {
           >
             <Input placeholder="ID врача" />
           </Form.Item>
+          <Form.Item name="exam_type" label="Протокол съёмки" initialValue="general">
+            <Select options={examTypes} />
+          </Form.Item>
           <Form.Item name="description" label="Описание">
             <Input.TextArea rows={4} placeholder="Например, предварительный диагноз" />
           </Form.Item>
@@ -1,6 +1,6 @@
 import { useState } from 'react'
 import { Form, Input, Button, Card, message, Tabs } from 'antd'
-import { UserOutlined, LockOutlined, MailOutlined } from '@ant-design/icons'
+import { UserOutlined, LockOutlined, MailOutlined, BankOutlined } from '@ant-design/icons'
 import { useNavigate } from 'react-router-dom'
 import { authAPI } from '../api'
 
@@ -14,6 +14,7 @@ export default function LoginPage() {
       const response = await authAPI.login(values)
       localStorage.setItem('token', response.data.data.token)
       localStorage.setItem('user', JSON.stringify(response.data.data.user))
+      localStorage.setItem('organization', JSON.stringify(response.data.data.organization))
       message.success('Вход выполнен успешно')
       navigate('/')
     } catch (error) {
@@ -32,6 +33,7 @@ export default function LoginPage() {
       const response = await authAPI.login(loginData)
       localStorage.setItem('token', response.data.data.token)
       localStorage.setItem('user', JSON.stringify(response.data.data.user))
+      localStorage.setItem('organization', JSON.stringify(response.data.data.organization))
       navigate('/')
     } catch (error) {
       message.error('Ошибка регистрации')
@@ -69,6 +71,9 @@ export default function LoginPage() {
               label: 'Регистрация',
               children: (
                 <Form onFinish={onRegister} layout="vertical">
+                  <Form.Item name="organization_name" rules={[{ required: true, message: 'Введите название клиники' }]}>
+                    <Input prefix={<BankOutlined />} placeholder="Клиника" />
+                  </Form.Item>
                   <Form.Item name="username" rules={[{ required: true, message: 'Введите логин' }]}>
                     <Input prefix={<UserOutlined />} placeholder="Логин" />
                   </Form.Item>
@@ -84,9 +89,6 @@ export default function LoginPage() {
                   <Form.Item name="last_name" rules={[{ required: true, message: 'Введите фамилию' }]}>
                     <Input placeholder="Фамилия" />
                   </Form.Item>
-                  <Form.Item name="role" initialValue="doctor" hidden>
-                    <Input />
-                  </Form.Item>
                   <Form.Item>
                     <Button type="primary" htmlType="submit" loading={loading} block>
                       Зарегистрироваться
@@ -73,11 +73,16 @@ export default function ReportPage() {
   const handleSave = async () => {
     try {
       const values = await form.validateFields()
-      await reportsAPI.update(id, values)
+      await reportsAPI.update(id, values, report.version)
       message.success('Отчет обновлен')
       setEditModalVisible(false)
       loadReport()
     } catch (error) {
+      if (error.response?.status === 412) {
+        message.error('Отчет был изменен другим пользователем. Данные обновлены, внесите правки повторно')
+        loadReport()
+        return
+      }
       message.error('Ошибка обновления отчета')
     }
   }